@@ -0,0 +1,202 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseElements(t *testing.T) {
+	assert := assert.New(t)
+
+	tables, err := readFile("testdata/part06.xml")
+	assert.NoError(err)
+	elements := parseElements(tables)
+	assert.Equal([]element{
+		{tag: "(0008,0001)", name: "Length to End", keyword: "LengthToEnd", vr: "UL", vm: "1", retired: true},
+		{tag: "(0010,0010)", name: "Patient's Name", keyword: "PatientName", vr: "PN", vm: "1"},
+		{tag: "(0018,9445)", keyword: "RetiredBlank", vr: "na", vm: "1", retired: true},
+		{tag: "(0028,0106)", name: "Smallest Image Pixel Value", keyword: "SmallestImagePixelValue", vr: "xs", vm: "1"},
+		{tag: "(0028,3006)", name: "LUT Data", keyword: "LUTData", vr: "OW", vm: "1-N"},
+		{tag: "(60xx,3000)", name: "Overlay Data", keyword: "OverlayData", vr: "ox", vm: "1"},
+		{tag: "(FFFE,E000)", name: "Item", keyword: "Item", vr: "na", vm: "1"},
+	}, elements)
+
+	tables, err = readFile("testdata/part07.xml")
+	assert.NoError(err)
+	commands := parseElements(tables)
+	assert.Len(commands, 2)
+	assert.Equal("AffectedSOPClassUID", commands[1].keyword)
+
+	src, err := renderTags(append(commands, elements...))
+	assert.NoError(err)
+	assert.Contains(string(src), "var CommandGroupLength = DicomTag{0x0000, 0x0000}\n")
+	assert.Contains(string(src), "TagDict = make(map[DicomTag]TagInfo, 8)\n")
+	assert.Contains(string(src), `TagDict[DicomTag{0x0008, 0x0001}] = TagInfo{"UL", "LengthToEnd", "1", "retired"}`)
+	assert.Contains(string(src), `rangeDict = append(rangeDict, mustParseRangeEntry("(60xx,3000)", TagInfo{"ox", "OverlayData", "1", ""}))`)
+	assert.NotContains(string(src), "var OverlayData")
+}
+
+func TestParseUIDs(t *testing.T) {
+	assert := assert.New(t)
+
+	tables, err := readFile("testdata/part06.xml")
+	assert.NoError(err)
+	uids := parseUIDs(tables)
+	assert.Equal([]uidEntry{
+		{uid: "1.2.840.10008.1.2.1", name: "Explicit VR Little Endian", uidType: "Transfer Syntax", part: "PS3.5"},
+		{uid: "1.2.840.10008.1.2.2", name: "Explicit VR Big Endian", uidType: "Transfer Syntax", part: "PS3.5", retired: true},
+		{uid: "1.2.840.10008.5.1.4.1.1.2", name: "CT Image Storage", uidType: "SOP Class", part: "PS3.4"},
+		{uid: "1.2.840.10008.15.0.4.1", name: "dicomConfigurationRoot", uidType: "LDAP OID", part: "PS3.15"},
+	}, uids)
+
+	src, err := renderUIDs(uids)
+	assert.NoError(err)
+	assert.Contains(string(src), `{"1.2.840.10008.1.2.2", "Explicit VR Big Endian", TypeTransferSyntax, "PS3.5", "Retired"}`)
+	assert.Contains(string(src), `{"1.2.840.10008.15.0.4.1", "dicomConfigurationRoot", "LDAP OID", "PS3.15", ""}`)
+}
+
+func TestParseModules(t *testing.T) {
+	assert := assert.New(t)
+
+	tables, err := readFile("testdata/part03.xml")
+	assert.NoError(err)
+	modules, err := parseModules(tables)
+	assert.NoError(err)
+	assert.Len(modules, 1)
+	patient := modules[0]
+	assert.Equal("Patient", patient.name)
+	assert.Len(patient.attributes, 4)
+	assert.Equal([]string{"M", "F", "O"}, patient.attributes[1].enumerated)
+	// The attributes of the included macro are the items of the sequence
+	sequence := patient.attributes[2]
+	assert.Equal("(0008,1120)", sequence.tag)
+	assert.Len(sequence.items, 2)
+	assert.Equal("(0008,1155)", sequence.items[1].tag)
+	assert.Equal("1", sequence.items[1].attrType)
+
+	src, err := renderModules(modules)
+	assert.NoError(err)
+	assert.Contains(string(src), `{Tag: tag.DicomTag{Group: 0x0010, Element: 0x0040}, Type: Type2, Enumerated: []Enumeration{{Values: []string{"M", "F", "O"}}}}`)
+	assert.NotContains(string(src), "0x60xx")
+}
+
+func TestParseIODs(t *testing.T) {
+	assert := assert.New(t)
+
+	tables, err := readFile("testdata/part03.xml")
+	assert.NoError(err)
+	modules, err := parseModules(tables)
+	assert.NoError(err)
+	uidTables, err := readFile("testdata/part06.xml")
+	assert.NoError(err)
+	iods := parseIODs(tables, modules, parseUIDs(uidTables))
+	if assert.Len(iods, 1) {
+		assert.Equal("CT Image", iods[0].name)
+		assert.Equal("A.3", iods[0].section)
+		assert.Len(iods[0].sopClassUIDs, 1)
+		// The Clinical Trial Subject module has no definition
+		assert.Equal([]moduleUsage{{module: "Patient", usage: "Mandatory"}}, iods[0].modules)
+	}
+
+	src, err := renderIODs(iods)
+	assert.NoError(err)
+	assert.Contains(string(src), "// CTImageIOD is the CT Image IOD, PS3.3 A.3\n")
+	assert.Contains(string(src), `"1.2.840.10008.5.1.4.1.1.2", // CT Image Storage`)
+	assert.Contains(string(src), `{Module: ModuleDefinitions["Patient"], Usage: Mandatory},`)
+	assert.Contains(string(src), "var IODs = []*IOD{\n\tCTImageIOD,\n}\n")
+}
+
+func TestParseProfile(t *testing.T) {
+	assert := assert.New(t)
+
+	tables, err := readFile("testdata/part15.xml")
+	assert.NoError(err)
+	attributes := parseProfile(tables)
+	// The private attributes have no tag
+	if assert.Len(attributes, 8) {
+		assert.Equal(profileAttribute{tag: "(0008,0022)", name: "Acquisition Date", basic: "X/Z", retain: []string{"retainLongitudinal"}}, attributes[1])
+		assert.Equal("(50xx,xxxx)", attributes[2].tag)
+		assert.True(attributes[7].clean)
+	}
+
+	elementTables, err := readFile("testdata/part06.xml")
+	assert.NoError(err)
+	src, err := renderProfile(attributes, parseElements(elementTables))
+	assert.NoError(err)
+	assert.Contains(string(src), "tag.PatientName: {basic: \"Z\"},")
+	assert.Contains(string(src), "tag.DicomTag{Group: 0x0088, Element: 0x0130}: {basic: \"X\"},")
+	assert.Contains(string(src), "tag.DicomTag{Group: 0x0018, Element: 0x1000}: {basic: \"X/Z/D\", retain: retainDeviceIdentity},")
+	assert.NotContains(string(src), "0x50xx")
+	// The curve data of the repeating groups are omitted
+	assert.Equal(7, profileEntries(t, src))
+}
+
+// TestBasicProfile checks the basic profile of the deid package against the PS3.15 DocBook XML of the directory of
+// DICOM_STANDARD
+func TestBasicProfile(t *testing.T) {
+	dir := os.Getenv("DICOM_STANDARD")
+	if dir == "" {
+		t.Skip("DICOM_STANDARD is not set")
+	}
+	assert := assert.New(t)
+
+	tables, err := readFile(filepath.Join(dir, "part15.xml"))
+	assert.NoError(err)
+	count := 0
+	for _, a := range parseProfile(tables) {
+		if !strings.Contains(a.tag, "x") {
+			count++
+		}
+	}
+	src, err := os.ReadFile("../../pkg/dicom/deid/profile_definitions.go")
+	assert.NoError(err)
+	assert.Equal(count, profileEntries(t, src))
+}
+
+// profileEntries returns the number of entries of the basicProfile of the source
+func profileEntries(t *testing.T, src []byte) int {
+	f, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := -1
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "basicProfile" || len(spec.Values) != 1 {
+			return true
+		}
+		if lit, ok := spec.Values[0].(*ast.CompositeLit); ok {
+			count = len(lit.Elts)
+		}
+		return false
+	})
+	return count
+}
+
+func TestRun(t *testing.T) {
+	assert := assert.New(t)
+
+	dir := t.TempDir()
+	tags, uids, modules := filepath.Join(dir, "tags.go"), filepath.Join(dir, "uids.go"), filepath.Join(dir, "modules.go")
+	iods, profile := filepath.Join(dir, "iods.go"), filepath.Join(dir, "profile.go")
+	assert.NoError(run("testdata/part03.xml", "testdata/part06.xml", "testdata/part07.xml", "testdata/part15.xml",
+		tags, uids, modules, iods, profile))
+	for _, path := range []string{tags, uids, modules, iods, profile} {
+		_, err := os.Stat(path)
+		assert.NoError(err)
+	}
+
+	assert.Error(run("", "", "", "", "", "", "", "", ""))
+	assert.Error(run("", "", "", "", tags, "", "", "", ""))
+	assert.Error(run("", "testdata/part06.xml", "", "", "", "", modules, "", ""))
+	assert.Error(run("testdata/part03.xml", "", "", "", "", "", "", iods, ""))
+	assert.Error(run("", "", "", "testdata/part15.xml", "", "", "", "", profile))
+	assert.Error(run("", "testdata/missing.xml", "", "", tags, "", "", "", ""))
+}
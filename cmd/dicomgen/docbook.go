@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// node is an element of a DocBook document, or a text node of empty name
+type node struct {
+	name     string
+	attrs    map[string]string
+	children []*node
+	text     string
+}
+
+// table is a table of a DocBook document, with the lower case texts of its header cells
+type table struct {
+	id      string
+	caption string
+	header  []string
+	rows    []*node
+}
+
+// readTables returns the tables of the DocBook document. The rest of the document is skipped
+func readTables(r io.Reader) ([]table, error) {
+	d := xml.NewDecoder(r)
+	d.Entity = xml.HTMLEntity
+	res := make([]table, 0)
+	for {
+		token, err := d.Token()
+		if err == io.EOF {
+			return res, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot read DocBook document: %v", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "table" {
+			continue
+		}
+		n, err := readNode(d, start)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read DocBook document: %v", err)
+		}
+		res = append(res, newTable(n))
+	}
+}
+
+// readNode reads the element of the start token and its children
+func readNode(d *xml.Decoder, start xml.StartElement) (*node, error) {
+	n := &node{name: start.Name.Local, attrs: make(map[string]string, len(start.Attr))}
+	for _, attr := range start.Attr {
+		n.attrs[attr.Name.Local] = attr.Value
+	}
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			child, err := readNode(d, t)
+			if err != nil {
+				return nil, err
+			}
+			n.children = append(n.children, child)
+		case xml.CharData:
+			n.children = append(n.children, &node{text: string(t)})
+		case xml.EndElement:
+			return n, nil
+		}
+	}
+}
+
+func newTable(n *node) table {
+	t := table{id: n.attrs["id"]}
+	if caption := n.first("caption"); caption != nil {
+		t.caption = caption.Text()
+	}
+	if thead := n.first("thead"); thead != nil {
+		for _, th := range thead.all("th") {
+			t.header = append(t.header, strings.ToLower(th.Text()))
+		}
+	}
+	if tbody := n.first("tbody"); tbody != nil {
+		t.rows = tbody.all("tr")
+	}
+	return t
+}
+
+// column returns the index of the first header cell of one of the names, or -1
+func (t table) column(names ...string) int {
+	for i, header := range t.header {
+		for _, name := range names {
+			if header == name {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Text returns the text of the node and its descendants, without the zero width spaces of the keywords and with the
+// white spaces collapsed
+func (n *node) Text() string {
+	b := strings.Builder{}
+	n.writeText(&b)
+	return strings.Join(strings.Fields(strings.ReplaceAll(b.String(), "\u200b", "")), " ")
+}
+
+func (n *node) writeText(b *strings.Builder) {
+	if n.name == "" {
+		b.WriteString(n.text)
+		return
+	}
+	for _, child := range n.children {
+		child.writeText(b)
+	}
+}
+
+// first returns the first descendant of the name, or nil
+func (n *node) first(name string) *node {
+	for _, child := range n.children {
+		if child.name == name {
+			return child
+		}
+		if res := child.first(name); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// all returns the descendants of the name, in document order. The descendants of a matching node are not searched
+func (n *node) all(name string) []*node {
+	var res []*node
+	for _, child := range n.children {
+		if child.name == name {
+			res = append(res, child)
+			continue
+		}
+		res = append(res, child.all(name)...)
+	}
+	return res
+}
+
+// cell returns the text of the cell of the row at the index, or an empty string
+func cell(cells []*node, i int) string {
+	if i < 0 || i >= len(cells) {
+		return ""
+	}
+	return cells[i].Text()
+}
@@ -0,0 +1,150 @@
+// Command dicomgen generates the tag dictionary, the UID registry, the module definitions, the IOD definitions and the
+// basic de-identification profile from the DocBook XML of the DICOM standard, e.g.: part06.xml, provided as local files.
+// It is run by go generate in the tag, uid, iod and deid packages, with the DICOM_STANDARD environment variable set to the directory of the DocBook files:
+//
+//	DICOM_STANDARD=/path/to/docbook go generate ./pkg/dicom/...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	part3 := flag.String("part3", "", "path of the PS3.3 DocBook XML, the source of the module definitions")
+	part6 := flag.String("part6", "", "path of the PS3.6 DocBook XML, the source of the data elements and UIDs")
+	part7 := flag.String("part7", "", "path of the PS3.7 DocBook XML, the source of the command fields")
+	part15 := flag.String("part15", "", "path of the PS3.15 DocBook XML, the source of the basic profile")
+	tags := flag.String("tags", "", "output path of the tag variables and dictionary, e.g.: tag_definitions.go")
+	uids := flag.String("uids", "", "output path of the UID registry, e.g.: uid_definitions.go")
+	modules := flag.String("modules", "", "output path of the module definitions, e.g.: module_definitions.go")
+	iods := flag.String("iods", "", "output path of the IOD definitions, e.g.: iod_definitions.go")
+	profile := flag.String("profile", "", "output path of the basic profile, e.g.: profile_definitions.go")
+	flag.Parse()
+
+	if err := run(*part3, *part6, *part7, *part15, *tags, *uids, *modules, *iods, *profile); err != nil {
+		fmt.Fprintf(os.Stderr, "dicomgen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(part3, part6, part7, part15, tags, uids, modules, iods, profile string) error {
+	if tags == "" && uids == "" && modules == "" && iods == "" && profile == "" {
+		return fmt.Errorf("no output, expected -tags, -uids, -modules, -iods or -profile")
+	}
+	if (tags != "" || uids != "") && part6 == "" {
+		return fmt.Errorf("-tags and -uids require the PS3.6 DocBook XML of -part6")
+	}
+	if profile != "" && (part15 == "" || part6 == "") {
+		return fmt.Errorf("-profile requires the PS3.15 DocBook XML of -part15 and the PS3.6 DocBook XML of -part6")
+	}
+	if modules != "" && part3 == "" {
+		return fmt.Errorf("-modules requires the PS3.3 DocBook XML of -part3")
+	}
+	if iods != "" && (part3 == "" || part6 == "") {
+		return fmt.Errorf("-iods requires the PS3.3 DocBook XML of -part3 and the PS3.6 DocBook XML of -part6")
+	}
+
+	// entries holds the UIDs of PS3.6, the source of the SOP classes of the IODs
+	var entries []uidEntry
+	if tags != "" || uids != "" || iods != "" || profile != "" {
+		tables, err := readFile(part6)
+		if err != nil {
+			return err
+		}
+		entries = parseUIDs(tables)
+		if profile != "" {
+			if err = writeProfile(part15, profile, parseElements(tables)); err != nil {
+				return err
+			}
+		}
+		if tags != "" {
+			elements := parseElements(tables)
+			if part7 != "" {
+				commandTables, err := readFile(part7)
+				if err != nil {
+					return err
+				}
+				// The command fields of the group 0000 precede the data elements
+				elements = append(parseElements(commandTables), elements...)
+			}
+			if len(elements) == 0 {
+				return fmt.Errorf("no data element found in %s", part6)
+			}
+			if err = write(tags, func() ([]byte, error) { return renderTags(elements) }); err != nil {
+				return err
+			}
+		}
+		if uids != "" {
+			if len(entries) == 0 {
+				return fmt.Errorf("no UID found in %s", part6)
+			}
+			if err = write(uids, func() ([]byte, error) { return renderUIDs(entries) }); err != nil {
+				return err
+			}
+		}
+	}
+
+	if modules != "" || iods != "" {
+		tables, err := readFile(part3)
+		if err != nil {
+			return err
+		}
+		parsed, err := parseModules(tables)
+		if err != nil {
+			return err
+		}
+		if len(parsed) == 0 {
+			return fmt.Errorf("no module found in %s", part3)
+		}
+		if modules != "" {
+			if err = write(modules, func() ([]byte, error) { return renderModules(parsed) }); err != nil {
+				return err
+			}
+		}
+		if iods != "" {
+			definitions := parseIODs(tables, parsed, entries)
+			if len(definitions) == 0 {
+				return fmt.Errorf("no IOD found in %s", part3)
+			}
+			if err = write(iods, func() ([]byte, error) { return renderIODs(definitions) }); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func writeProfile(part15, profile string, elements []element) error {
+	tables, err := readFile(part15)
+	if err != nil {
+		return err
+	}
+	attributes := parseProfile(tables)
+	if len(attributes) == 0 {
+		return fmt.Errorf("no profile attribute found in %s", part15)
+	}
+	return write(profile, func() ([]byte, error) { return renderProfile(attributes, elements) })
+}
+
+func readFile(path string) ([]table, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tables, err := readTables(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return tables, nil
+}
+
+func write(path string, render func() ([]byte, error)) error {
+	src, err := render()
+	if err != nil {
+		return fmt.Errorf("cannot format %s: %v", path, err)
+	}
+	return os.WriteFile(path, src, 0644)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+)
+
+// profileAttribute is an attribute of the Application Level Confidentiality Profile Attributes table of PS3.15
+type profileAttribute struct {
+	// tag is formatted as (GGGG,EEEE), with x digits for the repeating groups, e.g.: (50xx,xxxx)
+	tag    string
+	name   string
+	basic  string
+	retain []string
+	clean  bool
+}
+
+// retainColumns maps the retention option columns of the profile table to the options of the deid package
+var retainColumns = []struct {
+	names  []string
+	option string
+}{
+	{[]string{"rtn. long. full dates opt.", "rtn. long. modif. dates opt."}, "retainLongitudinal"},
+	{[]string{"rtn. pat. chars. opt."}, "retainPatientCharacteristics"},
+	{[]string{"rtn. dev. id. opt."}, "retainDeviceIdentity"},
+}
+
+// parseProfile returns the attributes of the tables of attribute name, tag and basic profile columns, e.g.: the
+// Application Level Confidentiality Profile Attributes of PS3.15 E.1-1. The rows without tag, e.g.: the private
+// attributes, are omitted
+func parseProfile(tables []table) []profileAttribute {
+	res := make([]profileAttribute, 0)
+	for _, t := range tables {
+		nameCol, tagCol, basicCol := t.column("attribute name"), t.column("tag"), t.column("basic prof.", "basic profile")
+		if nameCol < 0 || tagCol < 0 || basicCol < 0 {
+			continue
+		}
+		cleanCol := t.column("clean desc. opt.")
+		for _, row := range t.rows {
+			cells := row.all("td")
+			m := tagRegexp.FindStringSubmatch(cell(cells, tagCol))
+			if m == nil {
+				continue
+			}
+			a := profileAttribute{
+				tag:   "(" + hexDigits(m[1]) + "," + hexDigits(m[2]) + ")",
+				name:  cell(cells, nameCol),
+				basic: strings.ReplaceAll(cell(cells, basicCol), " ", ""),
+				clean: cell(cells, cleanCol) != "",
+			}
+			for _, c := range retainColumns {
+				for _, name := range c.names {
+					if cell(cells, t.column(name)) != "" {
+						a.retain = append(a.retain, c.option)
+						break
+					}
+				}
+			}
+			res = append(res, a)
+		}
+	}
+	return res
+}
+
+// renderProfile returns the source of profile_definitions.go, the basic profile of the deid package. The attributes
+// are keyed by the variables of the tag package of the data elements of PS3.6, and the attributes of the repeating
+// groups are omitted
+func renderProfile(attributes []profileAttribute, elements []element) ([]byte, error) {
+	keywords := make(map[string]string, len(elements))
+	for _, e := range elements {
+		if _, ok := keywords[e.tag]; !ok {
+			keywords[e.tag] = e.keyword
+		}
+	}
+	entries := make([]string, 0, len(attributes))
+	for _, a := range attributes {
+		if strings.Contains(a.tag, "x") {
+			continue
+		}
+		key := fmt.Sprintf("tag.DicomTag{Group: 0x%s, Element: 0x%s}", a.tag[1:5], a.tag[6:10])
+		if keyword, ok := keywords[a.tag]; ok {
+			key = "tag." + keyword
+		}
+		entry := fmt.Sprintf("%s: {basic: %q", key, a.basic)
+		if len(a.retain) > 0 {
+			entry += ", retain: " + strings.Join(a.retain, " | ")
+		}
+		if a.clean {
+			entry += ", clean: true"
+		}
+		entries = append(entries, entry+"},")
+	}
+	sort.Strings(entries)
+
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by dicomgen from PS3.15 and PS3.6; DO NOT EDIT.\n\npackage deid\n\n")
+	b.WriteString("import \"github.com/okieraised/go2com/pkg/dicom/tag\"\n\n")
+	b.WriteString("// basicProfile is the Application Level Confidentiality Profile Attributes table of PS3.15 Annex E. Curves, overlay\n")
+	b.WriteString("// comments and overlay data are handled by group in actionOf\nvar basicProfile = map[tag.DicomTag]rule{\n")
+	for _, entry := range entries {
+		b.WriteString(entry + "\n")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
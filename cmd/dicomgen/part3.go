@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// maxIncludeDepth bounds the nesting of the macros included by the modules and macros
+const maxIncludeDepth = 16
+
+// module is a module of PS3.3, with the attributes of its included macros
+type module struct {
+	name       string
+	attributes []*attribute
+}
+
+// attribute is an attribute of a module, with the attributes of the items of a sequence
+type attribute struct {
+	tag        string
+	name       string
+	attrType   string
+	enumerated []string
+	items      []*attribute
+}
+
+// parseModules returns the modules of the tables of attribute name, tag and type columns whose caption is the module
+// name followed by Module Attributes, e.g.: Patient Module Attributes. The macros included by the modules are expanded
+func parseModules(tables []table) ([]module, error) {
+	byID := make(map[string]table, len(tables))
+	for _, t := range tables {
+		if t.id != "" {
+			byID[t.id] = t
+		}
+	}
+	res := make([]module, 0)
+	names := make(map[string]bool)
+	for _, t := range tables {
+		name := strings.TrimSuffix(strings.TrimSuffix(t.caption, " Attributes"), " Module")
+		if !strings.HasSuffix(t.caption, " Module Attributes") || names[name] {
+			continue
+		}
+		names[name] = true
+		attributes, err := parseAttributes(t, byID, 0)
+		if err != nil {
+			return nil, fmt.Errorf("invalid table %s of the %s module: %v", t.id, name, err)
+		}
+		res = append(res, module{name: name, attributes: attributes})
+	}
+	return res, nil
+}
+
+// parseAttributes returns the attributes of the table of a module or macro. The nesting level of the attributes of
+// the sequences is the number of > of their name
+func parseAttributes(t table, byID map[string]table, depth int) ([]*attribute, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("macros nested deeper than %d levels", maxIncludeDepth)
+	}
+	nameCol, tagCol, typeCol := t.column("attribute name"), t.column("tag"), t.column("type")
+	descriptionCol := t.column("attribute description", "description")
+	if nameCol < 0 || tagCol < 0 {
+		return nil, nil
+	}
+	root := &attribute{}
+	// parents holds the attribute of each nesting level, whose items receive the attributes of the next level
+	parents := []*attribute{root}
+	for _, row := range t.rows {
+		cells := row.all("td")
+		text := cell(cells, 0)
+		level := len(text) - len(strings.TrimLeft(text, ">"))
+		if level >= len(parents) {
+			// The attributes nested below an attribute that is not a sequence are ignored
+			continue
+		}
+		parent := parents[level]
+		parents = parents[:level+1]
+
+		if strings.HasPrefix(strings.TrimSpace(strings.TrimLeft(text, ">")), "Include") {
+			xref := row.first("xref")
+			if xref == nil {
+				continue
+			}
+			macro, ok := byID[xref.attrs["linkend"]]
+			if !ok {
+				continue
+			}
+			attributes, err := parseAttributes(macro, byID, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			parent.items = append(parent.items, attributes...)
+			continue
+		}
+
+		m := tagRegexp.FindStringSubmatch(cell(cells, tagCol))
+		if m == nil {
+			continue
+		}
+		attr := &attribute{
+			tag:      "(" + hexDigits(m[1]) + "," + hexDigits(m[2]) + ")",
+			name:     strings.TrimSpace(strings.TrimLeft(cell(cells, nameCol), ">")),
+			attrType: strings.ToUpper(cell(cells, typeCol)),
+		}
+		if descriptionCol >= 0 && descriptionCol < len(cells) {
+			attr.enumerated = enumeratedValues(cells[descriptionCol])
+		}
+		switch attr.attrType {
+		case "1", "1C", "2", "2C", "3":
+		default:
+			// The macros without type column, e.g.: the attributes of the items of a sequence, are optional
+			attr.attrType = "3"
+		}
+		parent.items = append(parent.items, attr)
+		parents = append(parents, attr)
+	}
+	return root.items, nil
+}
+
+// enumeratedValues returns the terms of the Enumerated Values lists of the description of an attribute
+func enumeratedValues(description *node) []string {
+	var res []string
+	for _, list := range description.all("variablelist") {
+		title := list.first("title")
+		if title == nil || !strings.HasPrefix(title.Text(), "Enumerated Values") {
+			continue
+		}
+		for _, term := range list.all("term") {
+			if value := term.Text(); value != "" {
+				res = append(res, value)
+			}
+		}
+	}
+	return res
+}
+
+// renderModules returns the source of the module definitions of the iod package, the ModuleDefinitions by name
+func renderModules(modules []module) ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by dicomgen from PS3.3; DO NOT EDIT.\n\npackage iod\n\n")
+	b.WriteString("import \"github.com/okieraised/go2com/pkg/dicom/tag\"\n\n")
+	b.WriteString("// ModuleDefinitions holds the modules of PS3.3 by name. The conditions of their Type 1C and 2C attributes are not\n")
+	b.WriteString("// generated, and their repeating groups are omitted\n")
+	b.WriteString("var ModuleDefinitions = map[string]*Module{\n")
+	for _, m := range modules {
+		fmt.Fprintf(b, "%q: {\nName: %q,\nAttributes: ", m.name, m.name)
+		renderAttributes(b, m.attributes)
+		b.WriteString(",\n},\n")
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+func renderAttributes(b *bytes.Buffer, attributes []*attribute) {
+	b.WriteString("[]Attribute{\n")
+	for _, attr := range attributes {
+		if strings.Contains(attr.tag, "x") {
+			continue
+		}
+		fmt.Fprintf(b, "// %s\n", attr.name)
+		fmt.Fprintf(b, "{Tag: tag.DicomTag{Group: 0x%s, Element: 0x%s}, Type: Type%s", attr.tag[1:5], attr.tag[6:10], attr.attrType)
+		if len(attr.enumerated) > 0 {
+			b.WriteString(", Enumerated: []Enumeration{{Values: []string{")
+			for i, value := range attr.enumerated {
+				if i > 0 {
+					b.WriteString(", ")
+				}
+				fmt.Fprintf(b, "%q", value)
+			}
+			b.WriteString("}}}")
+		}
+		if len(attr.items) > 0 {
+			b.WriteString(", Items: ")
+			renderAttributes(b, attr.items)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}")
+}
+
+// iod is an IOD of PS3.3 A, with its storage SOP classes and the modules of its table of modules
+type iod struct {
+	name         string
+	section      string
+	sopClassUIDs []uidEntry
+	modules      []moduleUsage
+}
+
+// moduleUsage is a module of an IOD with the constant of its usage, e.g.: Mandatory
+type moduleUsage struct {
+	module string
+	usage  string
+}
+
+// parseIODs returns the IODs of the tables of module and usage columns whose caption is the IOD name followed by IOD
+// Modules, e.g.: CT Image IOD Modules. The SOP classes of an IOD are the storage SOP classes of its name, e.g.: CT
+// Image Storage, the IODs without storage SOP class are omitted, and so are the modules without definition
+func parseIODs(tables []table, modules []module, uids []uidEntry) []iod {
+	defined := make(map[string]bool, len(modules))
+	for _, m := range modules {
+		defined[m.name] = true
+	}
+	res := make([]iod, 0)
+	names := make(map[string]bool)
+	for _, t := range tables {
+		name := strings.TrimSuffix(t.caption, " IOD Modules")
+		moduleCol, usageCol := t.column("module"), t.column("usage")
+		if !strings.HasSuffix(t.caption, " IOD Modules") || names[name] || moduleCol < 0 || usageCol < 0 {
+			continue
+		}
+		names[name] = true
+		d := iod{name: name, section: strings.TrimPrefix(t.id, "table_")}
+		if i := strings.LastIndex(d.section, "-"); i >= 0 {
+			d.section = d.section[:i]
+		}
+		for _, u := range uids {
+			if u.uidType == "SOP Class" && !u.retired && (u.name == name+" Storage" || strings.HasPrefix(u.name, name+" Storage - ")) {
+				d.sopClassUIDs = append(d.sopClassUIDs, u)
+			}
+		}
+		if len(d.sopClassUIDs) == 0 {
+			continue
+		}
+		for _, row := range t.rows {
+			cells := row.all("td")
+			// The IE cell spans the rows of the modules of the IE, and is omitted by the rows following the first
+			shift := len(t.header) - len(cells)
+			if shift < 0 {
+				shift = 0
+			}
+			m := cell(cells, moduleCol-shift)
+			usage := usageConstant(cell(cells, usageCol-shift))
+			if !defined[m] || usage == "" {
+				continue
+			}
+			d.modules = append(d.modules, moduleUsage{module: m, usage: usage})
+		}
+		res = append(res, d)
+	}
+	return res
+}
+
+// usageConstant returns the constant of the usage of a module, e.g.: Conditional for C - Required if..., or an empty
+// string
+func usageConstant(usage string) string {
+	switch {
+	case strings.HasPrefix(usage, "M"):
+		return "Mandatory"
+	case strings.HasPrefix(usage, "C"):
+		return "Conditional"
+	case strings.HasPrefix(usage, "U"):
+		return "UserOption"
+	}
+	return ""
+}
+
+// renderIODs returns the source of the IOD definitions of the iod package, the IOD variables and IODs. The modules of
+// the IODs are the ModuleDefinitions
+func renderIODs(iods []iod) ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by dicomgen from PS3.3 and PS3.6; DO NOT EDIT.\n\npackage iod\n\n")
+	b.WriteString("// The IODs of PS3.3 A of a storage SOP class. The conditions of their conditional modules are not generated\n\n")
+	for _, d := range iods {
+		keyword := toKeyword(d.name) + "IOD"
+		fmt.Fprintf(b, "// %s is the %s IOD, PS3.3 %s\n", keyword, d.name, d.section)
+		fmt.Fprintf(b, "var %s = &IOD{\nName: %q,\nSOPClassUIDs: []string{\n", keyword, d.name)
+		for _, u := range d.sopClassUIDs {
+			fmt.Fprintf(b, "%q, // %s\n", u.uid, u.name)
+		}
+		b.WriteString("},\nModules: []ModuleUsage{\n")
+		for _, m := range d.modules {
+			fmt.Fprintf(b, "{Module: ModuleDefinitions[%q], Usage: %s},\n", m.module, m.usage)
+		}
+		b.WriteString("},\n}\n\n")
+	}
+	b.WriteString("// IODs holds the IODs known to Find and Validate\nvar IODs = []*IOD{\n")
+	for _, d := range iods {
+		fmt.Fprintf(b, "%sIOD,\n", toKeyword(d.name))
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// element is a data element of the registries of PS3.6 and of the command fields of PS3.7
+type element struct {
+	// tag is formatted as (GGGG,EEEE), with x digits for the repeating groups and elements, e.g.: (60xx,3000)
+	tag     string
+	name    string
+	keyword string
+	vr      string
+	vm      string
+	retired bool
+}
+
+// uidEntry is a UID of the registry of PS3.6
+type uidEntry struct {
+	uid     string
+	name    string
+	uidType string
+	part    string
+	retired bool
+}
+
+var tagRegexp = regexp.MustCompile(`^\(([0-9A-Fa-fx]{4}),([0-9A-Fa-fx]{4})\)$`)
+
+// uidTypes maps the UID types of PS3.6 to the constants of the uid package
+var uidTypes = map[string]string{
+	"sop class":                     "TypeSOPClass",
+	"transfer syntax":               "TypeTransferSyntax",
+	"well-known frame of reference": "TypeWellKnownFrameOfReference",
+	"well-known sop instance":       "TypeWellKnownSOPInstance",
+	"coding scheme":                 "TypeCodingScheme",
+}
+
+// parseElements returns the data elements of the tables of tag, name or keyword, VR and VM columns, e.g.: the Registry
+// of DICOM Data Elements of PS3.6 or the Command Fields of PS3.7
+func parseElements(tables []table) []element {
+	res := make([]element, 0)
+	for _, t := range tables {
+		tagCol, vrCol, vmCol := t.column("tag"), t.column("vr"), t.column("vm")
+		nameCol, keywordCol := t.column("name", "message field"), t.column("keyword")
+		if tagCol < 0 || vrCol < 0 || vmCol < 0 || nameCol < 0 && keywordCol < 0 {
+			continue
+		}
+		// The status column of PS3.6 has no header, e.g.: RET or DICONDE
+		statusCol := t.column("", "retired", "status")
+		for _, row := range t.rows {
+			cells := row.all("td")
+			m := tagRegexp.FindStringSubmatch(cell(cells, tagCol))
+			if m == nil {
+				continue
+			}
+			e := element{
+				tag:     "(" + hexDigits(m[1]) + "," + hexDigits(m[2]) + ")",
+				name:    cell(cells, nameCol),
+				keyword: cell(cells, keywordCol),
+				vr:      dictionaryVR(cell(cells, vrCol)),
+				vm:      strings.ToUpper(strings.ReplaceAll(cell(cells, vmCol), " ", "")),
+				retired: strings.HasPrefix(cell(cells, statusCol), "RET") || strings.Contains(t.caption, "Retired"),
+			}
+			if e.keyword == "" {
+				e.keyword = toKeyword(e.name)
+			}
+			// The retired elements of PS3.6 whose name is removed have no keyword
+			if e.keyword == "" {
+				if !e.retired {
+					continue
+				}
+				e.keyword = "RetiredBlank"
+			}
+			if e.vm == "" {
+				e.vm = "1"
+			}
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// parseUIDs returns the UIDs of the tables of UID value, name and type columns, e.g.: the UID Values of PS3.6 A-1
+func parseUIDs(tables []table) []uidEntry {
+	res := make([]uidEntry, 0)
+	for _, t := range tables {
+		valueCol, nameCol, typeCol := t.column("uid value"), t.column("uid name"), t.column("uid type")
+		if valueCol < 0 || nameCol < 0 || typeCol < 0 {
+			continue
+		}
+		partCol := t.column("part")
+		for _, row := range t.rows {
+			cells := row.all("td")
+			e := uidEntry{
+				uid:     cell(cells, valueCol),
+				name:    cell(cells, nameCol),
+				uidType: cell(cells, typeCol),
+				part:    cell(cells, partCol),
+			}
+			if e.uid == "" || strings.Trim(e.uid, "0123456789.") != "" {
+				continue
+			}
+			if strings.HasSuffix(e.name, "(Retired)") {
+				e.name = strings.TrimSpace(strings.TrimSuffix(e.name, "(Retired)"))
+				e.retired = true
+			}
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// dictionaryVR returns the VR of the dictionary of the tag package for the VR of PS3.6, e.g.: xs for US or SS
+func dictionaryVR(vr string) string {
+	switch vr {
+	case "US or SS":
+		return "xs"
+	case "OB or OW":
+		return "ox"
+	case "US or SS or OW", "US or OW":
+		// The lookup table data are read as OW
+		return "OW"
+	}
+	if len(vr) != 2 || strings.ToUpper(vr) != vr {
+		// The items and delimitation items have no VR
+		return "na"
+	}
+	return vr
+}
+
+// hexDigits returns the hexadecimal digits in upper case, and the x digits of the repeating groups in lower case
+func hexDigits(s string) string {
+	return strings.ReplaceAll(strings.ToUpper(s), "X", "x")
+}
+
+// toKeyword returns the keyword of the name of the command fields of PS3.7, which have no keyword column, e.g.:
+// AffectedSOPClassUID for Affected SOP Class UID
+func toKeyword(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	b := strings.Builder{}
+	for _, word := range words {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// renderTags returns the source of tag_definitions.go: the variables of the tags, and the initialization of the
+// dictionary and of the ranges of tags
+func renderTags(elements []element) ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by dicomgen from PS3.6; DO NOT EDIT.\n\npackage tag\n\n")
+	vars := make(map[string]bool)
+	count := 0
+	for _, e := range elements {
+		if strings.Contains(e.tag, "x") {
+			continue
+		}
+		count++
+		// The variables of the keywords of several tags are the first tag
+		if vars[e.keyword] {
+			continue
+		}
+		vars[e.keyword] = true
+		fmt.Fprintf(b, "var %s = DicomTag{0x%s, 0x%s}\n", e.keyword, e.tag[1:5], e.tag[6:10])
+	}
+	b.WriteString("\nvar TagDict map[DicomTag]TagInfo\n\nfunc initTag() {\n")
+	fmt.Fprintf(b, "\tTagDict = make(map[DicomTag]TagInfo, %d)\n", count)
+	for _, e := range elements {
+		status := ""
+		if e.retired {
+			status = "retired"
+		}
+		// The repeating groups and elements, e.g.: (60xx,3000), are matched as ranges
+		if strings.Contains(e.tag, "x") {
+			fmt.Fprintf(b, "\trangeDict = append(rangeDict, mustParseRangeEntry(%q, TagInfo{%q, %q, %q, %q}))\n",
+				e.tag, e.vr, e.keyword, e.vm, status)
+			continue
+		}
+		fmt.Fprintf(b, "\tTagDict[DicomTag{0x%s, 0x%s}] = TagInfo{%q, %q, %q, %q}\n",
+			e.tag[1:5], e.tag[6:10], e.vr, e.keyword, e.vm, status)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
+
+// renderUIDs returns the source of uid_definitions.go, the registry of the UIDs
+func renderUIDs(uids []uidEntry) ([]byte, error) {
+	b := &bytes.Buffer{}
+	b.WriteString("// Code generated by dicomgen from PS3.6; DO NOT EDIT.\n\npackage uid\n\nvar uidMap = map[string]Info{\n")
+	for _, e := range uids {
+		uidType, ok := uidTypes[strings.ToLower(e.uidType)]
+		if !ok {
+			uidType = fmt.Sprintf("%q", e.uidType)
+		}
+		status := ""
+		if e.retired {
+			status = "Retired"
+		}
+		fmt.Fprintf(b, "\t%q: {%q, %q, %s, %q, %q},\n", e.uid, e.uid, e.name, uidType, e.part, status)
+	}
+	b.WriteString("}\n")
+	return format.Source(b.Bytes())
+}
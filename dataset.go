@@ -32,6 +32,9 @@ type Element struct {
 	ValueLength            uint32       `json:"value_length"`
 	Tag                    tag.DicomTag `json:"tag"`
 	ValueRepresentation    vr.VRKind    `json:"value_representation"`
+	// Padding holds the trailing spaces and NULs trimmed by the reader from a string value, e.g.: a NUL for a UI
+	// value of odd length
+	Padding string `json:"-"`
 }
 
 type Dataset struct {
@@ -106,6 +109,10 @@ func ReadElement(r *dcmReader, isImplicit bool, binOrder binary.ByteOrder) (*Ele
 	}
 
 	if *tagVal == tag.ItemDelimitationItem || *tagVal == tag.Item {
+		// The private creators of an item only reserve the blocks of the item
+		if *tagVal == tag.Item {
+			r.privateCreators = nil
+		}
 		_ = r.skip(4)
 		return nil, nil
 	}
@@ -118,16 +125,33 @@ func ReadElement(r *dcmReader, isImplicit bool, binOrder binary.ByteOrder) (*Ele
 		return nil, nil
 	}
 	dmcTagName := dcmTagInfo.Name
-	dcmVR, err := readVR(r, isImplicit, *tagVal)
+	if !isImplicit {
+		isImplicit, err = r.isImplicitVR(*tagVal)
+		if err != nil {
+			return nil, err
+		}
+	}
+	dcmVR, err := readVR(r, isImplicit, dcmTagInfo)
 	if err != nil {
-		return nil, err
+		if err = r.truncated(*tagVal, err); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
 	}
 
 	dcmVL, err := readVL(r, isImplicit, *tagVal, dcmVR)
+	if err != nil {
+		if err = r.truncated(*tagVal, err); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+	err = r.checkVL(*tagVal, dcmVR, dcmVL)
 	if err != nil {
 		return nil, err
 	}
 
+	r.padding = ""
 	value, err := readValue(r, *tagVal, dcmVR, dcmVL)
 	if err != nil {
 		return nil, err
@@ -135,6 +159,9 @@ func ReadElement(r *dcmReader, isImplicit bool, binOrder binary.ByteOrder) (*Ele
 	if n, ok := value.([]byte); ok {
 		dcmVL = uint32(len(n))
 	}
+	if tag.IsPrivateCreator(*tagVal) {
+		r.setPrivateCreator(*tagVal, value)
+	}
 
 	elem := Element{
 		Tag:                    *tagVal,
@@ -143,6 +170,10 @@ func ReadElement(r *dcmReader, isImplicit bool, binOrder binary.ByteOrder) (*Ele
 		ValueLength:            dcmVL,
 		Value:                  Value{RawValue: value},
 	}
+	// The padding of the elements of a sequence is theirs
+	if _, ok := value.([]*Element); !ok {
+		elem.Padding = r.padding
+	}
 
 	return &elem, nil
 }
@@ -163,33 +194,41 @@ func readTag(r *dcmReader) (*tag.DicomTag, *tag.TagInfo, error) {
 		Element: element,
 	}
 
-	// Check if tag is private. If yes, just return here
-	// Otherwise, find info about the public tag
-	if int(group)%2 != 0 {
-		tagInfo := tag.TagInfo{
-			VR:     "",
-			Name:   PrivateTag,
-			VM:     "",
-			Status: "",
+	// Private tags are looked up in the private dictionary with the creator reserving their block
+	if tag.IsPrivateTag(group) {
+		tagInfo, err := tag.FindPrivate(t, r.privateCreators[tag.PrivateCreatorTag(t)])
+		if err != nil {
+			tagInfo = tag.TagInfo{
+				VR:     "",
+				Name:   PrivateTag,
+				VM:     "",
+				Status: "",
+			}
 		}
 		return &t, &tagInfo, nil
 	}
 
 	tagInfo, err := tag.Find(t)
 	if err != nil {
-		return nil, nil, err
+		err = r.report(IssueUnknownTag, t, err)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The VR of the unknown tags is read as UN if implicit
+		tagInfo = tag.TagInfo{
+			Name: UnknownTag,
+		}
 	}
 	return &t, &tagInfo, nil
 }
 
-// readVR returns the value representation of the tag
-func readVR(r *dcmReader, isImplicit bool, t tag.DicomTag) (string, error) {
+// readVR returns the value representation of the tag, from the dictionary if the VR is implicit
+func readVR(r *dcmReader, isImplicit bool, tagInfo *tag.TagInfo) (string, error) {
 	if isImplicit {
-		record, err := tag.Find(t)
-		if err != nil {
+		if tagInfo.VR == "" {
 			return vr.Unknown, nil
 		}
-		return record.VR, nil
+		return tagInfo.VR, nil
 	}
 	return r.readString(2)
 }
@@ -216,7 +255,7 @@ func readVL(r *dcmReader, isImplicit bool, t tag.DicomTag, valueRepresentation s
 			(valueRepresentation == vr.UnlimitedCharacters ||
 				valueRepresentation == vr.UniversalResourceIdentifier ||
 				valueRepresentation == vr.UnlimitedText) {
-			return 0, errors.New("UC, UR and UT must have defined length")
+			return 0, r.invalidVL(t, valueRepresentation, valueLength)
 		}
 		return valueLength, nil
 	default:
@@ -239,10 +278,8 @@ func readValue(r *dcmReader, t tag.DicomTag, valueRepresentation string, valueLe
 	// If yes then handles like SQ
 	if valueRepresentation == vr.Unknown && t.Group%2 != 0 {
 		n, err := r.peek(4)
-		if err != nil {
-			return nil, err
-		}
-		if binary.BigEndian.Uint32(n) == 0xFFFEE000 || binary.BigEndian.Uint32(n) == 0xFEFF00E0 || binary.BigEndian.Uint32(n) == VLUndefinedLength {
+		if err == nil && (binary.BigEndian.Uint32(n) == 0xFFFEE000 || binary.BigEndian.Uint32(n) == 0xFEFF00E0 ||
+			binary.BigEndian.Uint32(n) == VLUndefinedLength) {
 			r.SetTransferSyntax(r.ByteOrder(), true)
 			return readSequence(r, t, valueRepresentation, valueLength)
 		}
@@ -334,8 +371,11 @@ func readStringType(r *dcmReader, t tag.DicomTag, valueRepresentation string, va
 	sep := "\\"
 	str, err := r.readString(valueLength)
 	if err != nil {
-		return str, err
+		if err = r.truncated(t, err); err != nil {
+			return nil, err
+		}
 	}
+	r.padding = str[len(strings.TrimRight(str, " \000")):]
 	str = strings.Trim(str, " \000") // There is a space " \000", not "\000"
 	if strings.Contains(str, sep) {
 		strArr := strings.Split(str, sep)
@@ -348,9 +388,6 @@ func readStringType(r *dcmReader, t tag.DicomTag, valueRepresentation string, va
 
 // readPixelDataType reads the raw pixel data
 func readPixelDataType(r *dcmReader, t tag.DicomTag, valueRepresentation string, valueLength uint32) (interface{}, error) {
-	if valueLength%2 != 0 && valueLength != VLUndefinedLength {
-		fmt.Printf("Odd value length encountered for tag: %v with length %d", t.String(), valueLength)
-	}
 	byteSize := r.GetFileSize()
 	if valueLength != VLUndefinedLength {
 		byteSize = int64(valueLength)
@@ -358,12 +395,15 @@ func readPixelDataType(r *dcmReader, t tag.DicomTag, valueRepresentation string,
 
 	bArr := make([]byte, byteSize)
 	n, err := io.ReadFull(r, bArr)
-	sbArr := bArr[:n]
 	if err != nil {
-		if err == io.ErrUnexpectedEOF {
-			return sbArr, nil
+		// The pixel data of undefined length are read until the end of file
+		if err == io.ErrUnexpectedEOF && valueLength == VLUndefinedLength {
+			return bArr[:n], nil
 		}
-		return nil, err
+		if err = r.truncated(t, err); err != nil {
+			return nil, err
+		}
+		return bArr[:n], nil
 	}
 	return bArr, nil
 }
@@ -374,18 +414,14 @@ func readByteType(r *dcmReader, t tag.DicomTag, valueRepresentation string, valu
 	case vr.OtherByte, vr.Unknown, vr.OtherByteOrOtherWord, strings.ToLower(vr.OtherByteOrOtherWord):
 		bArr := make([]byte, valueLength)
 		n, err := io.ReadFull(r, bArr)
-		sbArr := bArr[:n]
 		if err != nil {
-			if err == io.ErrUnexpectedEOF {
-				return sbArr, nil
+			if err = r.truncated(t, err); err != nil {
+				return nil, err
 			}
-			return nil, err
+			return bArr[:n], nil
 		}
 		return bArr, nil
 	case vr.OtherWord:
-		if valueLength%2 != 0 {
-			fmt.Printf("Odd value length encountered for tag: %v with length %d", t.String(), valueLength)
-		}
 		buf := bytes.NewBuffer(make([]byte, 0, valueLength))
 		numWords := int(valueLength / 2)
 		for i := 0; i < numWords; i++ {
@@ -393,15 +429,10 @@ func readByteType(r *dcmReader, t tag.DicomTag, valueRepresentation string, valu
 			if err != nil {
 				// Handle a case when the actual pixel data is less than the value length. Just return what we can
 				// read here
-				if err == io.EOF {
-					err = binary.Write(buf, system.NativeEndian, word)
-					if err != nil {
-						return nil, err
-					}
-					r = nil
-					return buf.Bytes(), nil
+				if err = r.truncated(t, err); err != nil {
+					return nil, err
 				}
-				return nil, err
+				return buf.Bytes(), nil
 			}
 			err = binary.Write(buf, system.NativeEndian, word)
 			if err != nil {
@@ -413,7 +444,7 @@ func readByteType(r *dcmReader, t tag.DicomTag, valueRepresentation string, valu
 	default:
 		_, err := r.discard(int(valueLength))
 		if err != nil {
-			return nil, err
+			return nil, r.truncated(t, err)
 		}
 	}
 	return nil, nil
@@ -425,13 +456,22 @@ func readIntType(r *dcmReader, t tag.DicomTag, valueRepresentation string, value
 	retVal := make([]int, 0, valueLength/2)
 	n, err := r.peek(int(valueLength))
 	if err != nil {
-		return nil, err
+		if err = r.truncated(t, err); err != nil {
+			return nil, err
+		}
+	}
+	// The trailing bytes of a truncated value, or of a value length that is not a multiple of the size of the values,
+	// are ignored
+	end := len(n)
+	if size, ok := valueSizes[valueRepresentation]; ok {
+		end -= end % int(size)
 	}
 	subReader := bytes.NewReader(n)
 	subRd := NewDICOMReader(bufio.NewReader(subReader), WithSkipPixelData(r.SkipPixelData()))
+	subRd.SetTransferSyntax(r.ByteOrder(), r.IsImplicit())
 	byteRead := 0
 	for {
-		if byteRead >= int(valueLength) {
+		if byteRead >= end {
 			break
 		}
 		switch valueRepresentation {
@@ -473,7 +513,7 @@ func readIntType(r *dcmReader, t tag.DicomTag, valueRepresentation string, value
 		}
 		retVal = append(retVal, subVal)
 	}
-	_, _ = r.discard(int(valueLength))
+	_, _ = r.discard(len(n))
 	if len(retVal) == 1 {
 		return retVal[0], nil
 	}
@@ -486,13 +526,22 @@ func readFloatType(r *dcmReader, t tag.DicomTag, valueRepresentation string, val
 	retVal := make([]float64, 0, valueLength/2)
 	n, err := r.peek(int(valueLength))
 	if err != nil {
-		return nil, err
+		if err = r.truncated(t, err); err != nil {
+			return nil, err
+		}
+	}
+	// The trailing bytes of a truncated value, or of a value length that is not a multiple of the size of the values,
+	// are ignored
+	end := len(n)
+	if size, ok := valueSizes[valueRepresentation]; ok {
+		end -= end % int(size)
 	}
 	subReader := bytes.NewReader(n)
 	subRd := NewDICOMReader(bufio.NewReader(subReader), WithSkipPixelData(r.SkipPixelData()))
+	subRd.SetTransferSyntax(r.ByteOrder(), r.IsImplicit())
 	byteRead := 0
 	for {
-		if byteRead >= int(valueLength) {
+		if byteRead >= end {
 			break
 		}
 		switch valueRepresentation {
@@ -513,7 +562,7 @@ func readFloatType(r *dcmReader, t tag.DicomTag, valueRepresentation string, val
 		}
 		retVal = append(retVal, subVal)
 	}
-	_, _ = r.discard(int(valueLength))
+	_, _ = r.discard(len(n))
 	if len(retVal) == 1 {
 		return retVal[0], nil
 	}
@@ -524,12 +573,22 @@ func readFloatType(r *dcmReader, t tag.DicomTag, valueRepresentation string, val
 // readSequence reads the value as sequence of items
 func readSequence(r *dcmReader, t tag.DicomTag, valueRepresentation string, valueLength uint32) (interface{}, error) {
 	var sequences []*Element
+	parentCreators := r.privateCreators
+	r.path = append(r.path, t)
+	defer func() {
+		r.privateCreators = parentCreators
+		r.path = r.path[:len(r.path)-1]
+	}()
 	// Reference: https://dicom.nema.org/dicom/2013/output/chtml/part05/sect_7.5.html
 	if valueLength == VLUndefinedLength {
 		for {
 			subElement, err := ReadElement(r, r.IsImplicit(), r.ByteOrder())
 			if err != nil {
-				return nil, err
+				// The sequence is truncated without sequence delimitation item
+				if err = r.truncated(t, err); err != nil {
+					return nil, err
+				}
+				break
 			}
 
 			if subElement == nil {
@@ -548,23 +607,28 @@ func readSequence(r *dcmReader, t tag.DicomTag, valueRepresentation string, valu
 		n, err := r.peek(int(valueLength))
 		if err != nil {
 			if err == bufio.ErrBufferFull {
+				offset := r.offset
 				bRaw, err := writeToBuf(r, int(valueLength))
 				if err != nil {
-					return nil, err
+					if err = r.truncated(t, err); err != nil {
+						return nil, err
+					}
 				}
-				sequences, err = readDefinedLengthSequences(r, bRaw, valueRepresentation)
+				sequences, err = readDefinedLengthSequences(r, bRaw, offset, valueRepresentation)
 				if err != nil {
 					return nil, err
 				}
 				return sequences, nil
 			}
-			return nil, err
+			if err = r.truncated(t, err); err != nil {
+				return nil, err
+			}
 		}
-		sequences, err = readDefinedLengthSequences(r, n, valueRepresentation)
+		sequences, err = readDefinedLengthSequences(r, n, r.offset, valueRepresentation)
 		if err != nil {
 			return nil, err
 		}
-		_, _ = r.discard(int(valueLength))
+		_, _ = r.discard(len(n))
 	}
 	return sequences, nil
 
@@ -576,7 +640,7 @@ func writeToBuf(r *dcmReader, n int) ([]byte, error) {
 	for i := 0; i < n; i++ {
 		word, err := r.readUInt8()
 		if err != nil {
-			return nil, err
+			return buf.Bytes(), err
 		}
 		err = binary.Write(buf, system.NativeEndian, word)
 		if err != nil {
@@ -586,10 +650,14 @@ func writeToBuf(r *dcmReader, n int) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-func readDefinedLengthSequences(r *dcmReader, b []byte, valueRepresentation string) ([]*Element, error) {
+// readDefinedLengthSequences reads the items of the bytes of a sequence starting at the offset of the file
+func readDefinedLengthSequences(r *dcmReader, b []byte, offset int64, valueRepresentation string) ([]*Element, error) {
 	var sequences []*Element
 	br := bytes.NewReader(b)
-	subRd := NewDICOMReader(bufio.NewReaderSize(br, len(b)), WithSkipPixelData(r.SkipPixelData()))
+	subRd := NewDICOMReader(bufio.NewReaderSize(br, len(b)), WithSkipPixelData(r.SkipPixelData()),
+		WithAllowNonCompliantDcm(r.allowNonCompliantDcm))
+	subRd.offset = offset
+	subRd.path = r.path
 	_ = subRd.skip(8)
 	subRd.SetTransferSyntax(r.ByteOrder(), r.IsImplicit())
 	for {
@@ -606,6 +674,7 @@ func readDefinedLengthSequences(r *dcmReader, b []byte, valueRepresentation stri
 		}
 		sequences = append(sequences, subElement)
 	}
+	r.issues = append(r.issues, subRd.issues...)
 	if valueRepresentation == vr.Unknown {
 		r.SetTransferSyntax(r.ByteOrder(), r.isTrackingImplicit())
 	}
@@ -0,0 +1,60 @@
+package go2com
+
+import (
+	"errors"
+	"fmt"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"io"
+	"strings"
+)
+
+// ErrNotDICOM is returned for the files without the 128 bytes preamble followed by the magic string 'DICM'
+var ErrNotDICOM = errors.New("file is not in valid dicom format")
+
+// ErrUnsupportedTransferSyntax is returned for a transfer syntax UID missing from the registry
+type ErrUnsupportedTransferSyntax = uid.ErrUnsupportedTransferSyntax
+
+// ErrTruncated is returned when the end of file is reached within an element
+type ErrTruncated struct {
+	Tag tag.DicomTag
+	// Path holds the tags of the sequences enclosing the element, from the outermost
+	Path []tag.DicomTag
+	// Offset is the byte offset in the file at which the end of file is reached
+	Offset int64
+}
+
+func (e *ErrTruncated) Error() string {
+	return fmt.Sprintf("unexpected end of file in %s at offset %d", tagPath(e.Path, e.Tag), e.Offset)
+}
+
+func (e *ErrTruncated) Unwrap() error {
+	return io.ErrUnexpectedEOF
+}
+
+// ErrInvalidVL is returned for a value length not allowed for the VR of an element, e.g.: an odd value length, or an
+// undefined length of UT
+type ErrInvalidVL struct {
+	Tag tag.DicomTag
+	VR  string
+	VL  uint32
+	// Path holds the tags of the sequences enclosing the element, from the outermost
+	Path []tag.DicomTag
+	// Offset is the byte offset in the file of the value of the element
+	Offset int64
+}
+
+func (e *ErrInvalidVL) Error() string {
+	return fmt.Sprintf("invalid value length %d of VR %s in %s at offset %d", e.VL, e.VR, tagPath(e.Path, e.Tag), e.Offset)
+}
+
+// tagPath returns the tag preceded by the tags of its enclosing sequences, e.g.: (0008,1140)>(0008,1155)
+func tagPath(path []tag.DicomTag, t tag.DicomTag) string {
+	b := strings.Builder{}
+	for _, sequence := range path {
+		b.WriteString(sequence.String())
+		b.WriteString(">")
+	}
+	b.WriteString(t.String())
+	return b.String()
+}
@@ -15,6 +15,7 @@ import (
 const (
 	MagicString = "DICM"
 	PrivateTag  = "PrivateTag"
+	UnknownTag  = "UnknownTag"
 )
 
 type dcmReader struct {
@@ -27,7 +28,17 @@ type dcmReader struct {
 	keepTrackImplicit    bool
 	skipPixelData        bool
 	skipDataset          bool
+	isRawDataset         bool
 	fileSize             int64
+	// offset is the number of bytes of the file read so far
+	offset int64
+	issues []ParseIssue
+	// path holds the tags of the sequences enclosing the element being read
+	path []tag.DicomTag
+	// privateCreators holds the values of the private creator elements of the dataset or item being read
+	privateCreators map[tag.DicomTag]string
+	// padding holds the trailing padding trimmed from the last string value read
+	padding string
 }
 
 // NewDICOMReader returns a new reader
@@ -47,6 +58,9 @@ func NewDICOMReader(reader *bufio.Reader, options ...func(*dcmReader)) *dcmReade
 
 // WithAllowNonCompliantDcm provides option to keep trying to parse the file even if it's not DICOM compliant
 // e.g.: Missing header, missing FileMetaInformationGroupLength,...
+// The files without preamble are read from their magic string or file meta information, or else as datasets without
+// file meta information, whose transfer syntax is guessed from their first element.
+// If true, the problems are recorded as the issues of the reader. If false, the first problem is returned as error
 func WithAllowNonCompliantDcm(allowNonCompliantDcm bool) func(*dcmReader) {
 	return func(s *dcmReader) {
 		s.allowNonCompliantDcm = allowNonCompliantDcm
@@ -69,6 +83,17 @@ func WithSkipDataset(skipDataset bool) func(*dcmReader) {
 	}
 }
 
+// WithDatasetTransferSyntax provides option to read a dataset without preamble nor file meta information, e.g.: the
+// dataset of a DIMSE message, encoded with the given byte order and VR encoding
+func WithDatasetTransferSyntax(binaryOrder binary.ByteOrder, isImplicit bool) func(*dcmReader) {
+	return func(s *dcmReader) {
+		s.isRawDataset = true
+		s.binaryOrder = binaryOrder
+		s.isImplicit = isImplicit
+		s.keepTrackImplicit = isImplicit
+	}
+}
+
 // WithSetFileSize provides option to set the file size to the reader
 func WithSetFileSize(fileSize int64) func(*dcmReader) {
 	return func(s *dcmReader) {
@@ -111,10 +136,10 @@ func (r *dcmReader) ByteOrder() binary.ByteOrder {
 func (r *dcmReader) IsValidDICOM() error {
 	preamble, err := r.peek(132)
 	if err != nil {
-		return fmt.Errorf("cannot read the first 132 bytes: %v", err)
+		return fmt.Errorf("%w: cannot read the first 132 bytes: %v", ErrNotDICOM, err)
 	}
 	if string(preamble[128:]) != MagicString {
-		return fmt.Errorf("file is not in valid dicom format")
+		return ErrNotDICOM
 	}
 	return nil
 }
@@ -209,7 +234,9 @@ func (r *dcmReader) ExportSeriesTags() MappedTag {
 }
 
 func (r *dcmReader) Read(p []byte) (int, error) {
-	return r.reader.Read(p)
+	n, err := r.reader.Read(p)
+	r.offset += int64(n)
+	return n, err
 }
 
 func (r *dcmReader) IsImplicit() bool {
@@ -232,9 +259,9 @@ func (r *dcmReader) GetFileSize() int64 {
 
 func (r *dcmReader) readString(n uint32) (string, error) {
 	data := make([]byte, n)
-	_, err := io.ReadFull(r, data)
+	m, err := io.ReadFull(r, data)
 	if err != nil {
-		return "", err
+		return string(data[:m]), err
 	}
 	return string(data), nil
 }
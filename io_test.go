@@ -2,8 +2,12 @@ package go2com
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"github.com/okieraised/go2com/internal/utils"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
 	"github.com/stretchr/testify/assert"
 	"os"
 	"testing"
@@ -218,3 +222,57 @@ func TestNewDICOMReader_9(t *testing.T) {
 		_ = rd.ExportDatasetTags(false)
 	}
 }
+
+func TestParse_WithoutPreamble(t *testing.T) {
+	assert := assert.New(t)
+	meta := NewFileMeta("1.2.840.10008.5.1.4.1.1.2", "1.2.3.4", uid.ExplicitVRLittleEndian)
+	ds := Dataset{Elements: []*Element{
+		NewElement(tag.PatientName, "DOE^JOHN"),
+		NewElement(tag.Rows, 512),
+		NewElement(tag.PixelSpacing, []float64{0.5, 0.25}),
+	}}
+	file := bytes.Buffer{}
+	assert.NoError(NewDICOMWriter(&file).WriteFile(meta, ds))
+	encode := func(binaryOrder binary.ByteOrder, isImplicit bool) []byte {
+		buf := bytes.Buffer{}
+		assert.NoError(NewDICOMWriter(&buf, WithWriterTransferSyntax(binaryOrder, isImplicit)).WriteDataset(ds))
+		return buf.Bytes()
+	}
+
+	cases := []struct {
+		name        string
+		data        []byte
+		metadata    int
+		binaryOrder binary.ByteOrder
+		isImplicit  bool
+	}{
+		{"file meta without magic string", file.Bytes()[132:], 7, binary.LittleEndian, false},
+		{"implicit VR little endian", encode(binary.LittleEndian, true), 0, binary.LittleEndian, true},
+		{"explicit VR little endian", encode(binary.LittleEndian, false), 0, binary.LittleEndian, false},
+		{"explicit VR big endian", encode(binary.BigEndian, false), 0, binary.BigEndian, false},
+	}
+	for _, c := range cases {
+		strict := NewDICOMReader(bufio.NewReader(bytes.NewReader(c.data)), WithSetFileSize(int64(len(c.data))))
+		assert.Error(strict.Parse(), c.name)
+
+		rd := NewDICOMReader(bufio.NewReader(bytes.NewReader(c.data)), WithSetFileSize(int64(len(c.data))),
+			WithAllowNonCompliantDcm(true))
+		assert.NoError(rd.Parse(), c.name)
+		assert.Len(rd.GetMetadata().Elements, c.metadata, c.name)
+		assert.Equal(c.binaryOrder, rd.ByteOrder(), c.name)
+		assert.Equal(c.isImplicit, rd.IsImplicit(), c.name)
+		val, err := rd.GetElementByTagString("(0028,0010)")
+		assert.NoError(err, c.name)
+		assert.Equal(Value{RawValue: 512}, val, c.name)
+	}
+
+	// The dataset of a DIMSE message is read with the transfer syntax of its presentation context
+	data := encode(binary.BigEndian, false)
+	rd := NewDICOMReader(bufio.NewReader(bytes.NewReader(data)), WithDatasetTransferSyntax(binary.BigEndian, false))
+	assert.NoError(rd.Parse())
+	assert.Empty(rd.Issues())
+	assert.Len(rd.GetDataset().Elements, 3)
+	val, err := rd.GetElementByTagString("(0028,0030)")
+	assert.NoError(err)
+	assert.Equal(Value{RawValue: []float64{0.5, 0.25}}, val)
+}
@@ -0,0 +1,148 @@
+package go2com
+
+import (
+	"fmt"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+	"io"
+)
+
+// IssueKind is the kind of problem of a non-compliant file
+type IssueKind int
+
+const (
+	// IssueMissingPreamble is a file without the 128 bytes preamble followed by the magic string 'DICM'
+	IssueMissingPreamble IssueKind = iota + 1
+	// IssueGroupLength is a missing FileMetaInformationGroupLength, or one not matching the length of the meta header
+	IssueGroupLength
+	// IssueVRMismatch is a VR not matching the transfer syntax, e.g.: an implicit VR dataset in an explicit VR file
+	IssueVRMismatch
+	// IssueUnknownTag is a public tag missing from the dictionary
+	IssueUnknownTag
+	// IssueInvalidLength is an odd value length, or one that is not a multiple of the size of the values of the VR
+	IssueInvalidLength
+	// IssueTruncated is a value cut short by the end of the file
+	IssueTruncated
+)
+
+func (k IssueKind) String() string {
+	switch k {
+	case IssueMissingPreamble:
+		return "missing preamble"
+	case IssueGroupLength:
+		return "wrong group length"
+	case IssueVRMismatch:
+		return "VR mismatch"
+	case IssueUnknownTag:
+		return "unknown tag"
+	case IssueInvalidLength:
+		return "invalid value length"
+	case IssueTruncated:
+		return "truncated value"
+	default:
+		return fmt.Sprintf("IssueKind(%d)", int(k))
+	}
+}
+
+// ParseIssue is a problem of a non-compliant file. In lenient mode, the reader records the issues and keeps on parsing,
+// in strict mode, the first issue is returned as the error of Parse
+type ParseIssue struct {
+	Kind IssueKind
+	// Tag is the tag of the element of the issue, or the zero tag for the issues of the file
+	Tag tag.DicomTag
+	// Path holds the tags of the sequences enclosing the element, from the outermost
+	Path []tag.DicomTag
+	// Offset is the byte offset in the file at which the issue is detected
+	Offset int64
+	Err    error
+}
+
+func (i ParseIssue) Error() string {
+	if i.Tag == (tag.DicomTag{}) {
+		return fmt.Sprintf("%s at offset %d: %v", i.Kind, i.Offset, i.Err)
+	}
+	return fmt.Sprintf("%s of tag %s at offset %d: %v", i.Kind, tagPath(i.Path, i.Tag), i.Offset, i.Err)
+}
+
+func (i ParseIssue) Unwrap() error {
+	return i.Err
+}
+
+// valueSizes holds the size of the values of the binary VRs, of which the value length is a multiple
+var valueSizes = map[string]uint32{
+	vr.UnsignedShort:       2,
+	vr.SignedShort:         2,
+	"xs":                   2,
+	vr.AttributeTag:        4,
+	vr.UnsignedLong:        4,
+	vr.SignedLong:          4,
+	vr.FloatingPointSingle: 4,
+	vr.FloatingPointDouble: 8,
+	vr.OtherFloat:          4,
+	vr.OtherDouble:         8,
+	vr.OtherLong:           4,
+}
+
+// Issues returns the problems of the file recorded in lenient mode
+func (r *dcmReader) Issues() []ParseIssue {
+	return r.issues
+}
+
+// report records the issue in lenient mode, or returns it as the error in strict mode
+func (r *dcmReader) report(kind IssueKind, t tag.DicomTag, err error) error {
+	issue := ParseIssue{Kind: kind, Tag: t, Path: r.tagPath(), Offset: r.offset, Err: err}
+	if !r.allowNonCompliantDcm {
+		return issue
+	}
+	r.issues = append(r.issues, issue)
+	return nil
+}
+
+// truncated reports the end of file reached within the value of the tag. The other errors are returned as is. In
+// lenient mode, the caller keeps the partial value
+func (r *dcmReader) truncated(t tag.DicomTag, err error) error {
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	return r.report(IssueTruncated, t, &ErrTruncated{Tag: t, Path: r.tagPath(), Offset: r.offset})
+}
+
+// invalidVL returns the error of the value length of the element being read
+func (r *dcmReader) invalidVL(t tag.DicomTag, valueRepresentation string, valueLength uint32) *ErrInvalidVL {
+	return &ErrInvalidVL{Tag: t, VR: valueRepresentation, VL: valueLength, Path: r.tagPath(), Offset: r.offset}
+}
+
+// tagPath returns a copy of the tags of the sequences enclosing the element being read
+func (r *dcmReader) tagPath() []tag.DicomTag {
+	if len(r.path) == 0 {
+		return nil
+	}
+	return append([]tag.DicomTag{}, r.path...)
+}
+
+// checkVL reports the odd value lengths, and those that are not a multiple of the size of the values of the VR
+func (r *dcmReader) checkVL(t tag.DicomTag, valueRepresentation string, valueLength uint32) error {
+	if valueLength == VLUndefinedLength {
+		return nil
+	}
+	size, ok := valueSizes[valueRepresentation]
+	if valueLength%2 != 0 || ok && valueLength%size != 0 {
+		return r.report(IssueInvalidLength, t, r.invalidVL(t, valueRepresentation, valueLength))
+	}
+	return nil
+}
+
+// isImplicitVR returns whether the element of an explicit VR dataset is encoded with implicit VR instead, when the 2
+// bytes following its tag are not a VR. In lenient mode, the element is then read as implicit VR
+func (r *dcmReader) isImplicitVR(t tag.DicomTag) (bool, error) {
+	// The items and delimitation items have no VR
+	if t.Group == 0xFFFE {
+		return false, nil
+	}
+	n, err := r.peek(2)
+	if err != nil || vr.VRMapper[string(n)] {
+		// The end of file is reported by the read of the VR
+		return false, nil
+	}
+	return true, r.report(IssueVRMismatch, t, fmt.Errorf("invalid VR %q of tag %s in explicit VR dataset", n, t))
+}
@@ -0,0 +1,138 @@
+package go2com
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_NonCompliant(t *testing.T) {
+	assert := assert.New(t)
+	meta := NewFileMeta("1.2.840.10008.5.1.4.1.1.2", "1.2.3.4", uid.ExplicitVRLittleEndian)
+	ds := Dataset{Elements: []*Element{
+		NewElement(tag.PatientName, "DOE^JOHN"),
+		NewElement(tag.Rows, 512),
+		NewElement(tag.PixelSpacing, []float64{0.5, 0.25}),
+	}}
+	buf := bytes.Buffer{}
+	assert.NoError(NewDICOMWriter(&buf).WriteFile(meta, ds))
+	file := buf.Bytes()
+
+	implicit := bytes.Buffer{}
+	w := NewDICOMWriter(&implicit)
+	assert.NoError(w.WriteFileMeta(meta))
+	w.SetTransferSyntax(binary.LittleEndian, true)
+	assert.NoError(w.WriteDataset(ds))
+
+	unknown := bytes.Buffer{}
+	assert.NoError(NewDICOMWriter(&unknown).WriteFile(meta, Dataset{Elements: append([]*Element{
+		NewElement(tag.DicomTag{Group: 0x0010, Element: 0x0001}, []byte{1, 2}),
+	}, ds.Elements...)}))
+
+	groupLength := append([]byte{}, file...)
+	binary.LittleEndian.PutUint32(groupLength[140:], binary.LittleEndian.Uint32(groupLength[140:])+2)
+
+	// The Patient ID of odd length ends the dataset
+	oddLength := append(append([]byte{}, file...), 0x10, 0x00, 0x20, 0x00, 'L', 'O', 0x03, 0x00, 'A', 'B', 'C')
+
+	cases := []struct {
+		name     string
+		file     []byte
+		kind     IssueKind
+		issueTag tag.DicomTag
+		elements int
+	}{
+		{"missing preamble", file[128:], IssueMissingPreamble, tag.DicomTag{}, 3},
+		{"wrong group length", groupLength, IssueGroupLength, tag.FileMetaInformationGroupLength, 3},
+		{"implicit dataset", implicit.Bytes(), IssueVRMismatch, tag.PatientName, 3},
+		{"unknown tag", unknown.Bytes(), IssueUnknownTag, tag.DicomTag{Group: 0x0010, Element: 0x0001}, 4},
+		{"odd length", oddLength, IssueInvalidLength, tag.PatientID, 4},
+		{"truncated", file[:len(file)-3], IssueTruncated, tag.PixelSpacing, 3},
+	}
+	for _, c := range cases {
+		strict := NewDICOMReader(bufio.NewReader(bytes.NewReader(c.file)), WithSetFileSize(int64(len(c.file))))
+		err := strict.Parse()
+		var issue ParseIssue
+		if assert.True(errors.As(err, &issue), c.name) {
+			assert.Equal(c.kind, issue.Kind, c.name)
+			assert.Equal(c.issueTag, issue.Tag, c.name)
+			assert.Contains(issue.Error(), fmt.Sprintf("at offset %d", issue.Offset), c.name)
+		}
+
+		lenient := NewDICOMReader(bufio.NewReader(bytes.NewReader(c.file)), WithSetFileSize(int64(len(c.file))),
+			WithAllowNonCompliantDcm(true))
+		assert.NoError(lenient.Parse(), c.name)
+		if assert.Len(lenient.Issues(), 1, c.name) {
+			assert.Equal(c.kind, lenient.Issues()[0].Kind, c.name)
+			assert.Equal(c.issueTag, lenient.Issues()[0].Tag, c.name)
+		}
+		assert.Len(lenient.GetDataset().Elements, c.elements, c.name)
+		val, err := lenient.GetElementByTagString("(0010,0010)")
+		assert.NoError(err, c.name)
+		assert.Equal(Value{RawValue: "DOE^JOHN"}, val, c.name)
+	}
+
+	truncated := NewDICOMReader(bufio.NewReader(bytes.NewReader(file[:len(file)-3])), WithAllowNonCompliantDcm(true))
+	assert.NoError(truncated.Parse())
+	assert.True(errors.Is(truncated.Issues()[0], io.ErrUnexpectedEOF))
+	assert.Equal(int64(len(file)-3), truncated.Issues()[0].Offset)
+}
+
+func TestParse_Errors(t *testing.T) {
+	assert := assert.New(t)
+	parse := func(data []byte, options ...func(*dcmReader)) error {
+		return NewDICOMReader(bufio.NewReader(bytes.NewReader(data)), options...).Parse()
+	}
+
+	assert.True(errors.Is(parse([]byte("DICM")), ErrNotDICOM))
+	assert.True(errors.Is(parse(make([]byte, 256)), ErrNotDICOM))
+
+	buf := bytes.Buffer{}
+	meta := NewFileMeta("1.2.840.10008.5.1.4.1.1.2", "1.2.3.4", "1.2.3")
+	assert.NoError(NewDICOMWriter(&buf).WriteFileMeta(meta))
+	// The empty Patient Name follows the file meta information
+	buf.Write([]byte{0x10, 0x00, 0x10, 0x00, 'P', 'N', 0x00, 0x00})
+	var unsupported *ErrUnsupportedTransferSyntax
+	if assert.True(errors.As(parse(buf.Bytes()), &unsupported)) {
+		assert.Equal("1.2.3", unsupported.UID)
+	}
+
+	buf.Reset()
+	meta = NewFileMeta("1.2.840.10008.5.1.4.1.1.2", "1.2.3.4", uid.ExplicitVRLittleEndian)
+	assert.NoError(NewDICOMWriter(&buf).WriteFile(meta, Dataset{Elements: []*Element{
+		NewElement(tag.PatientName, "DOE^JOHN"),
+		NewElement(tag.ReferencedImageSequence, []*Element{
+			NewElement(tag.ReferencedSOPInstanceUID, "1.2.3.4.5.6"),
+		}),
+	}}))
+	cut := bytes.Index(buf.Bytes(), []byte("1.2.3.4.5.6")) + 3
+	err := parse(buf.Bytes()[:cut])
+	assert.True(errors.Is(err, io.ErrUnexpectedEOF))
+	var truncated *ErrTruncated
+	if assert.True(errors.As(err, &truncated)) {
+		assert.Equal(tag.ReferencedSOPInstanceUID, truncated.Tag)
+		assert.Equal([]tag.DicomTag{tag.ReferencedImageSequence}, truncated.Path)
+		assert.Equal(int64(cut), truncated.Offset)
+	}
+	assert.Contains(err.Error(), fmt.Sprintf("of tag (0008,1140)>(0008,1155) at offset %d", cut))
+
+	// The Text Value of UT VR has an undefined length
+	ut := []byte{0x40, 0x00, 0x60, 0xA1, 'U', 'T', 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF}
+	for _, lenient := range []bool{false, true} {
+		err = parse(ut, WithDatasetTransferSyntax(binary.LittleEndian, false), WithAllowNonCompliantDcm(lenient))
+		var invalid *ErrInvalidVL
+		if assert.True(errors.As(err, &invalid)) {
+			assert.Equal(tag.TextValue, invalid.Tag)
+			assert.Equal("UT", invalid.VR)
+			assert.Equal(VLUndefinedLength, invalid.VL)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package deid
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// Action is an action code of the Basic Application Level Confidentiality Profile
+type Action string
+
+const (
+	// ActionDummy replaces the value with a non-zero length dummy value consistent with the VR
+	ActionDummy Action = "D"
+	// ActionZero replaces the value with a zero length value
+	ActionZero Action = "Z"
+	// ActionRemove removes the attribute
+	ActionRemove Action = "X"
+	// ActionKeep keeps the attribute unchanged, apart from the attributes of the items of a sequence
+	ActionKeep Action = "K"
+	// ActionClean replaces the identifying information of the value with values of similar meaning
+	ActionClean Action = "C"
+	// ActionUID replaces the UID with a new UID, consistently within the Deidentifier
+	ActionUID Action = "U"
+)
+
+// option is a set of retention options of the profile
+type option uint8
+
+const (
+	retainLongitudinal option = 1 << iota
+	retainPatientCharacteristics
+	retainDeviceIdentity
+)
+
+// Dummy values of the D action by VR
+const (
+	DummyString   = "ANONYMOUS"
+	DummyDate     = "19000101"
+	DummyTime     = "000000"
+	DummyDateTime = "19000101000000"
+	DummyAge      = "000Y"
+)
+
+// Deidentifier applies the Basic Application Level Confidentiality Profile of PS3.15 Annex E to datasets. The
+// UIDs are remapped consistently across all the datasets de-identified by the same Deidentifier, so that the
+// instances of a study keep referencing each other. A Deidentifier is safe for concurrent use
+type Deidentifier struct {
+	options          option
+	cleanDescriptors bool
+	// safePrivate holds the element offsets of the safe private attributes by private creator
+	safePrivate   map[string]map[uint8]bool
+	actions       map[tag.DicomTag]Action
+	cleaner       func(text string, identifiers []string) string
+	uidGenerator  func() (string, error)
+	pseudonymizer *Pseudonymizer
+	pixelFilters  []PixelFilter
+
+	mu   sync.Mutex
+	uids map[string]string
+}
+
+// NewDeidentifier returns a Deidentifier applying the Basic Profile with the given options
+func NewDeidentifier(options ...func(*Deidentifier)) *Deidentifier {
+	d := &Deidentifier{
+		safePrivate:  make(map[string]map[uint8]bool),
+		actions:      make(map[tag.DicomTag]Action),
+		cleaner:      cleanText,
+		uidGenerator: uid.GenerateUUID,
+		uids:         make(map[string]string),
+	}
+	for _, opt := range options {
+		opt(d)
+	}
+	return d
+}
+
+// WithRetainLongitudinalTemporalInformation keeps the dates and times (Retain Longitudinal Temporal Information
+// with Full Dates Option)
+func WithRetainLongitudinalTemporalInformation(retain bool) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.setOption(retainLongitudinal, retain)
+	}
+}
+
+// WithRetainPatientCharacteristics keeps the physical characteristics of the patient, e.g.: sex, age, size and weight
+// (Retain Patient Characteristics Option)
+func WithRetainPatientCharacteristics(retain bool) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.setOption(retainPatientCharacteristics, retain)
+	}
+}
+
+// WithRetainDeviceIdentity keeps the attributes identifying the equipment (Retain Device Identity Option)
+func WithRetainDeviceIdentity(retain bool) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.setOption(retainDeviceIdentity, retain)
+	}
+}
+
+// WithCleanDescriptors cleans the descriptions and comments instead of removing them (Clean Descriptors Option)
+func WithCleanDescriptors(clean bool) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.cleanDescriptors = clean
+	}
+}
+
+// WithSafePrivateTag keeps the private attributes of the creator at the element offsets, e.g.: 0x0C for (0019,xx0C),
+// known not to contain identifying information (Retain Safe Private Option). Other private attributes are removed
+func WithSafePrivateTag(creator string, elements ...uint8) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		if d.safePrivate[creator] == nil {
+			d.safePrivate[creator] = make(map[uint8]bool)
+		}
+		for _, element := range elements {
+			d.safePrivate[creator][element] = true
+		}
+	}
+}
+
+// WithAction overrides the action of the profile for the tag
+func WithAction(t tag.DicomTag, action Action) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.actions[t] = action
+	}
+}
+
+// WithCleaner sets the function cleaning the text of the C action. It is given the identifying values of the
+// dataset, e.g.: the patient name components and IDs. By default, these values are removed from the text
+func WithCleaner(cleaner func(text string, identifiers []string) string) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.cleaner = cleaner
+	}
+}
+
+// WithUIDGenerator sets the function generating the replacement UIDs. By default, UIDs are derived from random UUIDs
+// under the 2.25 root
+func WithUIDGenerator(generator func() (string, error)) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.uidGenerator = generator
+	}
+}
+
+func (d *Deidentifier) setOption(opt option, set bool) {
+	if set {
+		d.options |= opt
+	} else {
+		d.options &^= opt
+	}
+}
+
+// Deidentify returns the de-identified copy of the dataset, including the attributes of the nested sequences. The
+// PatientIdentityRemoved, DeidentificationMethod and DeidentificationMethodCodeSequence attributes are set to record
+// the applied profile and options. The dataset is left unchanged
+func (d *Deidentifier) Deidentify(ds go2com.Dataset) (go2com.Dataset, error) {
+	s := &scope{identifiers: identifiers(ds)}
+	if elem := findElement(ds, tag.PatientID); elem != nil {
+		s.patientID = stringValue(elem)
+	}
+	elements, err := d.deidentify(ds.Elements, s)
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	res := go2com.Dataset{Elements: elements}
+	d.setMethod(&res)
+	return res, nil
+}
+
+// DeidentifyFile de-identifies the dataset of a file, replacing the MediaStorageSOPInstanceUID of the file meta
+// information consistently with the SOPInstanceUID. The burned-in annotations are blanked with the pixel filters
+func (d *Deidentifier) DeidentifyFile(meta, ds go2com.Dataset) (go2com.Dataset, go2com.Dataset, error) {
+	res, err := d.Deidentify(ds)
+	if err != nil {
+		return go2com.Dataset{}, go2com.Dataset{}, err
+	}
+	if len(d.pixelFilters) > 0 {
+		var transferSyntaxUID string
+		if elem := findElement(meta, tag.TransferSyntaxUID); elem != nil {
+			transferSyntaxUID = stringValue(elem)
+		}
+		// The filters match the equipment of the original dataset, as it may be removed by the profile
+		for _, filter := range d.pixelFilters {
+			if filter.Match(ds) {
+				res, err = BlankRegions(res, transferSyntaxUID, filter.Regions)
+				if err != nil {
+					return go2com.Dataset{}, go2com.Dataset{}, err
+				}
+				break
+			}
+		}
+	}
+	resMeta := go2com.Dataset{Elements: make([]*go2com.Element, 0, len(meta.Elements))}
+	for _, elem := range meta.Elements {
+		if elem == nil {
+			continue
+		}
+		if elem.Tag == tag.MediaStorageSOPInstanceUID {
+			elem, err = d.replaceUIDs(elem)
+			if err != nil {
+				return go2com.Dataset{}, go2com.Dataset{}, err
+			}
+		}
+		resMeta.Elements = append(resMeta.Elements, elem)
+	}
+	return resMeta, res, nil
+}
+
+// MapUID returns the replacement of the UID, generating it on first use. With a Pseudonymizer, the replacement is
+// derived from the UID and the key
+func (d *Deidentifier) MapUID(uid string) (string, error) {
+	if d.pseudonymizer != nil {
+		return d.pseudonymizer.UID(uid)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if mapped, ok := d.uids[uid]; ok {
+		return mapped, nil
+	}
+	mapped, err := d.uidGenerator()
+	if err != nil {
+		return "", fmt.Errorf("deid: cannot generate UID: %v", err)
+	}
+	d.uids[uid] = mapped
+	return mapped, nil
+}
+
+// actionOf returns the action applied to the standard attribute
+func (d *Deidentifier) actionOf(t tag.DicomTag) Action {
+	if action, ok := d.actions[t]; ok {
+		return action
+	}
+	r, ok := basicProfile[t]
+	if !ok {
+		switch {
+		// Curve data (50xx,xxxx) and overlay data (60xx,3000)
+		case t.Group&0xFF00 == 0x5000, t.Group&0xFF00 == 0x6000 && t.Element == 0x3000:
+			return ActionRemove
+		// Overlay comments (60xx,4000)
+		case t.Group&0xFF00 == 0x6000 && t.Element == 0x4000:
+			r = rule{basic: "X", clean: true}
+		default:
+			return ActionKeep
+		}
+	}
+	switch {
+	case r.retain&d.options != 0:
+		return ActionKeep
+	case r.clean && d.cleanDescriptors:
+		return ActionClean
+	}
+	return resolveAction(r.basic)
+}
+
+// resolveAction returns the action applied for an action of the table. Without knowledge of the IOD, the compound
+// actions keep the attribute when it may be required: X/Z and X/Z/D as Z, X/D and Z/D as D, and X/Z/U* as U, which
+// replaces the UIDs of the items of the sequence
+func resolveAction(basic string) Action {
+	switch basic {
+	case "X/Z", "X/Z/D":
+		return ActionZero
+	case "X/D", "Z/D":
+		return ActionDummy
+	case "X/Z/U*":
+		return ActionUID
+	}
+	return Action(basic)
+}
+
+// scope holds the values of the de-identified dataset used for the attributes of its sequences
+type scope struct {
+	// identifiers holds the identifying values to clean from the descriptors
+	identifiers []string
+	// patientID is the original PatientID, selecting the date offset
+	patientID string
+}
+
+// deidentify returns the de-identified copies of the elements of a dataset or sequence item
+func (d *Deidentifier) deidentify(elements []*go2com.Element, s *scope) ([]*go2com.Element, error) {
+	safe := d.safePrivateElements(elements)
+	res := make([]*go2com.Element, 0, len(elements))
+	for _, elem := range elements {
+		if elem == nil {
+			continue
+		}
+		if elem.Tag.Group%2 == 1 {
+			if safe[elem.Tag] {
+				res = append(res, elem)
+			}
+			continue
+		}
+
+		if d.pseudonymizer != nil {
+			pseudonymized, ok, err := d.pseudonymize(elem, s)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				res = append(res, pseudonymized)
+				continue
+			}
+		}
+
+		action := d.actionOf(elem.Tag)
+		var err error
+		switch action {
+		case ActionRemove:
+			continue
+		case ActionZero:
+			elem = replaceValue(elem, zeroValue(elem))
+		case ActionDummy:
+			if isSequence(elem) {
+				elem, err = d.deidentifySequence(elem, s)
+			} else if elem.ValueRepresentationStr == vr.UniqueIdentifier {
+				elem, err = d.replaceUIDs(elem)
+			} else {
+				elem = replaceValue(elem, dummyValue(elem))
+			}
+		case ActionClean:
+			if isSequence(elem) {
+				elem, err = d.deidentifySequence(elem, s)
+			} else {
+				elem = d.clean(elem, s.identifiers)
+			}
+		case ActionUID:
+			if isSequence(elem) {
+				elem, err = d.deidentifySequence(elem, s)
+			} else {
+				elem, err = d.replaceUIDs(elem)
+			}
+		default:
+			if isSequence(elem) {
+				elem, err = d.deidentifySequence(elem, s)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, elem)
+	}
+	return res, nil
+}
+
+// deidentifySequence returns the copy of the sequence with its items de-identified. Items left empty are removed
+func (d *Deidentifier) deidentifySequence(elem *go2com.Element, s *scope) (*go2com.Element, error) {
+	subElements := make([]*go2com.Element, 0)
+	for _, item := range splitItems(elem) {
+		res, err := d.deidentify(item, s)
+		if err != nil {
+			return nil, err
+		}
+		subElements = append(subElements, res...)
+	}
+	return replaceValue(elem, subElements), nil
+}
+
+// safePrivateElements returns the safe private attributes of the elements and their private creators
+func (d *Deidentifier) safePrivateElements(elements []*go2com.Element) map[tag.DicomTag]bool {
+	safe := make(map[tag.DicomTag]bool)
+	if len(d.safePrivate) == 0 {
+		return safe
+	}
+	creators := make(map[tag.DicomTag]string)
+	for _, elem := range elements {
+		if elem != nil && elem.Tag.Group%2 == 1 && elem.Tag.Element >= 0x0010 && elem.Tag.Element <= 0x00FF {
+			creators[elem.Tag] = strings.TrimSpace(stringValue(elem))
+		}
+	}
+	for _, elem := range elements {
+		if elem == nil || elem.Tag.Group%2 == 0 || elem.Tag.Element < 0x1000 {
+			continue
+		}
+		creatorTag := tag.DicomTag{Group: elem.Tag.Group, Element: elem.Tag.Element >> 8}
+		if d.safePrivate[creators[creatorTag]][uint8(elem.Tag.Element&0xFF)] {
+			safe[elem.Tag] = true
+			safe[creatorTag] = true
+		}
+	}
+	return safe
+}
+
+// replaceUIDs returns the copy of the element with its UIDs replaced
+func (d *Deidentifier) replaceUIDs(elem *go2com.Element) (*go2com.Element, error) {
+	switch v := elem.Value.RawValue.(type) {
+	case string:
+		if v == "" {
+			return elem, nil
+		}
+		mapped, err := d.MapUID(v)
+		if err != nil {
+			return nil, err
+		}
+		return replaceValue(elem, mapped), nil
+	case []string:
+		mapped := make([]string, 0, len(v))
+		for _, uid := range v {
+			m, err := d.MapUID(uid)
+			if err != nil {
+				return nil, err
+			}
+			mapped = append(mapped, m)
+		}
+		return replaceValue(elem, mapped), nil
+	}
+	return elem, nil
+}
+
+// clean returns the copy of the element with the identifiers cleaned from its text values
+func (d *Deidentifier) clean(elem *go2com.Element, identifiers []string) *go2com.Element {
+	switch v := elem.Value.RawValue.(type) {
+	case string:
+		return replaceValue(elem, d.cleaner(v, identifiers))
+	case []string:
+		cleaned := make([]string, 0, len(v))
+		for _, s := range v {
+			cleaned = append(cleaned, d.cleaner(s, identifiers))
+		}
+		return replaceValue(elem, cleaned)
+	}
+	return elem
+}
+
+// setMethod records the de-identification in the dataset
+func (d *Deidentifier) setMethod(ds *go2com.Dataset) {
+	codes := [][2]string{{"113100", "Basic Application Confidentiality Profile"}}
+	methods := []string{"Basic Application Confidentiality Profile"}
+	if d.options&retainLongitudinal != 0 {
+		codes = append(codes, [2]string{"113106", "Retain Longitudinal Temporal Information Full Dates Option"})
+	} else if d.shiftDates() {
+		codes = append(codes, [2]string{"113107", "Retain Longitudinal Temporal Information Modified Dates Option"})
+	}
+	if d.options&retainPatientCharacteristics != 0 {
+		codes = append(codes, [2]string{"113108", "Retain Patient Characteristics Option"})
+	}
+	if d.options&retainDeviceIdentity != 0 {
+		codes = append(codes, [2]string{"113109", "Retain Device Identity Option"})
+	}
+	if d.cleanDescriptors {
+		codes = append(codes, [2]string{"113105", "Clean Descriptors Option"})
+	}
+	if len(d.safePrivate) > 0 {
+		codes = append(codes, [2]string{"113111", "Retain Safe Private Option"})
+	}
+	items := make([]*go2com.Element, 0, 3*len(codes))
+	for _, code := range codes[1:] {
+		methods = append(methods, code[1])
+	}
+	for _, code := range codes {
+		items = append(items,
+			go2com.NewElement(tag.CodeValue, code[0]),
+			go2com.NewElement(tag.CodingSchemeDesignator, "DCM"),
+			go2com.NewElement(tag.CodeMeaning, code[1]),
+		)
+	}
+	temporal := "REMOVED"
+	if d.options&retainLongitudinal != 0 {
+		temporal = "UNMODIFIED"
+	} else if d.shiftDates() {
+		temporal = "MODIFIED"
+	}
+
+	setElements(ds, []*go2com.Element{
+		go2com.NewElement(tag.PatientIdentityRemoved, "YES"),
+		go2com.NewElement(tag.DeidentificationMethod, methods),
+		go2com.NewElement(tag.DeidentificationMethodCodeSequence, items),
+		go2com.NewElement(tag.LongitudinalTemporalInformationModified, temporal),
+	})
+}
+
+// setElements replaces or inserts the elements, keeping the dataset in ascending tag order
+func setElements(ds *go2com.Dataset, elements []*go2com.Element) {
+	replaced := make(map[tag.DicomTag]*go2com.Element, len(elements))
+	for _, elem := range elements {
+		replaced[elem.Tag] = elem
+	}
+	res := make([]*go2com.Element, 0, len(ds.Elements)+len(elements))
+	for _, elem := range ds.Elements {
+		if _, ok := replaced[elem.Tag]; !ok {
+			res = append(res, elem)
+		}
+	}
+	res = append(res, elements...)
+	sort.SliceStable(res, func(i, j int) bool {
+		return tagLess(res[i].Tag, res[j].Tag)
+	})
+	ds.Elements = res
+}
+
+// identifiers returns the identifying values of the dataset to clean from the descriptors
+func identifiers(ds go2com.Dataset) []string {
+	res := make([]string, 0)
+	for _, t := range []tag.DicomTag{
+		tag.PatientName, tag.OtherPatientNames, tag.PatientBirthName, tag.PatientMotherBirthName,
+		tag.ReferringPhysicianName, tag.PerformingPhysicianName, tag.OperatorsName,
+		tag.PatientID, tag.OtherPatientIDs, tag.AccessionNumber, tag.StudyID, tag.MedicalRecordLocator,
+		tag.PatientBirthDate, tag.PatientAddress, tag.InstitutionName,
+	} {
+		elem := findElement(ds, t)
+		if elem == nil {
+			continue
+		}
+		for _, value := range stringValues(elem) {
+			parts := []string{value}
+			if elem.ValueRepresentationStr == vr.PersonName {
+				parts = strings.FieldsFunc(value, func(r rune) bool {
+					return r == '^' || r == '='
+				})
+			}
+			for _, part := range parts {
+				// Single characters, e.g.: initials, would erase unrelated text
+				if part = strings.TrimSpace(part); len(part) > 1 {
+					res = append(res, part)
+				}
+			}
+		}
+	}
+	return res
+}
+
+// cleanText removes the identifiers from the text, ignoring case
+func cleanText(text string, identifiers []string) string {
+	for _, identifier := range identifiers {
+		text = regexp.MustCompile(`(?i)`+regexp.QuoteMeta(identifier)).ReplaceAllString(text, "")
+	}
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// replaceValue returns a copy of the element with the value
+func replaceValue(elem *go2com.Element, value interface{}) *go2com.Element {
+	res := *elem
+	res.Value = go2com.Value{RawValue: value}
+	return &res
+}
+
+func isSequence(elem *go2com.Element) bool {
+	_, ok := elem.Value.RawValue.([]*go2com.Element)
+	return ok || elem.ValueRepresentationStr == vr.SequenceOfItems
+}
+
+func isString(valueRepresentation string) bool {
+	switch valueRepresentation {
+	case vr.ApplicationEntity, vr.AgeString, vr.CodeString, vr.Date, vr.DecimalString, vr.DateTime,
+		vr.IntegerString, vr.LongString, vr.LongText, vr.PersonName, vr.ShortString, vr.ShortText, vr.Time,
+		vr.UnlimitedCharacters, vr.UniqueIdentifier, vr.UniversalResourceIdentifier, vr.UnlimitedText:
+		return true
+	}
+	return false
+}
+
+// zeroValue returns the zero length value of the element
+func zeroValue(elem *go2com.Element) interface{} {
+	switch {
+	case isSequence(elem):
+		return []*go2com.Element{}
+	case isString(elem.ValueRepresentationStr):
+		return ""
+	}
+	return nil
+}
+
+// dummyValue returns the dummy value of the element, consistent with its VR
+func dummyValue(elem *go2com.Element) interface{} {
+	switch elem.ValueRepresentationStr {
+	case vr.Date:
+		return DummyDate
+	case vr.Time:
+		return DummyTime
+	case vr.DateTime:
+		return DummyDateTime
+	case vr.AgeString:
+		return DummyAge
+	case vr.DecimalString, vr.IntegerString:
+		return "0"
+	case vr.ApplicationEntity, vr.CodeString, vr.LongString, vr.LongText, vr.PersonName, vr.ShortString,
+		vr.ShortText, vr.UnlimitedCharacters, vr.UnlimitedText:
+		return DummyString
+	case vr.UnsignedShort, vr.SignedShort, vr.UnsignedLong, vr.SignedLong, vr.UnsignedVeryLong, vr.SignedVeryLong:
+		return 0
+	case vr.FloatingPointSingle, vr.FloatingPointDouble:
+		return float64(0)
+	}
+	return zeroValue(elem)
+}
+
+// splitItems splits the elements of a parsed sequence into its items, a new item starting when the tags stop
+// ascending
+func splitItems(elem *go2com.Element) [][]*go2com.Element {
+	subElements, _ := elem.Value.RawValue.([]*go2com.Element)
+	items := make([][]*go2com.Element, 0)
+	var current []*go2com.Element
+	for _, subElem := range subElements {
+		if subElem == nil {
+			continue
+		}
+		if len(current) > 0 && !tagLess(current[len(current)-1].Tag, subElem.Tag) {
+			items = append(items, current)
+			current = nil
+		}
+		current = append(current, subElem)
+	}
+	if len(current) > 0 {
+		items = append(items, current)
+	}
+	return items
+}
+
+func tagLess(a, b tag.DicomTag) bool {
+	if a.Group != b.Group {
+		return a.Group < b.Group
+	}
+	return a.Element < b.Element
+}
+
+// findElement returns the element of the tag, skipping the nil elements left by the reader, or nil if not found
+func findElement(ds go2com.Dataset, t tag.DicomTag) *go2com.Element {
+	for _, elem := range ds.Elements {
+		if elem != nil && elem.Tag == t {
+			return elem
+		}
+	}
+	return nil
+}
+
+func stringValue(elem *go2com.Element) string {
+	values := stringValues(elem)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func stringValues(elem *go2com.Element) []string {
+	switch v := elem.Value.RawValue.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, "\\")
+	case []string:
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package deid
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func privateElement(t tag.DicomTag, valueRepresentation string, value interface{}) *go2com.Element {
+	return &go2com.Element{Tag: t, TagName: go2com.PrivateTag, ValueRepresentationStr: valueRepresentation, Value: go2com.Value{RawValue: value}}
+}
+
+func instance(sopInstanceUID, referencedUID string) go2com.Dataset {
+	return go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.SOPClassUID, "1.2.840.10008.5.1.4.1.1.7"),
+		go2com.NewElement(tag.SOPInstanceUID, sopInstanceUID),
+		go2com.NewElement(tag.StudyDate, "20200102"),
+		go2com.NewElement(tag.AccessionNumber, "ACC123"),
+		go2com.NewElement(tag.Modality, "OT"),
+		go2com.NewElement(tag.InstitutionName, "General Hospital"),
+		go2com.NewElement(tag.StationName, "STATION1"),
+		go2com.NewElement(tag.StudyDescription, "Follow-up of John Doe"),
+		go2com.NewElement(tag.ReferencedImageSequence, []*go2com.Element{
+			go2com.NewElement(tag.ReferencedSOPClassUID, "1.2.840.10008.5.1.4.1.1.7"),
+			go2com.NewElement(tag.ReferencedSOPInstanceUID, referencedUID),
+		}),
+		privateElement(tag.DicomTag{Group: 0x0009, Element: 0x0010}, "LO", "ACME 1.0"),
+		privateElement(tag.DicomTag{Group: 0x0009, Element: 0x1001}, "LO", "John Doe"),
+		privateElement(tag.DicomTag{Group: 0x0009, Element: 0x1002}, "DS", "1.5"),
+		go2com.NewElement(tag.PatientName, "Doe^John"),
+		go2com.NewElement(tag.PatientID, "12345"),
+		go2com.NewElement(tag.PatientBirthDate, "19700101"),
+		go2com.NewElement(tag.PatientSex, "M"),
+		go2com.NewElement(tag.PatientAge, "050Y"),
+		go2com.NewElement(tag.DeviceSerialNumber, "SN42"),
+		go2com.NewElement(tag.StorageMediaFileSetID, "DISC1"),
+		go2com.NewElement(tag.StudyInstanceUID, "1.2.3"),
+		go2com.NewElement(tag.SeriesInstanceUID, "1.2.3.4"),
+	}}
+}
+
+func value(ds go2com.Dataset, t tag.DicomTag) interface{} {
+	elem, err := ds.FindElementByTag(t)
+	if err != nil {
+		return nil
+	}
+	return elem.Value.RawValue
+}
+
+func TestDeidentify(t *testing.T) {
+	assert := assert.New(t)
+	d := NewDeidentifier()
+	first, err := d.Deidentify(instance("1.2.3.4.1", "1.2.3.4.2"))
+	assert.NoError(err)
+	second, err := d.Deidentify(instance("1.2.3.4.2", "1.2.3.4.1"))
+	assert.NoError(err)
+
+	for _, t := range []tag.DicomTag{tag.PatientName, tag.PatientID, tag.PatientBirthDate, tag.PatientSex, tag.AccessionNumber, tag.StudyDate, tag.InstitutionName, tag.StationName, tag.DeviceSerialNumber} {
+		assert.Equal("", value(first, t), t.String())
+	}
+	for _, t := range []tag.DicomTag{tag.PatientAge, tag.StudyDescription, tag.StorageMediaFileSetID, {Group: 0x0009, Element: 0x0010}, {Group: 0x0009, Element: 0x1001}} {
+		_, err = first.FindElementByTag(t)
+		assert.Error(err, t.String())
+	}
+	assert.Equal("OT", value(first, tag.Modality))
+	assert.Equal("YES", value(first, tag.PatientIdentityRemoved))
+
+	// UIDs are remapped consistently across the instances of the study, including the references
+	assert.Equal(value(first, tag.StudyInstanceUID), value(second, tag.StudyInstanceUID))
+	assert.NotEqual("1.2.3", value(first, tag.StudyInstanceUID))
+	assert.Regexp(`^2\.25\.\d+$`, value(first, tag.SOPInstanceUID))
+	refs := value(second, tag.ReferencedImageSequence).([]*go2com.Element)
+	assert.Equal(value(first, tag.SOPInstanceUID), refs[1].Value.RawValue)
+	assert.Equal("1.2.840.10008.5.1.4.1.1.7", refs[0].Value.RawValue)
+}
+
+func TestDeidentify_Options(t *testing.T) {
+	assert := assert.New(t)
+	ds := instance("1.2.3.4.1", "1.2.3.4.2")
+	d := NewDeidentifier(
+		WithRetainLongitudinalTemporalInformation(true),
+		WithRetainPatientCharacteristics(true),
+		WithRetainDeviceIdentity(true),
+		WithCleanDescriptors(true),
+		WithSafePrivateTag("ACME 1.0", 0x02),
+		WithAction(tag.Modality, ActionDummy),
+	)
+	res, err := d.Deidentify(ds)
+	assert.NoError(err)
+	assert.Equal("20200102", value(res, tag.StudyDate))
+	assert.Equal("M", value(res, tag.PatientSex))
+	assert.Equal("050Y", value(res, tag.PatientAge))
+	assert.Equal("SN42", value(res, tag.DeviceSerialNumber))
+	assert.Equal("STATION1", value(res, tag.StationName))
+	assert.Equal("Follow-up of", value(res, tag.StudyDescription))
+	assert.Equal(DummyString, value(res, tag.Modality))
+	assert.Equal("ACME 1.0", value(res, tag.DicomTag{Group: 0x0009, Element: 0x0010}))
+	assert.Equal("1.5", value(res, tag.DicomTag{Group: 0x0009, Element: 0x1002}))
+	assert.Nil(value(res, tag.DicomTag{Group: 0x0009, Element: 0x1001}))
+	assert.Len(value(res, tag.DeidentificationMethodCodeSequence), 6*3)
+
+	// The original dataset is left unchanged
+	assert.Equal("Doe^John", value(ds, tag.PatientName))
+	assert.Equal("1.2.3", value(ds, tag.StudyInstanceUID))
+}
+
+func TestDeidentifyFile(t *testing.T) {
+	assert := assert.New(t)
+	data, err := os.ReadFile("../../../dicom_test/014.dcm")
+	assert.NoError(err)
+	rd := go2com.NewDICOMReader(bufio.NewReader(bytes.NewReader(data)), go2com.WithSetFileSize(int64(len(data))))
+	assert.NoError(rd.Parse())
+
+	d := NewDeidentifier()
+	meta, ds, err := d.DeidentifyFile(rd.GetMetadata(), rd.GetDataset())
+	assert.NoError(err)
+	assert.Equal(value(ds, tag.SOPInstanceUID), value(meta, tag.MediaStorageSOPInstanceUID))
+
+	buf := bytes.Buffer{}
+	assert.NoError(go2com.NewDICOMWriter(&buf).WriteFile(meta, ds))
+	rd = go2com.NewDICOMReader(bufio.NewReader(bytes.NewReader(buf.Bytes())), go2com.WithSetFileSize(int64(buf.Len())))
+	assert.NoError(rd.Parse())
+	res := rd.GetDataset()
+	assert.Equal("", value(res, tag.PatientName))
+	assert.Equal(value(ds, tag.StudyInstanceUID), value(res, tag.StudyInstanceUID))
+	for _, elem := range res.Elements {
+		if elem != nil {
+			assert.Equal(uint16(0), elem.Tag.Group%2, elem.Tag.String())
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package deid
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// PixelFilter selects the images with annotations burned in the pixels by their equipment and size, like the filter
+// scripts of the RSNA Clinical Trial Processor, and the regions to blank. Empty or zero fields match any value
+type PixelFilter struct {
+	// Manufacturer and ModelName are matched case-insensitively with the Manufacturer and ManufacturerModelName
+	Manufacturer string
+	ModelName    string
+	Rows         int
+	Columns      int
+	// Regions holds the rectangles to blank, in pixels from the top left corner of the frames
+	Regions []image.Rectangle
+}
+
+// Match returns true if the filter selects the image
+func (f PixelFilter) Match(ds go2com.Dataset) bool {
+	return matchString(f.Manufacturer, ds, tag.Manufacturer) && matchString(f.ModelName, ds, tag.ManufacturerModelName) &&
+		(f.Rows == 0 || f.Rows == intValue(ds, tag.Rows, 0)) &&
+		(f.Columns == 0 || f.Columns == intValue(ds, tag.Columns, 0))
+}
+
+// Frames holds the native pixel data of an image split into frames
+type Frames struct {
+	Rows                int
+	Columns             int
+	SamplesPerPixel     int
+	BitsAllocated       int
+	PlanarConfiguration int
+	// Data holds the frames as encoded in the transfer syntax of the image
+	Data [][]byte
+}
+
+// HasBurnedInAnnotation returns true if the image may have annotations burned in the pixels: images flagged by
+// BurnedInAnnotation, and ultrasound and secondary capture images that are not flagged as free of annotations
+func HasBurnedInAnnotation(ds go2com.Dataset) bool {
+	switch strings.ToUpper(datasetString(ds, tag.BurnedInAnnotation)) {
+	case "YES":
+		return true
+	case "NO":
+		return false
+	}
+	// The multi-frame secondary capture SOP classes are under the UID of the Secondary Capture Image Storage
+	sopClassUID := datasetString(ds, tag.SOPClassUID)
+	return datasetString(ds, tag.Modality) == "US" || sopClassUID == uid.SecondaryCaptureImageStorage ||
+		strings.HasPrefix(sopClassUID, uid.SecondaryCaptureImageStorage+".")
+}
+
+// DecodeFrames returns the frames of the native pixel data of the image. Only the Implicit VR Little Endian, Explicit
+// VR Little Endian and Explicit VR Big Endian transfer syntaxes are supported
+func DecodeFrames(ds go2com.Dataset, transferSyntaxUID string) (*Frames, error) {
+	switch transferSyntaxUID {
+	case uid.ImplicitVRLittleEndian, uid.ExplicitVRLittleEndian, uid.ExplicitVRBigEndian:
+	default:
+		return nil, fmt.Errorf("deid: cannot decode pixel data of transfer syntax %s", transferSyntaxUID)
+	}
+	elem := findElement(ds, tag.PixelData)
+	if elem == nil {
+		return nil, fmt.Errorf("deid: no pixel data")
+	}
+	data, ok := elem.Value.RawValue.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("deid: pixel data of type %T", elem.Value.RawValue)
+	}
+
+	f := &Frames{
+		Rows:                intValue(ds, tag.Rows, 0),
+		Columns:             intValue(ds, tag.Columns, 0),
+		SamplesPerPixel:     intValue(ds, tag.SamplesPerPixel, 1),
+		BitsAllocated:       intValue(ds, tag.BitsAllocated, 0),
+		PlanarConfiguration: intValue(ds, tag.PlanarConfiguration, 0),
+	}
+	numberOfFrames := intValue(ds, tag.NumberOfFrames, 1)
+	if f.BitsAllocated != 1 && f.BitsAllocated%8 != 0 {
+		return nil, fmt.Errorf("deid: unsupported BitsAllocated %d", f.BitsAllocated)
+	}
+	if photometric := datasetString(ds, tag.PhotometricInterpretation); strings.HasPrefix(photometric, "YBR") && photometric != "YBR_FULL" {
+		return nil, fmt.Errorf("deid: unsupported photometric interpretation %s", photometric)
+	}
+	frameBits := f.Rows * f.Columns * f.SamplesPerPixel * f.BitsAllocated
+	if frameBits%8 != 0 {
+		return nil, fmt.Errorf("deid: frames of %d bits are not aligned on bytes", frameBits)
+	}
+	frameSize := frameBits / 8
+	if frameSize == 0 || len(data) < frameSize*numberOfFrames {
+		return nil, fmt.Errorf("deid: pixel data of %d bytes is shorter than %d frames of %d bytes", len(data), numberOfFrames, frameSize)
+	}
+	for i := 0; i < numberOfFrames; i++ {
+		frame := make([]byte, frameSize)
+		copy(frame, data[i*frameSize:])
+		f.Data = append(f.Data, frame)
+	}
+	return f, nil
+}
+
+// Blank sets the samples of the pixels of the regions to zero in all the frames. The regions are clipped to the
+// frames
+func (f *Frames) Blank(regions ...image.Rectangle) {
+	bounds := image.Rect(0, 0, f.Columns, f.Rows)
+	bytesPerSample := f.BitsAllocated / 8
+	planeSize := f.Rows * f.Columns * bytesPerSample
+	for _, frame := range f.Data {
+		for _, region := range regions {
+			region = region.Intersect(bounds)
+			for y := region.Min.Y; y < region.Max.Y; y++ {
+				for x := region.Min.X; x < region.Max.X; x++ {
+					pixel := y*f.Columns + x
+					switch {
+					case f.BitsAllocated == 1:
+						frame[pixel/8] &^= 1 << (pixel % 8)
+					case f.PlanarConfiguration == 0 || f.SamplesPerPixel == 1:
+						offset := pixel * f.SamplesPerPixel * bytesPerSample
+						zero(frame[offset : offset+f.SamplesPerPixel*bytesPerSample])
+					default:
+						for sample := 0; sample < f.SamplesPerPixel; sample++ {
+							offset := sample*planeSize + pixel*bytesPerSample
+							zero(frame[offset : offset+bytesPerSample])
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// Encode returns the pixel data of the frames, padded to an even length
+func (f *Frames) Encode() []byte {
+	res := make([]byte, 0)
+	for _, frame := range f.Data {
+		res = append(res, frame...)
+	}
+	if len(res)%2 != 0 {
+		res = append(res, 0)
+	}
+	return res
+}
+
+// BlankRegions returns the copy of the dataset with the regions of its frames blanked, re-encoded in the transfer
+// syntax of the image, and BurnedInAnnotation set to NO
+func BlankRegions(ds go2com.Dataset, transferSyntaxUID string, regions []image.Rectangle) (go2com.Dataset, error) {
+	frames, err := DecodeFrames(ds, transferSyntaxUID)
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	frames.Blank(regions...)
+
+	res := go2com.Dataset{Elements: make([]*go2com.Element, 0, len(ds.Elements))}
+	for _, elem := range ds.Elements {
+		if elem != nil {
+			res.Elements = append(res.Elements, elem)
+		}
+	}
+	pixelData := findElement(ds, tag.PixelData)
+	setElements(&res, []*go2com.Element{
+		replaceValue(pixelData, frames.Encode()),
+		go2com.NewElement(tag.BurnedInAnnotation, "NO"),
+	})
+	return res, nil
+}
+
+// BlankBurnedInAnnotation blanks the regions of the first filter matching the image, and returns whether a filter
+// matched
+func BlankBurnedInAnnotation(ds go2com.Dataset, transferSyntaxUID string, filters []PixelFilter) (go2com.Dataset, bool, error) {
+	for _, filter := range filters {
+		if !filter.Match(ds) {
+			continue
+		}
+		res, err := BlankRegions(ds, transferSyntaxUID, filter.Regions)
+		if err != nil {
+			return go2com.Dataset{}, false, err
+		}
+		return res, true, nil
+	}
+	return ds, false, nil
+}
+
+// WithPixelFilters blanks the burned-in annotations of the images de-identified by DeidentifyFile with the filters
+func WithPixelFilters(filters ...PixelFilter) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.pixelFilters = append(d.pixelFilters, filters...)
+	}
+}
+
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+func matchString(pattern string, ds go2com.Dataset, t tag.DicomTag) bool {
+	return pattern == "" || strings.EqualFold(strings.TrimSpace(pattern), datasetString(ds, t))
+}
+
+func datasetString(ds go2com.Dataset, t tag.DicomTag) string {
+	elem := findElement(ds, t)
+	if elem == nil {
+		return ""
+	}
+	return strings.TrimSpace(stringValue(elem))
+}
+
+func intValue(ds go2com.Dataset, t tag.DicomTag, defaultValue int) int {
+	elem := findElement(ds, t)
+	if elem == nil {
+		return defaultValue
+	}
+	switch v := elem.Value.RawValue.(type) {
+	case int:
+		return v
+	case []int:
+		if len(v) > 0 {
+			return v[0]
+		}
+	}
+	return defaultValue
+}
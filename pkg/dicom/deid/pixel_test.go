@@ -0,0 +1,86 @@
+package deid
+
+import (
+	"image"
+	"testing"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+func image8(rows, columns, samplesPerPixel, planarConfiguration, frames int) go2com.Dataset {
+	data := make([]byte, rows*columns*samplesPerPixel*frames)
+	for i := range data {
+		data[i] = 0xFF
+	}
+	return go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.Manufacturer, "ACME"),
+		go2com.NewElement(tag.ManufacturerModelName, "Scanner 3000"),
+		go2com.NewElement(tag.SamplesPerPixel, samplesPerPixel),
+		go2com.NewElement(tag.PlanarConfiguration, planarConfiguration),
+		go2com.NewElement(tag.NumberOfFrames, frames),
+		go2com.NewElement(tag.Rows, rows),
+		go2com.NewElement(tag.Columns, columns),
+		go2com.NewElement(tag.BitsAllocated, 8),
+		go2com.NewElement(tag.BurnedInAnnotation, "YES"),
+		go2com.NewElement(tag.PixelData, data),
+	}}
+}
+
+func TestBlankRegions(t *testing.T) {
+	assert := assert.New(t)
+	ds := image8(3, 4, 1, 0, 2)
+	res, err := BlankRegions(ds, uid.ExplicitVRLittleEndian, []image.Rectangle{image.Rect(1, 1, 3, 2), image.Rect(3, 2, 10, 10)})
+	assert.NoError(err)
+	frame := []byte{
+		0xFF, 0xFF, 0xFF, 0xFF,
+		0xFF, 0x00, 0x00, 0xFF,
+		0xFF, 0xFF, 0xFF, 0x00,
+	}
+	assert.Equal(append(frame, frame...), value(res, tag.PixelData))
+	assert.Equal("NO", value(res, tag.BurnedInAnnotation))
+	assert.Equal("YES", value(ds, tag.BurnedInAnnotation))
+	assert.NotContains(value(ds, tag.PixelData), byte(0))
+
+	// Color by plane
+	res, err = BlankRegions(image8(2, 2, 3, 1, 1), uid.ImplicitVRLittleEndian, []image.Rectangle{image.Rect(0, 0, 1, 1)})
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0xFF, 0xFF, 0xFF, 0, 0xFF, 0xFF, 0xFF, 0, 0xFF, 0xFF, 0xFF}, value(res, tag.PixelData))
+
+	for _, transferSyntaxUID := range []string{
+		uid.JPEGBaselineProcess1,
+		uid.DeflatedExplicitVRLittleEndian,
+		uid.PrivateGELittleEndianImplicitWithBigEndianPixelData,
+	} {
+		_, err = BlankRegions(ds, transferSyntaxUID, nil)
+		assert.Error(err, transferSyntaxUID)
+	}
+}
+
+func TestBlankBurnedInAnnotation(t *testing.T) {
+	assert := assert.New(t)
+	ds := image8(3, 4, 1, 0, 1)
+	assert.True(HasBurnedInAnnotation(ds))
+	filters := []PixelFilter{
+		{Manufacturer: "ACME", Rows: 512, Regions: []image.Rectangle{image.Rect(0, 0, 4, 3)}},
+		{Manufacturer: "acme", ModelName: "Scanner 3000", Columns: 4, Regions: []image.Rectangle{image.Rect(0, 0, 4, 1)}},
+	}
+	res, ok, err := BlankBurnedInAnnotation(ds, uid.ExplicitVRLittleEndian, filters)
+	assert.NoError(err)
+	assert.True(ok)
+	assert.Equal([]byte{0, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}, value(res, tag.PixelData))
+	assert.False(HasBurnedInAnnotation(res))
+
+	_, ok, err = BlankBurnedInAnnotation(ds, uid.ExplicitVRLittleEndian, filters[:1])
+	assert.NoError(err)
+	assert.False(ok)
+
+	// DeidentifyFile blanks the images matching the filters
+	meta := go2com.NewFileMeta(uid.SecondaryCaptureImageStorage, "1.2.3", uid.ExplicitVRLittleEndian)
+	_, res, err = NewDeidentifier(WithPixelFilters(filters...)).DeidentifyFile(meta, ds)
+	assert.NoError(err)
+	assert.Equal([]byte{0, 0, 0, 0}, value(res, tag.PixelData).([]byte)[:4])
+	assert.Equal("NO", value(res, tag.BurnedInAnnotation))
+}
@@ -0,0 +1,11 @@
+package deid
+
+//go:generate go run ../../../cmd/dicomgen -part15 $DICOM_STANDARD/part15.xml -part6 $DICOM_STANDARD/part06.xml -profile profile_definitions.go
+
+// rule is the row of an attribute in the Basic Profile table: the action of the Basic Profile, the options retaining
+// the attribute and whether the Clean Descriptors option cleans it
+type rule struct {
+	basic  string
+	retain option
+	clean  bool
+}
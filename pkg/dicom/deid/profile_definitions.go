@@ -0,0 +1,278 @@
+package deid
+
+import "github.com/okieraised/go2com/pkg/dicom/tag"
+
+// basicProfile is the Application Level Confidentiality Profile Attributes table of PS3.15 Annex E. Curves, overlay
+// comments and overlay data are handled by group in actionOf
+var basicProfile = map[tag.DicomTag]rule{
+	tag.AccessionNumber:                                   {basic: "Z"},
+	tag.AcquisitionComments:                               {basic: "X", clean: true},
+	tag.AcquisitionContextSequence:                        {basic: "X"},
+	tag.AcquisitionDate:                                   {basic: "X/Z", retain: retainLongitudinal},
+	tag.AcquisitionDateTime:                               {basic: "X/D", retain: retainLongitudinal},
+	tag.AcquisitionDeviceProcessingDescription:            {basic: "X/D", retain: retainDeviceIdentity, clean: true},
+	tag.AcquisitionProtocolDescription:                    {basic: "X", clean: true},
+	tag.AcquisitionTime:                                   {basic: "X/Z", retain: retainLongitudinal},
+	tag.ActualHumanPerformersSequence:                     {basic: "X"},
+	tag.AdditionalPatientHistory:                          {basic: "X", clean: true},
+	tag.AdmissionID:                                       {basic: "X"},
+	tag.AdmittingDate:                                     {basic: "X", retain: retainLongitudinal},
+	tag.AdmittingDiagnosesCodeSequence:                    {basic: "X", clean: true},
+	tag.AdmittingDiagnosesDescription:                     {basic: "X", clean: true},
+	tag.AdmittingTime:                                     {basic: "X", retain: retainLongitudinal},
+	tag.AffectedSOPInstanceUID:                            {basic: "X"},
+	tag.Allergies:                                         {basic: "X", clean: true},
+	tag.AuthorObserverSequence:                            {basic: "X"},
+	tag.BranchOfService:                                   {basic: "X"},
+	tag.CassetteID:                                        {basic: "X", retain: retainDeviceIdentity},
+	tag.CommentsOnThePerformedProcedureStep:               {basic: "X", clean: true},
+	tag.ConcatenationUID:                                  {basic: "U"},
+	tag.ConfidentialityConstraintOnPatientDataDescription: {basic: "X", clean: true},
+	tag.ConsultingPhysicianIdentificationSequence:         {basic: "X"},
+	tag.ConsultingPhysicianName:                           {basic: "X"},
+	tag.ContentCreatorIdentificationCodeSequence:          {basic: "X"},
+	tag.ContentCreatorName:                                {basic: "Z"},
+	tag.ContentDate:                                       {basic: "Z/D", retain: retainLongitudinal},
+	tag.ContentSequence:                                   {basic: "X"},
+	tag.ContentTime:                                       {basic: "Z/D", retain: retainLongitudinal},
+	tag.ContextGroupExtensionCreatorUID:                   {basic: "U"},
+	tag.ContrastBolusAgent:                                {basic: "Z/D", clean: true},
+	tag.ContributionDescription:                           {basic: "X", clean: true},
+	tag.CountryOfResidence:                                {basic: "X"},
+	tag.CreatorVersionUID:                                 {basic: "U"},
+	tag.CurrentPatientLocation:                            {basic: "X"},
+	tag.CurveDate:                                         {basic: "X", retain: retainLongitudinal},
+	tag.CurveTime:                                         {basic: "X", retain: retainLongitudinal},
+	tag.CustodialOrganizationSequence:                     {basic: "X"},
+	tag.DataSetTrailingPadding:                            {basic: "X"},
+	tag.DerivationDescription:                             {basic: "X", clean: true},
+	tag.DetectorID:                                        {basic: "X/D", retain: retainDeviceIdentity},
+	tag.DeviceDescription:                                 {basic: "X", retain: retainDeviceIdentity},
+	tag.DeviceSerialNumber:                                {basic: "X/Z/D", retain: retainDeviceIdentity},
+	tag.DeviceUID:                                         {basic: "U", retain: retainDeviceIdentity},
+	tag.DigitalSignatureUID:                               {basic: "X"},
+	tag.DigitalSignaturesSequence:                         {basic: "X"},
+	tag.DimensionOrganizationUID:                          {basic: "U"},
+	tag.DischargeDiagnosisDescription:                     {basic: "X", clean: true},
+	tag.DistributionAddress:                               {basic: "X"},
+	tag.DistributionName:                                  {basic: "X"},
+	tag.DoseReferenceUID:                                  {basic: "U"},
+	tag.EthnicGroup:                                       {basic: "X", retain: retainPatientCharacteristics},
+	tag.FailedSOPInstanceUIDList:                          {basic: "U"},
+	tag.FiducialUID:                                       {basic: "U"},
+	tag.FillerOrderNumberImagingServiceRequest:            {basic: "Z"},
+	tag.FrameComments:                                     {basic: "X", clean: true},
+	tag.FrameOfReferenceUID:                               {basic: "U"},
+	tag.GantryID:                                          {basic: "X", retain: retainDeviceIdentity},
+	tag.GeneratorID:                                       {basic: "X", retain: retainDeviceIdentity},
+	tag.GraphicAnnotationSequence:                         {basic: "D"},
+	tag.HumanPerformerName:                                {basic: "X"},
+	tag.HumanPerformerOrganization:                        {basic: "X"},
+	tag.IconImageSequence:                                 {basic: "X"},
+	tag.IdentifyingComments:                               {basic: "X", clean: true},
+	tag.ImageComments:                                     {basic: "X", clean: true},
+	tag.ImagePresentationComments:                         {basic: "X", clean: true},
+	tag.ImagingServiceRequestComments:                     {basic: "X", clean: true},
+	tag.Impressions:                                       {basic: "X", clean: true},
+	tag.InstanceCoercionDateTime:                          {basic: "X", retain: retainLongitudinal},
+	tag.InstanceCreationDate:                              {basic: "X/D", retain: retainLongitudinal},
+	tag.InstanceCreationTime:                              {basic: "X/Z/D", retain: retainLongitudinal},
+	tag.InstanceCreatorUID:                                {basic: "U"},
+	tag.InstitutionAddress:                                {basic: "X"},
+	tag.InstitutionCodeSequence:                           {basic: "X/Z/D"},
+	tag.InstitutionName:                                   {basic: "X/Z/D"},
+	tag.InstitutionalDepartmentName:                       {basic: "X"},
+	tag.InsurancePlanIdentification:                       {basic: "X"},
+	tag.IntendedRecipientsOfResultsIdentificationSequence: {basic: "X"},
+	tag.InterpretationApproverSequence:                    {basic: "X"},
+	tag.InterpretationAuthor:                              {basic: "X"},
+	tag.InterpretationDiagnosisDescription:                {basic: "X", clean: true},
+	tag.InterpretationID:                                  {basic: "X"},
+	tag.InterpretationIDIssuer:                            {basic: "X"},
+	tag.InterpretationRecorder:                            {basic: "X"},
+	tag.InterpretationText:                                {basic: "X", clean: true},
+	tag.InterpretationTranscriber:                         {basic: "X"},
+	tag.IrradiationEventUID:                               {basic: "U"},
+	tag.IssuerOfAccessionNumberSequence:                   {basic: "X"},
+	tag.IssuerOfAdmissionID:                               {basic: "X"},
+	tag.IssuerOfAdmissionIDSequence:                       {basic: "X"},
+	tag.IssuerOfPatientID:                                 {basic: "X"},
+	tag.IssuerOfPatientIDQualifiersSequence:               {basic: "X"},
+	tag.IssuerOfServiceEpisodeID:                          {basic: "X"},
+	tag.IssuerOfServiceEpisodeIDSequence:                  {basic: "X"},
+	tag.LargePaletteColorLookupTableUID:                   {basic: "U"},
+	tag.LastMenstrualDate:                                 {basic: "X", retain: retainLongitudinal},
+	tag.MAC:                                               {basic: "X"},
+	tag.MediaStorageSOPInstanceUID:                        {basic: "U"},
+	tag.MedicalAlerts:                                     {basic: "X", clean: true},
+	tag.MedicalRecordLocator:                              {basic: "X"},
+	tag.MilitaryRank:                                      {basic: "X"},
+	tag.ModifiedAttributesSequence:                        {basic: "X"},
+	tag.ModifiedImageDescription:                          {basic: "X", clean: true},
+	tag.ModifyingDeviceID:                                 {basic: "X", retain: retainDeviceIdentity},
+	tag.MostRecentTreatmentDate:                           {basic: "X/D", retain: retainLongitudinal},
+	tag.NameOfPhysiciansReadingStudy:                      {basic: "X"},
+	tag.NamesOfIntendedRecipientsOfResults:                {basic: "X"},
+	tag.ObservationUID:                                    {basic: "U"},
+	tag.Occupation:                                        {basic: "X", clean: true},
+	tag.OperatorIdentificationSequence:                    {basic: "X"},
+	tag.OperatorsName:                                     {basic: "X/Z/D"},
+	tag.OrderCallbackPhoneNumber:                          {basic: "X"},
+	tag.OrderEnteredBy:                                    {basic: "X"},
+	tag.OrderEntererLocation:                              {basic: "X"},
+	tag.OrderFillerIdentifierSequence:                     {basic: "X"},
+	tag.OrderPlacerIdentifierSequence:                     {basic: "X"},
+	tag.OriginalAttributesSequence:                        {basic: "X"},
+	tag.OtherPatientIDs:                                   {basic: "X"},
+	tag.OtherPatientIDsSequence:                           {basic: "X"},
+	tag.OtherPatientNames:                                 {basic: "X"},
+	tag.OverlayDate:                                       {basic: "X", retain: retainLongitudinal},
+	tag.OverlayTime:                                       {basic: "X", retain: retainLongitudinal},
+	tag.ParticipantSequence:                               {basic: "X"},
+	tag.PatientAddress:                                    {basic: "X"},
+	tag.PatientAge:                                        {basic: "X", retain: retainPatientCharacteristics},
+	tag.PatientAlternativeCalendar:                        {basic: "X"},
+	tag.PatientBirthDate:                                  {basic: "Z"},
+	tag.PatientBirthDateInAlternativeCalendar:             {basic: "X"},
+	tag.PatientBirthName:                                  {basic: "X"},
+	tag.PatientBirthTime:                                  {basic: "X"},
+	tag.PatientComments:                                   {basic: "X", clean: true},
+	tag.PatientDeathDateInAlternativeCalendar:             {basic: "X"},
+	tag.PatientID:                                         {basic: "Z"},
+	tag.PatientInsurancePlanCodeSequence:                  {basic: "X"},
+	tag.PatientMotherBirthName:                            {basic: "X"},
+	tag.PatientName:                                       {basic: "Z"},
+	tag.PatientPrimaryLanguageCodeSequence:                {basic: "X"},
+	tag.PatientPrimaryLanguageModifierCodeSequence:        {basic: "X"},
+	tag.PatientReligiousPreference:                        {basic: "X"},
+	tag.PatientSex:                                        {basic: "Z", retain: retainPatientCharacteristics},
+	tag.PatientSexNeutered:                                {basic: "X/Z", retain: retainPatientCharacteristics},
+	tag.PatientSize:                                       {basic: "X", retain: retainPatientCharacteristics},
+	tag.PatientState:                                      {basic: "X", clean: true},
+	tag.PatientTelecomInformation:                         {basic: "X"},
+	tag.PatientTelephoneNumbers:                           {basic: "X"},
+	tag.PatientTransportArrangements:                      {basic: "X"},
+	tag.PatientWeight:                                     {basic: "X", retain: retainPatientCharacteristics},
+	tag.PerformedLocation:                                 {basic: "X"},
+	tag.PerformedProcedureStepDescription:                 {basic: "X", clean: true},
+	tag.PerformedProcedureStepEndDate:                     {basic: "X", retain: retainLongitudinal},
+	tag.PerformedProcedureStepEndTime:                     {basic: "X", retain: retainLongitudinal},
+	tag.PerformedProcedureStepID:                          {basic: "X"},
+	tag.PerformedProcedureStepStartDate:                   {basic: "X", retain: retainLongitudinal},
+	tag.PerformedProcedureStepStartTime:                   {basic: "X", retain: retainLongitudinal},
+	tag.PerformedStationAETitle:                           {basic: "X"},
+	tag.PerformedStationGeographicLocationCodeSequence:    {basic: "X"},
+	tag.PerformedStationName:                              {basic: "X"},
+	tag.PerformedStationNameCodeSequence:                  {basic: "X"},
+	tag.PerformingPhysicianIdentificationSequence:         {basic: "X"},
+	tag.PerformingPhysicianName:                           {basic: "X"},
+	tag.PersonAddress:                                     {basic: "X"},
+	tag.PersonIdentificationCodeSequence:                  {basic: "D"},
+	tag.PersonName:                                        {basic: "D"},
+	tag.PersonTelecomInformation:                          {basic: "X"},
+	tag.PersonTelephoneNumbers:                            {basic: "X"},
+	tag.PhysicianApprovingInterpretation:                  {basic: "X"},
+	tag.PhysiciansOfRecord:                                {basic: "X"},
+	tag.PhysiciansOfRecordIdentificationSequence:          {basic: "X"},
+	tag.PhysiciansReadingStudyIdentificationSequence:      {basic: "X"},
+	tag.PlacerOrderNumberImagingServiceRequest:            {basic: "Z"},
+	tag.PlateID:                                           {basic: "X", retain: retainDeviceIdentity},
+	tag.PreMedication:                                     {basic: "X", clean: true},
+	tag.PregnancyStatus:                                   {basic: "X", retain: retainPatientCharacteristics},
+	tag.ProtocolName:                                      {basic: "X/D", clean: true},
+	tag.ReasonForStudy:                                    {basic: "X", clean: true},
+	tag.ReasonForTheImagingServiceRequest:                 {basic: "X", clean: true},
+	tag.ReasonForTheRequestedProcedure:                    {basic: "X", clean: true},
+	tag.ReferencedDigitalSignatureSequence:                {basic: "X"},
+	tag.ReferencedFrameOfReferenceUID:                     {basic: "U"},
+	tag.ReferencedGeneralPurposeScheduledProcedureStepTransactionUID: {basic: "U"},
+	tag.ReferencedImageSequence:                                      {basic: "X/Z/U*"},
+	tag.ReferencedPatientAliasSequence:                               {basic: "X"},
+	tag.ReferencedPatientPhotoSequence:                               {basic: "X"},
+	tag.ReferencedPatientSequence:                                    {basic: "X"},
+	tag.ReferencedPerformedProcedureStepSequence:                     {basic: "X/Z/D"},
+	tag.ReferencedSOPInstanceMACSequence:                             {basic: "X"},
+	tag.ReferencedSOPInstanceUID:                                     {basic: "U"},
+	tag.ReferencedSOPInstanceUIDInFile:                               {basic: "U"},
+	tag.ReferencedStudySequence:                                      {basic: "X/Z"},
+	tag.ReferringPhysicianAddress:                                    {basic: "X"},
+	tag.ReferringPhysicianIdentificationSequence:                     {basic: "X"},
+	tag.ReferringPhysicianName:                                       {basic: "Z"},
+	tag.ReferringPhysicianTelephoneNumbers:                           {basic: "X"},
+	tag.RegionOfResidence:                                            {basic: "X"},
+	tag.RelatedFrameOfReferenceUID:                                   {basic: "U"},
+	tag.RequestAttributesSequence:                                    {basic: "X"},
+	tag.RequestedContrastAgent:                                       {basic: "X", clean: true},
+	tag.RequestedProcedureComments:                                   {basic: "X", clean: true},
+	tag.RequestedProcedureDescription:                                {basic: "X/Z", clean: true},
+	tag.RequestedProcedureID:                                         {basic: "X"},
+	tag.RequestedProcedureLocation:                                   {basic: "X"},
+	tag.RequestedSOPInstanceUID:                                      {basic: "U"},
+	tag.RequestingPhysician:                                          {basic: "X"},
+	tag.RequestingService:                                            {basic: "X"},
+	tag.RequestingServiceCodeSequence:                                {basic: "X"},
+	tag.ResponsibleOrganization:                                      {basic: "X"},
+	tag.ResponsiblePerson:                                            {basic: "X"},
+	tag.ResultsComments:                                              {basic: "X", clean: true},
+	tag.ResultsDistributionListSequence:                              {basic: "X"},
+	tag.ResultsID:                                                    {basic: "X"},
+	tag.ResultsIDIssuer:                                              {basic: "X"},
+	tag.ReviewerName:                                                 {basic: "X/Z"},
+	tag.SOPInstanceUID:                                               {basic: "U"},
+	tag.ScheduledHumanPerformersSequence:                             {basic: "X"},
+	tag.ScheduledPatientInstitutionResidence:                         {basic: "X"},
+	tag.ScheduledPerformingPhysicianIdentificationSequence:           {basic: "X"},
+	tag.ScheduledPerformingPhysicianName:                             {basic: "X"},
+	tag.ScheduledProcedureStepDescription:                            {basic: "X", clean: true},
+	tag.ScheduledProcedureStepEndDate:                                {basic: "X", retain: retainLongitudinal},
+	tag.ScheduledProcedureStepEndTime:                                {basic: "X", retain: retainLongitudinal},
+	tag.ScheduledProcedureStepID:                                     {basic: "X"},
+	tag.ScheduledProcedureStepLocation:                               {basic: "X"},
+	tag.ScheduledProcedureStepStartDate:                              {basic: "X", retain: retainLongitudinal},
+	tag.ScheduledProcedureStepStartTime:                              {basic: "X", retain: retainLongitudinal},
+	tag.ScheduledStationAETitle:                                      {basic: "X"},
+	tag.ScheduledStationGeographicLocationCodeSequence:               {basic: "X"},
+	tag.ScheduledStationName:                                         {basic: "X"},
+	tag.ScheduledStationNameCodeSequence:                             {basic: "X"},
+	tag.ScheduledStudyLocation:                                       {basic: "X"},
+	tag.ScheduledStudyLocationAETitle:                                {basic: "X"},
+	tag.SeriesDate:                                                   {basic: "X", retain: retainLongitudinal},
+	tag.SeriesDescription:                                            {basic: "X", clean: true},
+	tag.SeriesInstanceUID:                                            {basic: "U"},
+	tag.SeriesTime:                                                   {basic: "X", retain: retainLongitudinal},
+	tag.ServiceEpisodeDescription:                                    {basic: "X", clean: true},
+	tag.ServiceEpisodeID:                                             {basic: "X"},
+	tag.SmokingStatus:                                                {basic: "X", retain: retainPatientCharacteristics},
+	tag.SourceImageSequence:                                          {basic: "X/Z/U*"},
+	tag.SpecialNeeds:                                                 {basic: "X", clean: true},
+	tag.SpecimenUID:                                                  {basic: "U"},
+	tag.StationName:                                                  {basic: "X/Z/D", retain: retainDeviceIdentity},
+	tag.StorageMediaFileSetID:                                        {basic: "X"},
+	tag.StorageMediaFileSetUID:                                       {basic: "U"},
+	tag.StudyComments:                                                {basic: "X", clean: true},
+	tag.StudyDate:                                                    {basic: "Z", retain: retainLongitudinal},
+	tag.StudyDescription:                                             {basic: "X", clean: true},
+	tag.StudyID:                                                      {basic: "Z"},
+	tag.StudyIDIssuer:                                                {basic: "X"},
+	tag.StudyInstanceUID:                                             {basic: "U"},
+	tag.StudyTime:                                                    {basic: "Z", retain: retainLongitudinal},
+	tag.SynchronizationFrameOfReferenceUID:                           {basic: "U"},
+	tag.TargetUID:                                                    {basic: "U"},
+	tag.TemplateExtensionCreatorUID:                                  {basic: "U"},
+	tag.TemplateExtensionOrganizationUID:                             {basic: "U"},
+	tag.TextComments:                                                 {basic: "X", clean: true},
+	tag.TextString:                                                   {basic: "X", clean: true},
+	tag.TextValue:                                                    {basic: "X"},
+	tag.TimezoneOffsetFromUTC:                                        {basic: "X", retain: retainLongitudinal},
+	tag.TopicAuthor:                                                  {basic: "X"},
+	tag.TopicKeywords:                                                {basic: "X", clean: true},
+	tag.TopicSubject:                                                 {basic: "X", clean: true},
+	tag.TopicTitle:                                                   {basic: "X", clean: true},
+	tag.TransactionUID:                                               {basic: "U"},
+	tag.UID:                                                          {basic: "U"},
+	tag.VerifyingObserverIdentificationCodeSequence:                  {basic: "Z"},
+	tag.VerifyingObserverName:                                        {basic: "D"},
+	tag.VerifyingObserverSequence:                                    {basic: "D"},
+	tag.VerifyingOrganization:                                        {basic: "X"},
+	tag.VisitComments:                                                {basic: "X", clean: true},
+}
@@ -0,0 +1,241 @@
+package deid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// Kind is the kind of value mapped to a pseudonym
+type Kind string
+
+const (
+	KindUID             Kind = "UID"
+	KindPatientID       Kind = "PatientID"
+	KindAccessionNumber Kind = "AccessionNumber"
+)
+
+// MappingStore records the pseudonyms, so that authorised users can look up the original values
+type MappingStore interface {
+	// Save records the pseudonym of the original value
+	Save(kind Kind, original, pseudonym string) error
+	// Lookup returns the original value of the pseudonym and whether it is known
+	Lookup(kind Kind, pseudonym string) (string, bool, error)
+}
+
+// MemoryStore is a MappingStore in memory, safe for concurrent use
+type MemoryStore struct {
+	mu       sync.RWMutex
+	mappings map[Kind]map[string]string
+}
+
+// NewMemoryStore returns an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{mappings: make(map[Kind]map[string]string)}
+}
+
+// Save records the pseudonym of the original value
+func (s *MemoryStore) Save(kind Kind, original, pseudonym string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mappings[kind] == nil {
+		s.mappings[kind] = make(map[string]string)
+	}
+	s.mappings[kind][pseudonym] = original
+	return nil
+}
+
+// Lookup returns the original value of the pseudonym and whether it is known
+func (s *MemoryStore) Lookup(kind Kind, pseudonym string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	original, ok := s.mappings[kind][pseudonym]
+	return original, ok, nil
+}
+
+// Pseudonymizer derives stable pseudonyms from the values with a secret key, so that the same value maps to the same
+// pseudonym across batches while the original value cannot be recovered without the key or the mapping store
+type Pseudonymizer struct {
+	key          []byte
+	store        MappingStore
+	maxDateShift int
+}
+
+// NewPseudonymizer returns a Pseudonymizer keyed with the secret key
+func NewPseudonymizer(key []byte, options ...func(*Pseudonymizer)) *Pseudonymizer {
+	p := &Pseudonymizer{key: key}
+	for _, opt := range options {
+		opt(p)
+	}
+	return p
+}
+
+// WithMappingStore records the pseudonyms in the store for reversible lookup
+func WithMappingStore(store MappingStore) func(*Pseudonymizer) {
+	return func(p *Pseudonymizer) {
+		p.store = store
+	}
+}
+
+// WithDateShift shifts the dates of a patient back by an offset of 1 to maxDays days derived from the PatientID,
+// keeping the intervals between the dates of the patient. Dates are not shifted by default
+func WithDateShift(maxDays int) func(*Pseudonymizer) {
+	return func(p *Pseudonymizer) {
+		p.maxDateShift = maxDays
+	}
+}
+
+// WithPseudonymizer replaces the UIDs, PatientID and AccessionNumber with the pseudonyms of the Pseudonymizer instead
+// of random UIDs and zero length values, and shifts the dates when date shifting is enabled
+func WithPseudonymizer(p *Pseudonymizer) func(*Deidentifier) {
+	return func(d *Deidentifier) {
+		d.pseudonymizer = p
+	}
+}
+
+// UID returns the pseudonym of the UID, a 2.25 UID derived from the keyed hash of the UID
+func (p *Pseudonymizer) UID(value string) (string, error) {
+	var b [16]byte
+	copy(b[:], p.sum(KindUID, value))
+	// Sets the version and variant bits of a UUID, as required under the 2.25 root by ISO/IEC 9834-8
+	b[6] = b[6]&0x0F | 0x80
+	b[8] = b[8]&0x3F | 0x80
+	return p.save(KindUID, value, uid.FromUUID(b))
+}
+
+// PatientID returns the pseudonym of the PatientID
+func (p *Pseudonymizer) PatientID(id string) (string, error) {
+	return p.save(KindPatientID, id, p.encode(KindPatientID, id))
+}
+
+// AccessionNumber returns the pseudonym of the AccessionNumber, fitting the 16 characters of the SH VR
+func (p *Pseudonymizer) AccessionNumber(accessionNumber string) (string, error) {
+	return p.save(KindAccessionNumber, accessionNumber, p.encode(KindAccessionNumber, accessionNumber))
+}
+
+// Lookup returns the original value of the pseudonym from the mapping store
+func (p *Pseudonymizer) Lookup(kind Kind, pseudonym string) (string, error) {
+	if p.store == nil {
+		return "", fmt.Errorf("deid: no mapping store to look up the pseudonym")
+	}
+	original, ok, err := p.store.Lookup(kind, pseudonym)
+	if err != nil {
+		return "", fmt.Errorf("deid: cannot look up the pseudonym: %v", err)
+	}
+	if !ok {
+		return "", fmt.Errorf("deid: unknown %s pseudonym %q", kind, pseudonym)
+	}
+	return original, nil
+}
+
+// DateOffset returns the number of days, negative, the dates of the patient are shifted by. It returns 0 when date
+// shifting is disabled
+func (p *Pseudonymizer) DateOffset(patientID string) int {
+	if p.maxDateShift <= 0 {
+		return 0
+	}
+	n := binary.BigEndian.Uint64(p.sum("DateOffset", patientID))
+	return -1 - int(n%uint64(p.maxDateShift))
+}
+
+// ShiftDate shifts the date of the DA value, or the date part of the DT value, by the offset of the patient
+func (p *Pseudonymizer) ShiftDate(patientID, value string) (string, error) {
+	if len(value) < 8 {
+		return "", fmt.Errorf("deid: cannot shift date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return "", fmt.Errorf("deid: cannot shift date %q: %v", value, err)
+	}
+	return date.AddDate(0, 0, p.DateOffset(patientID)).Format("20060102") + value[8:], nil
+}
+
+// sum returns the keyed hash of the value, separated by kind
+func (p *Pseudonymizer) sum(kind Kind, value string) []byte {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// encode returns the 16 characters identifier derived from the keyed hash of the value
+func (p *Pseudonymizer) encode(kind Kind, value string) string {
+	return base32.StdEncoding.EncodeToString(p.sum(kind, value)[:10])
+}
+
+func (p *Pseudonymizer) save(kind Kind, original, pseudonym string) (string, error) {
+	if p.store != nil {
+		err := p.store.Save(kind, original, pseudonym)
+		if err != nil {
+			return "", fmt.Errorf("deid: cannot save the %s pseudonym: %v", kind, err)
+		}
+	}
+	return pseudonym, nil
+}
+
+// shiftDates returns true if the dates are shifted instead of being removed
+func (d *Deidentifier) shiftDates() bool {
+	return d.pseudonymizer != nil && d.pseudonymizer.maxDateShift > 0 && d.options&retainLongitudinal == 0
+}
+
+// pseudonymize returns the copy of the element with its pseudonym or shifted date, and whether the element is
+// handled by the Pseudonymizer
+func (d *Deidentifier) pseudonymize(elem *go2com.Element, s *scope) (*go2com.Element, bool, error) {
+	if _, ok := d.actions[elem.Tag]; ok {
+		return nil, false, nil
+	}
+	var pseudonym func(string) (string, error)
+	switch {
+	case elem.Tag == tag.PatientID:
+		pseudonym = d.pseudonymizer.PatientID
+	case elem.Tag == tag.AccessionNumber:
+		pseudonym = d.pseudonymizer.AccessionNumber
+	case d.shiftDates() && basicProfile[elem.Tag].retain&retainLongitudinal != 0:
+		switch elem.ValueRepresentationStr {
+		case vr.Date, vr.DateTime:
+			pseudonym = func(value string) (string, error) {
+				return d.pseudonymizer.ShiftDate(s.patientID, value)
+			}
+		default:
+			// Times are kept as the dates are shifted by whole days
+			return elem, true, nil
+		}
+	default:
+		return nil, false, nil
+	}
+
+	values := stringValues(elem)
+	res := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		mapped, err := pseudonym(value)
+		if err != nil {
+			// Dates that cannot be shifted are removed
+			if elem.ValueRepresentationStr == vr.Date || elem.ValueRepresentationStr == vr.DateTime {
+				return replaceValue(elem, ""), true, nil
+			}
+			return nil, false, err
+		}
+		res = append(res, mapped)
+	}
+	switch len(res) {
+	case 0:
+		return replaceValue(elem, ""), true, nil
+	case 1:
+		return replaceValue(elem, res[0]), true, nil
+	}
+	return replaceValue(elem, res), true, nil
+}
@@ -0,0 +1,74 @@
+package deid
+
+import (
+	"testing"
+
+	"github.com/okieraised/go2com"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPseudonymizer(t *testing.T) {
+	assert := assert.New(t)
+	store := NewMemoryStore()
+	p := NewPseudonymizer([]byte("secret"), WithMappingStore(store), WithDateShift(365))
+
+	uid, err := p.UID("1.2.3")
+	assert.NoError(err)
+	assert.Regexp(`^2\.25\.\d+$`, uid)
+	assert.LessOrEqual(len(uid), 64)
+	again, _ := NewPseudonymizer([]byte("secret")).UID("1.2.3")
+	assert.Equal(uid, again)
+	other, _ := NewPseudonymizer([]byte("other key")).UID("1.2.3")
+	assert.NotEqual(uid, other)
+
+	id, err := p.PatientID("12345")
+	assert.NoError(err)
+	assert.Len(id, 16)
+	accessionNumber, err := p.AccessionNumber("12345")
+	assert.NoError(err)
+	assert.Len(accessionNumber, 16)
+	assert.NotEqual(id, accessionNumber)
+	original, err := p.Lookup(KindPatientID, id)
+	assert.NoError(err)
+	assert.Equal("12345", original)
+	_, err = p.Lookup(KindPatientID, accessionNumber)
+	assert.Error(err)
+
+	offset := p.DateOffset("12345")
+	assert.True(offset <= -1 && offset >= -365)
+	assert.Equal(0, NewPseudonymizer([]byte("secret")).DateOffset("12345"))
+	shifted, err := p.ShiftDate("12345", "20200301")
+	assert.NoError(err)
+	next, err := p.ShiftDate("12345", "20200302120000")
+	assert.NoError(err)
+	assert.Equal("120000", next[8:])
+	assert.Greater(next[:8], shifted)
+	_, err = p.ShiftDate("12345", "2020")
+	assert.Error(err)
+}
+
+func TestDeidentify_Pseudonymizer(t *testing.T) {
+	assert := assert.New(t)
+	key := []byte("secret")
+	first, err := NewDeidentifier(WithPseudonymizer(NewPseudonymizer(key, WithDateShift(30)))).Deidentify(instance("1.2.3.4.1", "1.2.3.4.2"))
+	assert.NoError(err)
+	// A new batch maps to the same pseudonyms
+	second, err := NewDeidentifier(WithPseudonymizer(NewPseudonymizer(key, WithDateShift(30)))).Deidentify(instance("1.2.3.4.2", "1.2.3.4.1"))
+	assert.NoError(err)
+
+	p := NewPseudonymizer(key, WithDateShift(30))
+	id, _ := p.PatientID("12345")
+	assert.Equal(id, value(first, tag.PatientID))
+	assert.Equal(value(first, tag.PatientID), value(second, tag.PatientID))
+	assert.Equal(value(first, tag.AccessionNumber), value(second, tag.AccessionNumber))
+	assert.Equal(value(first, tag.StudyInstanceUID), value(second, tag.StudyInstanceUID))
+	refs := value(second, tag.ReferencedImageSequence).([]*go2com.Element)
+	assert.Equal(value(first, tag.SOPInstanceUID), refs[1].Value.RawValue)
+
+	shifted, _ := p.ShiftDate("12345", "20200102")
+	assert.Equal(shifted, value(first, tag.StudyDate))
+	assert.Equal("", value(first, tag.PatientBirthDate))
+	assert.Equal("MODIFIED", value(first, tag.LongitudinalTemporalInformationModified))
+}
@@ -0,0 +1,325 @@
+package dicomdir
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+// Record is a directory record of a DICOMDIR
+type Record struct {
+	// Type is the Directory Record Type, e.g.: PATIENT, STUDY, SERIES or IMAGE
+	Type string
+	// Offset is the offset of the record item from the start of the file
+	Offset uint32
+	// Dataset holds the elements of the record
+	Dataset go2com.Dataset
+	// FileID holds the components of the Referenced File ID, empty if the record references no file
+	FileID []string
+	// Path is the path on disk of the referenced file, resolved from the directory of the DICOMDIR
+	Path string
+	// Children holds the records of the referenced lower level directory entity
+	Children []*Record
+}
+
+// DICOMDIR is a Media Storage Directory with its directory records arranged in a tree
+type DICOMDIR struct {
+	Metadata go2com.Dataset
+	// Dataset holds the elements of the file-set, without the Directory Record Sequence
+	Dataset   go2com.Dataset
+	FileSetID string
+	// Records holds the records of the root directory entity
+	Records []*Record
+}
+
+// Read parses the DICOMDIR file and resolves the referenced files relative to its directory
+func Read(path string) (*DICOMDIR, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f, filepath.Dir(path))
+}
+
+// Parse parses a DICOMDIR and resolves the referenced files relative to dir. The directory records are linked
+// with the OffsetOfTheNextDirectoryRecord and OffsetOfReferencedLowerLevelDirectoryEntity offsets, starting from
+// the OffsetOfTheFirstDirectoryRecordOfTheRootDirectoryEntity. Inactive records are left out
+func Parse(r io.Reader, dir string) (*DICOMDIR, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{data: data, br: bytes.NewReader(data)}
+	p.buf = bufio.NewReaderSize(p.br, len(data)+16)
+	rd := go2com.NewDICOMReader(p.buf, go2com.WithSkipDataset(true), go2com.WithSetFileSize(int64(len(data))))
+	err = rd.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("dicomdir: %v", err)
+	}
+	if rd.IsImplicit() || rd.ByteOrder() != binary.LittleEndian {
+		return nil, fmt.Errorf("dicomdir: DICOMDIR must be encoded in Explicit VR Little Endian")
+	}
+	p.readElement = func() (*go2com.Element, error) {
+		return go2com.ReadElement(rd, false, binary.LittleEndian)
+	}
+
+	d := &DICOMDIR{Metadata: rd.GetMetadata()}
+	records := make(map[uint32]*Record)
+	for {
+		header, err := p.buf.Peek(4)
+		if err != nil || len(header) < 4 {
+			break
+		}
+		if readTag(header) == tag.DirectoryRecordSequence {
+			err = p.readRecords(records)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		elem, err := p.readElement()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("dicomdir: %v", err)
+		}
+		if elem != nil {
+			d.Dataset.Elements = append(d.Dataset.Elements, elem)
+		}
+	}
+	d.FileSetID = stringValue(d.Dataset, tag.FileSetID)
+
+	first := uintValue(d.Dataset, tag.OffsetOfTheFirstDirectoryRecordOfTheRootDirectoryEntity)
+	d.Records, err = linkRecords(records, first, make(map[uint32]bool))
+	if err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		if len(rec.FileID) > 0 {
+			rec.Path = resolvePath(dir, rec.FileID)
+		}
+	}
+	return d, nil
+}
+
+// Walk calls fn for each record, in depth-first order, with its parent records. Walk stops at the first error
+// returned by fn
+func (d *DICOMDIR) Walk(fn func(rec *Record, parents []*Record) error) error {
+	return walk(d.Records, nil, fn)
+}
+
+// Files returns the paths of the files referenced by the records, in depth-first order
+func (d *DICOMDIR) Files() []string {
+	paths := make([]string, 0)
+	_ = d.Walk(func(rec *Record, parents []*Record) error {
+		if rec.Path != "" {
+			paths = append(paths, rec.Path)
+		}
+		return nil
+	})
+	return paths
+}
+
+func walk(records, parents []*Record, fn func(rec *Record, parents []*Record) error) error {
+	for _, rec := range records {
+		err := fn(rec, parents)
+		if err != nil {
+			return err
+		}
+		err = walk(rec.Children, append(parents[:len(parents):len(parents)], rec), fn)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parser reads the DICOMDIR from memory, so that the offsets of the records can be computed
+type parser struct {
+	data []byte
+	br   *bytes.Reader
+	buf  *bufio.Reader
+	// readElement reads the next element with the reader of the file
+	readElement func() (*go2com.Element, error)
+}
+
+// pos returns the offset of the next byte to read from the start of the file
+func (p *parser) pos() int {
+	return len(p.data) - p.br.Len() - p.buf.Buffered()
+}
+
+// readRecords reads the items of the Directory Record Sequence by offset
+func (p *parser) readRecords(records map[uint32]*Record) error {
+	header := make([]byte, 12)
+	_, err := io.ReadFull(p.buf, header)
+	if err != nil {
+		return fmt.Errorf("dicomdir: truncated directory record sequence: %v", err)
+	}
+	if string(header[4:6]) != "SQ" {
+		return fmt.Errorf("dicomdir: directory record sequence has VR %q", header[4:6])
+	}
+	length := binary.LittleEndian.Uint32(header[8:])
+	end := p.pos() + int(length)
+
+	for length == go2com.VLUndefinedLength || p.pos() < end {
+		offset := p.pos()
+		item := make([]byte, 8)
+		_, err = io.ReadFull(p.buf, item)
+		if err != nil {
+			return fmt.Errorf("dicomdir: truncated directory record at offset %d: %v", offset, err)
+		}
+		t := readTag(item)
+		if t == tag.SequenceDelimitationItem {
+			break
+		}
+		if t != tag.Item {
+			return fmt.Errorf("dicomdir: unexpected tag %s at offset %d in directory record sequence", t, offset)
+		}
+		itemLength := binary.LittleEndian.Uint32(item[4:])
+		itemEnd := p.pos() + int(itemLength)
+
+		rec := &Record{Offset: uint32(offset)}
+		for itemLength == go2com.VLUndefinedLength || p.pos() < itemEnd {
+			header, err := p.buf.Peek(4)
+			if err != nil {
+				return fmt.Errorf("dicomdir: truncated directory record at offset %d: %v", offset, err)
+			}
+			if readTag(header) == tag.ItemDelimitationItem {
+				_, _ = p.buf.Discard(8)
+				break
+			}
+			elem, err := p.readElement()
+			if err != nil {
+				return fmt.Errorf("dicomdir: directory record at offset %d: %v", offset, err)
+			}
+			if elem != nil {
+				rec.Dataset.Elements = append(rec.Dataset.Elements, elem)
+			}
+		}
+		rec.Type = stringValue(rec.Dataset, tag.DirectoryRecordType)
+		rec.FileID = stringValues(rec.Dataset, tag.ReferencedFileID)
+		records[rec.Offset] = rec
+	}
+	return nil
+}
+
+// linkRecords returns the active records of the directory entity starting at the offset, with their lower level
+// directory entities
+func linkRecords(records map[uint32]*Record, offset uint32, visited map[uint32]bool) ([]*Record, error) {
+	res := make([]*Record, 0)
+	for offset != 0 {
+		rec, ok := records[offset]
+		if !ok {
+			return nil, fmt.Errorf("dicomdir: no directory record at offset %d", offset)
+		}
+		if visited[offset] {
+			return nil, fmt.Errorf("dicomdir: directory record at offset %d is referenced twice", offset)
+		}
+		visited[offset] = true
+
+		// Records with a RecordInUseFlag of 0x0000 are inactive
+		inUse := true
+		if elem, err := rec.Dataset.FindElementByTag(tag.RecordInUseFlag); err == nil && elem.Value.RawValue != nil {
+			inUse = uintValue(rec.Dataset, tag.RecordInUseFlag) != 0
+		}
+		if inUse {
+			var err error
+			rec.Children, err = linkRecords(records, uintValue(rec.Dataset, tag.OffsetOfReferencedLowerLevelDirectoryEntity), visited)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, rec)
+		}
+		offset = uintValue(rec.Dataset, tag.OffsetOfTheNextDirectoryRecord)
+	}
+	return res, nil
+}
+
+// resolvePath returns the path of the file ID in the directory. As the file IDs are upper case, the components
+// are matched case-insensitively when the exact path does not exist
+func resolvePath(dir string, fileID []string) string {
+	path := filepath.Join(append([]string{dir}, fileID...)...)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	current := dir
+	if current == "" {
+		current = "."
+	}
+	for _, component := range fileID {
+		entries, err := os.ReadDir(current)
+		if err != nil {
+			return path
+		}
+		found := false
+		for _, entry := range entries {
+			if strings.EqualFold(entry.Name(), component) {
+				current = filepath.Join(current, entry.Name())
+				found = true
+				break
+			}
+		}
+		if !found {
+			return path
+		}
+	}
+	return current
+}
+
+func readTag(b []byte) tag.DicomTag {
+	return tag.DicomTag{
+		Group:   binary.LittleEndian.Uint16(b),
+		Element: binary.LittleEndian.Uint16(b[2:]),
+	}
+}
+
+func stringValue(ds go2com.Dataset, t tag.DicomTag) string {
+	values := stringValues(ds, t)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func stringValues(ds go2com.Dataset, t tag.DicomTag) []string {
+	elem, err := ds.FindElementByTag(t)
+	if err != nil {
+		return nil
+	}
+	switch v := elem.Value.RawValue.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return strings.Split(v, "\\")
+	case []string:
+		return v
+	}
+	return nil
+}
+
+func uintValue(ds go2com.Dataset, t tag.DicomTag) uint32 {
+	elem, err := ds.FindElementByTag(t)
+	if err != nil {
+		return 0
+	}
+	switch v := elem.Value.RawValue.(type) {
+	case int:
+		return uint32(v)
+	case []int:
+		if len(v) > 0 {
+			return uint32(v[0])
+		}
+	}
+	return 0
+}
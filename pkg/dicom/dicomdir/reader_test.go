@@ -0,0 +1,153 @@
+package dicomdir
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fixtureRecord is a directory record of a test DICOMDIR, linked to the other records by index
+type fixtureRecord struct {
+	recordType string
+	next       int
+	lower      int
+	inactive   bool
+	keys       []*go2com.Element
+}
+
+func element(t tag.DicomTag, valueRepresentation string, value interface{}) *go2com.Element {
+	return &go2com.Element{Tag: t, ValueRepresentationStr: valueRepresentation, Value: go2com.Value{RawValue: value}}
+}
+
+// encodeRecord encodes the elements of the record with the given offsets
+func encodeRecord(t *testing.T, rec fixtureRecord, next, lower uint32) []byte {
+	inUse := 0xFFFF
+	if rec.inactive {
+		inUse = 0
+	}
+	buf := bytes.Buffer{}
+	w := go2com.NewDICOMWriter(&buf)
+	for _, elem := range append([]*go2com.Element{
+		element(tag.OffsetOfTheNextDirectoryRecord, "UL", int(next)),
+		element(tag.RecordInUseFlag, "US", inUse),
+		element(tag.OffsetOfReferencedLowerLevelDirectoryEntity, "UL", int(lower)),
+		element(tag.DirectoryRecordType, "CS", rec.recordType),
+	}, rec.keys...) {
+		assert.NoError(t, w.WriteElement(elem))
+	}
+	return buf.Bytes()
+}
+
+// writeFixture writes a DICOMDIR with the records, the first one being the first record of the root directory
+func writeFixture(t *testing.T, path string, records []fixtureRecord) {
+	buf := bytes.Buffer{}
+	w := go2com.NewDICOMWriter(&buf)
+	assert.NoError(t, w.WriteFileMeta(go2com.NewFileMeta("1.2.840.10008.1.3.10", "1.2.3.4", uid.ExplicitVRLittleEndian)))
+	header := func(first uint32) []*go2com.Element {
+		return []*go2com.Element{
+			element(tag.FileSetID, "CS", "TEST"),
+			element(tag.OffsetOfTheFirstDirectoryRecordOfTheRootDirectoryEntity, "UL", int(first)),
+			element(tag.OffsetOfTheLastDirectoryRecordOfTheRootDirectoryEntity, "UL", 0),
+			element(tag.FileSetConsistencyFlag, "US", 0),
+		}
+	}
+	headerLength := 0
+	for _, elem := range header(0) {
+		b := bytes.Buffer{}
+		assert.NoError(t, go2com.NewDICOMWriter(&b).WriteElement(elem))
+		headerLength += b.Len()
+	}
+
+	// The records have the same length whatever their offsets
+	offsets := make([]uint32, len(records))
+	pos := uint32(buf.Len() + headerLength + 12)
+	for i, rec := range records {
+		offsets[i] = pos
+		pos += uint32(8 + len(encodeRecord(t, rec, 0, 0)))
+	}
+	offsetOf := func(i int) uint32 {
+		if i < 0 {
+			return 0
+		}
+		return offsets[i]
+	}
+
+	for _, elem := range header(offsets[0]) {
+		assert.NoError(t, w.WriteElement(elem))
+	}
+	buf.Write([]byte{0x04, 0x00, 0x20, 0x12, 'S', 'Q', 0, 0, 0xFF, 0xFF, 0xFF, 0xFF})
+	for _, rec := range records {
+		b := encodeRecord(t, rec, offsetOf(rec.next), offsetOf(rec.lower))
+		buf.Write([]byte{0xFE, 0xFF, 0x00, 0xE0})
+		_ = binary.Write(&buf, binary.LittleEndian, uint32(len(b)))
+		buf.Write(b)
+	}
+	buf.Write([]byte{0xFE, 0xFF, 0xDD, 0xE0, 0, 0, 0, 0})
+	assert.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+}
+
+func TestRead(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "dicom", "st1"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "dicom", "st1", "im1"), nil, 0644))
+
+	image := func(fileID []string, sopInstanceUID string) []*go2com.Element {
+		return []*go2com.Element{
+			element(tag.ReferencedFileID, "CS", fileID),
+			element(tag.ReferencedSOPClassUIDInFile, "UI", "1.2.840.10008.5.1.4.1.1.7"),
+			element(tag.ReferencedSOPInstanceUIDInFile, "UI", sopInstanceUID),
+			element(tag.InstanceNumber, "IS", "1"),
+		}
+	}
+	writeFixture(t, filepath.Join(dir, "DICOMDIR"), []fixtureRecord{
+		{recordType: "PATIENT", next: 5, lower: 1, keys: []*go2com.Element{element(tag.PatientID, "LO", "P1")}},
+		{recordType: "STUDY", next: -1, lower: 2, keys: []*go2com.Element{element(tag.StudyInstanceUID, "UI", "1.2.3")}},
+		{recordType: "SERIES", next: -1, lower: 3, keys: []*go2com.Element{element(tag.Modality, "CS", "OT")}},
+		{recordType: "IMAGE", next: 4, lower: -1, keys: image([]string{"DICOM", "ST1", "IM1"}, "1.2.3.1")},
+		{recordType: "IMAGE", next: 6, lower: -1, inactive: true, keys: image([]string{"DICOM", "ST1", "IM2"}, "1.2.3.2")},
+		{recordType: "PATIENT", next: -1, lower: -1, keys: []*go2com.Element{element(tag.PatientID, "LO", "P2")}},
+		{recordType: "IMAGE", next: -1, lower: -1, keys: image([]string{"DICOM", "ST1", "IM3"}, "1.2.3.3")},
+	})
+
+	d, err := Read(filepath.Join(dir, "DICOMDIR"))
+	assert.NoError(err)
+	assert.Equal("TEST", d.FileSetID)
+	assert.Len(d.Records, 2)
+
+	var types []string
+	err = d.Walk(func(rec *Record, parents []*Record) error {
+		types = append(types, rec.Type)
+		if rec.Type == "IMAGE" {
+			assert.Len(parents, 3)
+		}
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"PATIENT", "STUDY", "SERIES", "IMAGE", "IMAGE", "PATIENT"}, types)
+
+	img := d.Records[0].Children[0].Children[0].Children[0]
+	assert.Equal([]string{"DICOM", "ST1", "IM1"}, img.FileID)
+	assert.Equal(filepath.Join(dir, "dicom", "st1", "im1"), img.Path)
+	elem, err := img.Dataset.FindElementByTag(tag.ReferencedSOPInstanceUIDInFile)
+	assert.NoError(err)
+	assert.Equal("1.2.3.1", elem.Value.RawValue)
+	assert.Equal([]string{img.Path, filepath.Join(dir, "DICOM", "ST1", "IM3")}, d.Files())
+}
+
+func TestRead_InvalidOffset(t *testing.T) {
+	assert := assert.New(t)
+	path := filepath.Join(t.TempDir(), "DICOMDIR")
+	writeFixture(t, path, []fixtureRecord{
+		{recordType: "PATIENT", next: 0, lower: -1},
+	})
+	_, err := Read(path)
+	assert.Error(err)
+}
@@ -0,0 +1,509 @@
+package dicomdir
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// maxFileIDComponents is the maximum depth of a file ID (PS3.10 8.5)
+const maxFileIDComponents = 8
+
+// Attribute types of the keys of the directory records (PS3.3 F.5)
+const (
+	type1 = iota + 1
+	type1C
+	type2
+)
+
+type recordKey struct {
+	tag     tag.DicomTag
+	keyType int
+}
+
+// recordKeys holds the keys of each directory record type required by the General Purpose CD-R profile
+var recordKeys = map[string][]recordKey{
+	"PATIENT": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.PatientName, type2},
+		{tag.PatientID, type1},
+	},
+	"STUDY": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.StudyDate, type1},
+		{tag.StudyTime, type1},
+		{tag.AccessionNumber, type2},
+		{tag.StudyDescription, type2},
+		{tag.StudyInstanceUID, type1},
+		{tag.StudyID, type1},
+	},
+	"SERIES": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.Modality, type1},
+		{tag.SeriesInstanceUID, type1},
+		{tag.SeriesNumber, type1},
+	},
+	"IMAGE": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.InstanceNumber, type1},
+	},
+	"PRESENTATION": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.InstanceNumber, type1},
+		{tag.ContentLabel, type1},
+		{tag.ContentDescription, type2},
+		{tag.PresentationCreationDate, type1},
+		{tag.PresentationCreationTime, type1},
+		{tag.ContentCreatorName, type2},
+	},
+	"SR DOCUMENT": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.InstanceNumber, type1},
+		{tag.CompletionFlag, type1},
+		{tag.VerificationFlag, type1},
+		{tag.ContentDate, type1},
+		{tag.ContentTime, type1},
+		{tag.ConceptNameCodeSequence, type1},
+	},
+	"KEY OBJECT DOC": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.InstanceNumber, type1},
+		{tag.ContentDate, type1},
+		{tag.ContentTime, type1},
+		{tag.ConceptNameCodeSequence, type1},
+	},
+	"WAVEFORM": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.InstanceNumber, type1},
+		{tag.ContentDate, type1},
+		{tag.ContentTime, type1},
+	},
+	"ENCAP DOC": {
+		{tag.SpecificCharacterSet, type1C},
+		{tag.InstanceNumber, type1},
+		{tag.ContentDate, type2},
+		{tag.ContentTime, type2},
+		{tag.DocumentTitle, type2},
+		{tag.MIMETypeOfEncapsulatedDocument, type1},
+	},
+}
+
+// instanceRecordType returns the directory record type of the instances of the storage SOP class
+func instanceRecordType(sopClassUID string) string {
+	switch {
+	case sopClassUID == "1.2.840.10008.5.1.4.1.1.88.59":
+		return "KEY OBJECT DOC"
+	case strings.HasPrefix(sopClassUID, "1.2.840.10008.5.1.4.1.1.88."):
+		return "SR DOCUMENT"
+	case strings.HasPrefix(sopClassUID, "1.2.840.10008.5.1.4.1.1.11."):
+		return "PRESENTATION"
+	case strings.HasPrefix(sopClassUID, "1.2.840.10008.5.1.4.1.1.9."):
+		return "WAVEFORM"
+	case strings.HasPrefix(sopClassUID, "1.2.840.10008.5.1.4.1.1.104."):
+		return "ENCAP DOC"
+	default:
+		return "IMAGE"
+	}
+}
+
+type writerConfig struct {
+	fileSetID  string
+	inventKeys bool
+}
+
+// WithFileSetID provides option to set the File-set ID of the DICOMDIR, up to 16 characters
+func WithFileSetID(fileSetID string) func(*writerConfig) {
+	return func(c *writerConfig) {
+		c.fileSetID = fileSetID
+	}
+}
+
+// WithInventMissingKeys provides option to invent the required keys missing from the files instead of failing,
+// e.g.: for anonymized files without PatientID. Identifiers and numbers are generated from the position of the
+// record in its directory entity, dates and times are set to 19000101 and 000000
+func WithInventMissingKeys(inventKeys bool) func(*writerConfig) {
+	return func(c *writerConfig) {
+		c.inventKeys = inventKeys
+	}
+}
+
+// Create writes the DICOMDIR of the DICOM files found in dir, following the General Purpose CD-R interchange
+// profile (STD-GEN-CD): the files must be encoded in Explicit VR Little Endian and their paths relative to dir
+// must be valid file IDs, see FileID. The files which are not Part 10 files are ignored
+func Create(dir string, options ...func(*writerConfig)) error {
+	files := make([]*file, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if filepath.Dir(path) == filepath.Clean(dir) && d.Name() == "DICOMDIR" {
+			return nil
+		}
+		f, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return nil
+		}
+		f.fileID, err = FileID(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return writeDICOMDIR(filepath.Join(dir, "DICOMDIR"), files, options...)
+}
+
+// Export copies the DICOM files to dir under ISO 9660 file IDs generated from the patient, study, series and
+// instance of each file, e.g.: DICOM/PAT00001/STU00001/SER00001/IMG00001, and writes the DICOMDIR of dir. The
+// files must be encoded in Explicit VR Little Endian
+func Export(dir string, paths []string, options ...func(*writerConfig)) error {
+	files := make([]*file, 0, len(paths))
+	for _, path := range paths {
+		f, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return fmt.Errorf("dicomdir: %s is not a DICOM file", path)
+		}
+		files = append(files, f)
+	}
+
+	// The file IDs are numbered in the order of the tree of the DICOMDIR
+	for p, patient := range buildTree(files) {
+		for s, study := range patient.children {
+			for r, series := range study.children {
+				for i, instance := range series.children {
+					instance.file.fileID = []string{
+						"DICOM",
+						fmt.Sprintf("PAT%05d", p+1),
+						fmt.Sprintf("STU%05d", s+1),
+						fmt.Sprintf("SER%05d", r+1),
+						fmt.Sprintf("IMG%05d", i+1),
+					}
+				}
+			}
+		}
+	}
+	for _, f := range files {
+		dst := filepath.Join(append([]string{dir}, f.fileID...)...)
+		err := copyFile(f.path, dst)
+		if err != nil {
+			return err
+		}
+	}
+	return writeDICOMDIR(filepath.Join(dir, "DICOMDIR"), files, options...)
+}
+
+// FileID returns the components of the file ID of the path relative to root. Each component must be made of 1 to
+// 8 upper case letters, digits or underscores, with at most 8 components (PS3.12 F.3.2.2)
+func FileID(root, path string) ([]string, error) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, err
+	}
+	components := strings.Split(filepath.ToSlash(rel), "/")
+	if len(components) > maxFileIDComponents {
+		return nil, fmt.Errorf("dicomdir: file ID of %s has more than %d components", rel, maxFileIDComponents)
+	}
+	for _, component := range components {
+		if len(component) == 0 || len(component) > 8 {
+			return nil, fmt.Errorf("dicomdir: file ID component %q of %s must have 1 to 8 characters", component, rel)
+		}
+		for _, c := range component {
+			if !(c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_') {
+				return nil, fmt.Errorf("dicomdir: file ID component %q of %s must be made of A-Z, 0-9 and _", component, rel)
+			}
+		}
+	}
+	return components, nil
+}
+
+// file is a DICOM file referenced by the DICOMDIR
+type file struct {
+	path    string
+	fileID  []string
+	meta    go2com.Dataset
+	dataset go2com.Dataset
+}
+
+// readFile parses the file without its pixel data, it returns nil if the file is not a Part 10 file
+func readFile(path string) (*file, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	rd := go2com.NewDICOMReader(bufio.NewReader(f), go2com.WithSkipPixelData(true), go2com.WithSetFileSize(info.Size()))
+	if rd.IsValidDICOM() != nil {
+		return nil, nil
+	}
+	err = rd.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("dicomdir: cannot parse %s: %v", path, err)
+	}
+	res := &file{path: path, meta: rd.GetMetadata()}
+	// The skipped pixel data is left as a nil element
+	for _, elem := range rd.GetDataset().Elements {
+		if elem != nil {
+			res.dataset.Elements = append(res.dataset.Elements, elem)
+		}
+	}
+	if ts := stringValue(res.meta, tag.TransferSyntaxUID); ts != uid.ExplicitVRLittleEndian {
+		return nil, fmt.Errorf("dicomdir: %s: transfer syntax %s is not allowed by the General Purpose CD-R profile", path, ts)
+	}
+	return res, nil
+}
+
+func copyFile(src, dst string) error {
+	err := os.MkdirAll(filepath.Dir(dst), 0755)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// node is a directory record to write, with its encoded keys
+type node struct {
+	recordType string
+	uid        string
+	file       *file
+	// index is the position of the record in its directory entity, starting at 1
+	index    int
+	keys     []byte
+	offset   uint32
+	children []*node
+}
+
+// buildTree arranges the files in PATIENT, STUDY, SERIES and instance records, in the order of the files
+func buildTree(files []*file) []*node {
+	patients := make([]*node, 0)
+	child := func(nodes *[]*node, recordType, key string, f *file) *node {
+		for _, n := range *nodes {
+			if n.uid == key {
+				return n
+			}
+		}
+		n := &node{recordType: recordType, uid: key, file: f, index: len(*nodes) + 1}
+		*nodes = append(*nodes, n)
+		return n
+	}
+	for _, f := range files {
+		patient := child(&patients, "PATIENT", stringValue(f.dataset, tag.PatientID)+"\\"+stringValue(f.dataset, tag.PatientName), f)
+		study := child(&patient.children, "STUDY", stringValue(f.dataset, tag.StudyInstanceUID), f)
+		series := child(&study.children, "SERIES", stringValue(f.dataset, tag.SeriesInstanceUID), f)
+		series.children = append(series.children, &node{
+			recordType: instanceRecordType(stringValue(f.meta, tag.MediaStorageSOPClassUID)),
+			file:       f,
+			index:      len(series.children) + 1,
+		})
+	}
+	return patients
+}
+
+// recordElements returns the keys of the record, taken from its file
+func recordElements(n *node, cfg *writerConfig) ([]*go2com.Element, error) {
+	elements := make([]*go2com.Element, 0)
+	elements = append(elements, go2com.NewElement(tag.DirectoryRecordType, n.recordType))
+	for _, key := range recordKeys[n.recordType] {
+		elem, err := n.file.dataset.FindElementByTag(key.tag)
+		present := err == nil && elem.Value.RawValue != nil && elem.Value.RawValue != ""
+		switch {
+		case present:
+			elements = append(elements, elem)
+		case key.keyType == type1 && cfg.inventKeys && inventedKey(key.tag, n) != "":
+			elements = append(elements, go2com.NewElement(key.tag, inventedKey(key.tag, n)))
+		case key.keyType == type1:
+			name := key.tag.String()
+			if info, err := tag.Find(key.tag); err == nil {
+				name = info.Name
+			}
+			return nil, fmt.Errorf("dicomdir: %s: missing %s required by the %s record", n.file.path, name, n.recordType)
+		case key.keyType == type2:
+			elements = append(elements, go2com.NewElement(key.tag, ""))
+		}
+	}
+	if len(n.children) == 0 {
+		elements = append(elements,
+			go2com.NewElement(tag.ReferencedFileID, n.file.fileID),
+			go2com.NewElement(tag.ReferencedSOPClassUIDInFile, stringValue(n.file.meta, tag.MediaStorageSOPClassUID)),
+			go2com.NewElement(tag.ReferencedSOPInstanceUIDInFile, stringValue(n.file.meta, tag.MediaStorageSOPInstanceUID)),
+			go2com.NewElement(tag.ReferencedTransferSyntaxUIDInFile, stringValue(n.file.meta, tag.TransferSyntaxUID)),
+		)
+	}
+	sort.SliceStable(elements, func(i, j int) bool {
+		a, b := elements[i].Tag, elements[j].Tag
+		return a.Group < b.Group || a.Group == b.Group && a.Element < b.Element
+	})
+	return elements, nil
+}
+
+// inventedKey returns the value of a missing type 1 key, empty if it cannot be invented
+func inventedKey(t tag.DicomTag, n *node) string {
+	switch t {
+	case tag.PatientID:
+		return fmt.Sprintf("PAT%05d", n.index)
+	case tag.StudyID, tag.SeriesNumber, tag.InstanceNumber:
+		return fmt.Sprint(n.index)
+	case tag.StudyDate, tag.ContentDate, tag.PresentationCreationDate:
+		return "19000101"
+	case tag.StudyTime, tag.ContentTime, tag.PresentationCreationTime:
+		return "000000"
+	}
+	return ""
+}
+
+// recordHeaderLength is the length of the item header and of the offset and flag elements of a record
+const recordHeaderLength = 8 + 12 + 10 + 12
+
+// writeDICOMDIR lays out the directory records in depth-first order, computes their offsets and writes the
+// DICOMDIR
+func writeDICOMDIR(path string, files []*file, options ...func(*writerConfig)) error {
+	cfg := &writerConfig{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	tree := buildTree(files)
+	ordered := make([]*node, 0)
+	var flatten func(nodes []*node)
+	flatten = func(nodes []*node) {
+		for _, n := range nodes {
+			ordered = append(ordered, n)
+			flatten(n.children)
+		}
+	}
+	flatten(tree)
+
+	for _, n := range ordered {
+		elements, err := recordElements(n, cfg)
+		if err != nil {
+			return err
+		}
+		buf := bytes.Buffer{}
+		w := go2com.NewDICOMWriter(&buf)
+		for _, elem := range elements {
+			err = w.WriteElement(elem)
+			if err != nil {
+				return fmt.Errorf("dicomdir: cannot encode %s: %v", elem.Tag, err)
+			}
+		}
+		n.keys = buf.Bytes()
+	}
+
+	sopInstanceUID, err := uid.GenerateUUID()
+	if err != nil {
+		return err
+	}
+	buf := bytes.Buffer{}
+	w := go2com.NewDICOMWriter(&buf)
+	err = w.WriteFileMeta(go2com.NewFileMeta(uid.MediaStorageDirectoryStorage, sopInstanceUID, uid.ExplicitVRLittleEndian))
+	if err != nil {
+		return err
+	}
+	header := func(first, last uint32) []*go2com.Element {
+		return []*go2com.Element{
+			go2com.NewElement(tag.FileSetID, cfg.fileSetID),
+			go2com.NewElement(tag.OffsetOfTheFirstDirectoryRecordOfTheRootDirectoryEntity, int(first)),
+			go2com.NewElement(tag.OffsetOfTheLastDirectoryRecordOfTheRootDirectoryEntity, int(last)),
+			go2com.NewElement(tag.FileSetConsistencyFlag, 0),
+		}
+	}
+	headerBuf := bytes.Buffer{}
+	for _, elem := range header(0, 0) {
+		err = go2com.NewDICOMWriter(&headerBuf).WriteElement(elem)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The offsets are known once all the records are laid out, as the offset elements have a fixed length
+	pos := uint32(buf.Len() + headerBuf.Len() + 12)
+	sequenceLength := uint32(0)
+	for _, n := range ordered {
+		n.offset = pos + sequenceLength
+		sequenceLength += uint32(recordHeaderLength + len(n.keys))
+	}
+	var first, last uint32
+	if len(tree) > 0 {
+		first, last = tree[0].offset, tree[len(tree)-1].offset
+	}
+	for _, elem := range header(first, last) {
+		err = w.WriteElement(elem)
+		if err != nil {
+			return err
+		}
+	}
+	buf.Write([]byte{0x04, 0x00, 0x20, 0x12, 'S', 'Q', 0x00, 0x00})
+	_ = binary.Write(&buf, binary.LittleEndian, sequenceLength)
+
+	var writeRecords func(nodes []*node) error
+	writeRecords = func(nodes []*node) error {
+		for i, n := range nodes {
+			var next, lower uint32
+			if i+1 < len(nodes) {
+				next = nodes[i+1].offset
+			}
+			if len(n.children) > 0 {
+				lower = n.children[0].offset
+			}
+			buf.Write([]byte{0xFE, 0xFF, 0x00, 0xE0})
+			_ = binary.Write(&buf, binary.LittleEndian, uint32(recordHeaderLength-8+len(n.keys)))
+			for _, elem := range []*go2com.Element{
+				go2com.NewElement(tag.OffsetOfTheNextDirectoryRecord, int(next)),
+				go2com.NewElement(tag.RecordInUseFlag, 0xFFFF),
+				go2com.NewElement(tag.OffsetOfReferencedLowerLevelDirectoryEntity, int(lower)),
+			} {
+				err := w.WriteElement(elem)
+				if err != nil {
+					return err
+				}
+			}
+			buf.Write(n.keys)
+			err := writeRecords(n.children)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	err = writeRecords(tree)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
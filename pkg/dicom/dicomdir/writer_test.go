@@ -0,0 +1,84 @@
+package dicomdir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+var exportFiles = []string{
+	"../../../dicom_test/014.dcm",
+	"../../../dicom_test/015.DCM",
+	"../../../dicom_test/016.dcm",
+}
+
+func TestExport(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+
+	err := Export(dir, exportFiles)
+	assert.ErrorContains(err, "missing PatientID required by the PATIENT record")
+
+	assert.NoError(Export(dir, exportFiles, WithFileSetID("TEACHING"), WithInventMissingKeys(true)))
+	d, err := Read(filepath.Join(dir, "DICOMDIR"))
+	assert.NoError(err)
+	assert.Equal("TEACHING", d.FileSetID)
+	assert.Equal(uid.MediaStorageDirectoryStorage, stringValue(d.Metadata, tag.MediaStorageSOPClassUID))
+
+	files := d.Files()
+	assert.Len(files, len(exportFiles))
+	instances := 0
+	err = d.Walk(func(rec *Record, parents []*Record) error {
+		levels := []string{"PATIENT", "STUDY", "SERIES", "IMAGE"}
+		assert.Equal(levels[len(parents)], rec.Type)
+		if rec.Type != "IMAGE" {
+			assert.NotEmpty(rec.Children)
+			return nil
+		}
+		instances++
+		assert.Len(rec.FileID, 5)
+		src, err := readFile(rec.Path)
+		assert.NoError(err)
+		assert.Equal(stringValue(src.dataset, tag.SOPInstanceUID), stringValue(rec.Dataset, tag.ReferencedSOPInstanceUIDInFile))
+		assert.Equal(uid.ExplicitVRLittleEndian, stringValue(rec.Dataset, tag.ReferencedTransferSyntaxUIDInFile))
+		assert.NotEmpty(stringValue(parents[0].Dataset, tag.PatientID))
+		assert.NotEmpty(stringValue(parents[1].Dataset, tag.StudyInstanceUID))
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(len(exportFiles), instances)
+}
+
+func TestCreate(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	data, err := os.ReadFile(exportFiles[0])
+	assert.NoError(err)
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "IMAGES"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "IMAGES", "IM_1"), data, 0644))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "README"), []byte("not a DICOM file"), 0644))
+
+	assert.NoError(Create(dir, WithInventMissingKeys(true)))
+	d, err := Read(filepath.Join(dir, "DICOMDIR"))
+	assert.NoError(err)
+	assert.Equal([]string{filepath.Join(dir, "IMAGES", "IM_1")}, d.Files())
+
+	assert.NoError(os.WriteFile(filepath.Join(dir, "IMAGES", "image.dcm"), data, 0644))
+	assert.Error(Create(dir, WithInventMissingKeys(true)))
+}
+
+func TestFileID(t *testing.T) {
+	assert := assert.New(t)
+	fileID, err := FileID("/media", "/media/DICOM/ST00001/IM00001")
+	assert.NoError(err)
+	assert.Equal([]string{"DICOM", "ST00001", "IM00001"}, fileID)
+
+	for _, path := range []string{"/media/dicom/IM1", "/media/DICOM/IMAGE0001", "/media/A/B/C/D/E/F/G/H/I", "/media/IM1.DCM"} {
+		_, err = FileID("/media", path)
+		assert.Error(err, path)
+	}
+}
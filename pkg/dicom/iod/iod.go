@@ -0,0 +1,362 @@
+package iod
+
+//go:generate go run ../../../cmd/dicomgen -part3 $DICOM_STANDARD/part03.xml -part6 $DICOM_STANDARD/part06.xml -modules module_definitions.go -iods iod_definitions.go
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/validate"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// Type is the type of an attribute of a module, defined by PS3.5 7.4
+type Type string
+
+const (
+	// Type1 attributes are required and not empty
+	Type1 Type = "1"
+	// Type1C attributes are Type 1 when their condition is met
+	Type1C Type = "1C"
+	// Type2 attributes are required and may be empty
+	Type2 Type = "2"
+	// Type2C attributes are Type 2 when their condition is met
+	Type2C Type = "2C"
+	// Type3 attributes are optional
+	Type3 Type = "3"
+)
+
+// Usage is the usage of a module by an IOD, defined by PS3.3 A.1.3
+type Usage string
+
+const (
+	// Mandatory modules are always required
+	Mandatory Usage = "M"
+	// Conditional modules are required when their condition is met
+	Conditional Usage = "C"
+	// UserOption modules are optional
+	UserOption Usage = "U"
+)
+
+// Condition returns true if the condition of a conditional attribute or module is met by the dataset, or by the item
+// of the sequence holding the attribute
+type Condition func(ds go2com.Dataset) bool
+
+// Enumeration holds the enumerated values of an attribute
+type Enumeration struct {
+	// Value is the index from 1 of the value the enumeration applies to, e.g.: 1 for the first value of Image Type,
+	// or 0 for all the values
+	Value  int
+	Values []string
+}
+
+// Attribute is the definition of an attribute of a module
+type Attribute struct {
+	Tag  tag.DicomTag
+	Type Type
+	// Condition is the condition of the Type 1C and 2C attributes, nil if it cannot be evaluated on the dataset. The
+	// presence of the attributes of nil condition is not checked
+	Condition  Condition
+	Enumerated []Enumeration
+	// Items holds the attributes of the items of a sequence
+	Items []Attribute
+}
+
+// Module is the definition of a module, a group of related attributes
+type Module struct {
+	Name       string
+	Attributes []Attribute
+}
+
+// ModuleUsage is a module of an IOD with its usage
+type ModuleUsage struct {
+	Module *Module
+	Usage  Usage
+	// Condition is the condition of a conditional module, nil if it cannot be evaluated on the dataset. The modules
+	// of nil condition and the user optional modules are validated when one of their attributes is present
+	Condition Condition
+}
+
+// IOD is the definition of an information object, the modules of the instances of its SOP classes
+type IOD struct {
+	Name         string
+	SOPClassUIDs []string
+	Modules      []ModuleUsage
+}
+
+// Find returns the IOD of the SOP class
+func Find(sopClassUID string) (*IOD, error) {
+	for _, iod := range IODs {
+		for _, u := range iod.SOPClassUIDs {
+			if u == sopClassUID {
+				return iod, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("could not find the IOD of SOP class %s", sopClassUID)
+}
+
+// Validate validates the dataset against the IOD of its SOP Class UID
+func Validate(ds go2com.Dataset) ([]validate.Finding, error) {
+	elem, err := ds.FindElementByTag(tag.SOPClassUID)
+	if err != nil {
+		return nil, fmt.Errorf("dataset has no SOP Class UID")
+	}
+	values := stringValues(elem)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("dataset has an empty SOP Class UID")
+	}
+	iod, err := Find(values[0])
+	if err != nil {
+		return nil, err
+	}
+	return iod.Validate(ds), nil
+}
+
+// Validate validates the dataset against the modules of the IOD: the missing Type 1 and 2 attributes, the Type 1C and
+// 2C attributes whose condition is met, the empty Type 1 attributes and the values not enumerated
+func (iod *IOD) Validate(ds go2com.Dataset) []validate.Finding {
+	v := validator{reported: make(map[string]bool)}
+	for _, usage := range iod.Modules {
+		switch {
+		case usage.Usage == Mandatory:
+		case usage.Usage == Conditional && usage.Condition != nil:
+			if !usage.Condition(ds) {
+				continue
+			}
+		default:
+			if !usage.Module.isPresent(ds) {
+				continue
+			}
+		}
+		v.validateAttributes(ds, usage.Module.Attributes, usage.Module.Name, "")
+	}
+	return v.findings
+}
+
+// isPresent returns true if one of the attributes of the module is in the dataset
+func (m *Module) isPresent(ds go2com.Dataset) bool {
+	for _, attr := range m.Attributes {
+		if find(ds, attr.Tag) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+type validator struct {
+	findings []validate.Finding
+	// reported holds the issues already reported, of the attributes shared by several modules
+	reported map[string]bool
+}
+
+// report adds the finding of the issue, once per key
+func (v *validator) report(key, path string, t tag.DicomTag, format string, args ...interface{}) {
+	if v.reported[key] {
+		return
+	}
+	v.reported[key] = true
+	v.findings = append(v.findings, validate.Finding{
+		Path:     path,
+		Tag:      t,
+		Severity: validate.SeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) validateAttributes(ds go2com.Dataset, attributes []Attribute, module, parent string) {
+	for _, attr := range attributes {
+		path := parent + attr.Tag.String()
+		name := tagName(attr.Tag)
+		elem := find(ds, attr.Tag)
+		required := attr.Type == Type1 || attr.Type == Type2 ||
+			(attr.Type == Type1C || attr.Type == Type2C) && attr.Condition != nil && attr.Condition(ds)
+		if elem == nil {
+			if required {
+				v.report(path, path, attr.Tag, "missing Type %s attribute %s of the %s module", attr.Type, name, module)
+			}
+			continue
+		}
+		if isEmpty(elem) {
+			// The Type 1C attributes are Type 1 when present
+			if attr.Type == Type1 || attr.Type == Type1C {
+				v.report(path, path, attr.Tag, "empty Type %s attribute %s of the %s module", attr.Type, name, module)
+			}
+			continue
+		}
+		for _, enum := range attr.Enumerated {
+			for i, value := range stringValues(elem) {
+				if enum.Value > 0 && enum.Value != i+1 || contains(enum.Values, value) {
+					continue
+				}
+				v.report(fmt.Sprintf("%s=%d", path, i), path, attr.Tag, "value %d %q of %s is not one of the enumerated values %s of the %s module",
+					i+1, value, name, strings.Join(enum.Values, ", "), module)
+			}
+		}
+		if len(attr.Items) > 0 {
+			for i, item := range splitItems(elem) {
+				v.validateAttributes(go2com.Dataset{Elements: item}, attr.Items, module, fmt.Sprintf("%s[%d].", path, i))
+			}
+		}
+	}
+}
+
+// Present returns the condition met if the attribute is present
+func Present(t tag.DicomTag) Condition {
+	return func(ds go2com.Dataset) bool {
+		return find(ds, t) != nil
+	}
+}
+
+// HasValue returns the condition met if one of the values of the attribute is one of the values
+func HasValue(t tag.DicomTag, values ...string) Condition {
+	return func(ds go2com.Dataset) bool {
+		elem := find(ds, t)
+		if elem == nil {
+			return false
+		}
+		for _, value := range stringValues(elem) {
+			if contains(values, value) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns the condition met if the condition is not met
+func Not(c Condition) Condition {
+	return func(ds go2com.Dataset) bool {
+		return !c(ds)
+	}
+}
+
+// And returns the condition met if all the conditions are met
+func And(conditions ...Condition) Condition {
+	return func(ds go2com.Dataset) bool {
+		for _, c := range conditions {
+			if !c(ds) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns the condition met if one of the conditions is met
+func Or(conditions ...Condition) Condition {
+	return func(ds go2com.Dataset) bool {
+		for _, c := range conditions {
+			if c(ds) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func find(ds go2com.Dataset, t tag.DicomTag) *go2com.Element {
+	for _, elem := range ds.Elements {
+		if elem != nil && elem.Tag == t {
+			return elem
+		}
+	}
+	return nil
+}
+
+func tagName(t tag.DicomTag) string {
+	info, err := tag.Find(t)
+	if err != nil {
+		return t.String()
+	}
+	return info.Name
+}
+
+// stringValues returns the values of the element formatted as strings, e.g.: 16 for the value of Bits Allocated
+func stringValues(elem *go2com.Element) []string {
+	switch v := elem.Value.RawValue.(type) {
+	case nil:
+		return nil
+	case string:
+		return trimValues(strings.Split(v, "\\"))
+	case []string:
+		return trimValues(v)
+	case []byte, []*go2com.Element:
+		return nil
+	}
+	rv := reflect.ValueOf(elem.Value.RawValue)
+	if rv.Kind() != reflect.Slice {
+		return []string{fmt.Sprint(elem.Value.RawValue)}
+	}
+	values := make([]string, rv.Len())
+	for i := range values {
+		values[i] = fmt.Sprint(rv.Index(i).Interface())
+	}
+	return values
+}
+
+func trimValues(values []string) []string {
+	res := make([]string, 0, len(values))
+	for _, value := range values {
+		res = append(res, strings.Trim(value, " \x00"))
+	}
+	if len(res) == 1 && res[0] == "" {
+		return nil
+	}
+	return res
+}
+
+// isEmpty returns true if the element has no value, or no item for a sequence
+func isEmpty(elem *go2com.Element) bool {
+	switch v := elem.Value.RawValue.(type) {
+	case nil:
+		return true
+	case string, []string:
+		return len(stringValues(elem)) == 0
+	case []*go2com.Element:
+		return elem.ValueRepresentationStr == vr.SequenceOfItems && len(splitItems(elem)) == 0
+	case []byte:
+		return len(v) == 0
+	}
+	rv := reflect.ValueOf(elem.Value.RawValue)
+	return rv.Kind() == reflect.Slice && rv.Len() == 0
+}
+
+func splitItems(elem *go2com.Element) [][]*go2com.Element {
+	subElements, _ := elem.Value.RawValue.([]*go2com.Element)
+	items := make([][]*go2com.Element, 0)
+	var current []*go2com.Element
+	for _, subElem := range subElements {
+		if subElem == nil {
+			continue
+		}
+		if len(current) > 0 && !tagLess(current[len(current)-1].Tag, subElem.Tag) {
+			items = append(items, current)
+			current = nil
+		}
+		current = append(current, subElem)
+	}
+	if len(current) > 0 {
+		items = append(items, current)
+	}
+	return items
+}
+
+func tagLess(a, b tag.DicomTag) bool {
+	if a.Group != b.Group {
+		return a.Group < b.Group
+	}
+	return a.Element < b.Element
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
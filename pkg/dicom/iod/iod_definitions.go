@@ -1 +1,227 @@
 package iod
+
+import "github.com/okieraised/go2com/pkg/dicom/uid"
+
+// The IODs of PS3.3 A, limited to their modules of Type 1 and 2 attributes
+
+// CTImageIOD is the CT Image IOD, PS3.3 A.3
+var CTImageIOD = &IOD{
+	Name:         "CT Image",
+	SOPClassUIDs: []string{uid.CTImageStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: Mandatory},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePlaneModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: Conditional},
+		{Module: CTImageModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// MRImageIOD is the MR Image IOD, PS3.3 A.4
+var MRImageIOD = &IOD{
+	Name:         "MR Image",
+	SOPClassUIDs: []string{uid.MRImageStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: Mandatory},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePlaneModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: Conditional},
+		{Module: MRImageModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// CRImageIOD is the Computed Radiography Image IOD, PS3.3 A.2
+var CRImageIOD = &IOD{
+	Name:         "Computed Radiography Image",
+	SOPClassUIDs: []string{uid.ComputedRadiographyImageStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: CRSeriesModule, Usage: Mandatory},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: UserOption},
+		{Module: CRImageModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// DXImageIOD is the Digital X-Ray Image IOD, PS3.3 A.26
+var DXImageIOD = &IOD{
+	Name:         "Digital X-Ray Image",
+	SOPClassUIDs: []string{uid.DXImageStorageForPresentation, uid.DXImageStorageForProcessing},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: DXSeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: UserOption},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: UserOption},
+		{Module: DXAnatomyImagedModule, Usage: Mandatory},
+		{Module: DXImageModule, Usage: Mandatory},
+		{Module: DXDetectorModule, Usage: Mandatory},
+		{Module: AcquisitionContextModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// MGImageIOD is the Digital Mammography X-Ray Image IOD, PS3.3 A.27
+var MGImageIOD = &IOD{
+	Name:         "Digital Mammography X-Ray Image",
+	SOPClassUIDs: []string{uid.MGImageStorageForPresentation, uid.MGImageStorageForProcessing},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: DXSeriesModule, Usage: Mandatory},
+		{Module: MammographySeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: Conditional},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: Conditional},
+		{Module: DXAnatomyImagedModule, Usage: Mandatory},
+		{Module: DXImageModule, Usage: Mandatory},
+		{Module: DXDetectorModule, Usage: Mandatory},
+		{Module: MammographyImageModule, Usage: Mandatory},
+		{Module: AcquisitionContextModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// USImageIOD is the Ultrasound Image IOD, PS3.3 A.6
+var USImageIOD = &IOD{
+	Name:         "Ultrasound Image",
+	SOPClassUIDs: []string{uid.UltrasoundImageStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: UserOption},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: Conditional},
+		{Module: USImageModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// USMultiFrameImageIOD is the Ultrasound Multi-frame Image IOD, PS3.3 A.7
+var USMultiFrameImageIOD = &IOD{
+	Name:         "Ultrasound Multi-frame Image",
+	SOPClassUIDs: []string{uid.UltrasoundMultiFrameStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: UserOption},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: ContrastBolusModule, Usage: Conditional},
+		{Module: CineModule, Usage: Mandatory},
+		{Module: MultiFrameModule, Usage: Mandatory},
+		{Module: USImageModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// SCImageIOD is the Secondary Capture Image IOD, PS3.3 A.8.1
+var SCImageIOD = &IOD{
+	Name:         "Secondary Capture Image",
+	SOPClassUIDs: []string{uid.SecondaryCaptureImageStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: GeneralEquipmentModule, Usage: UserOption},
+		{Module: SCEquipmentModule, Usage: Mandatory},
+		{Module: GeneralImageModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// SegmentationIOD is the Segmentation IOD, PS3.3 A.51
+var SegmentationIOD = &IOD{
+	Name:         "Segmentation",
+	SOPClassUIDs: []string{uid.SegmentationStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: GeneralSeriesModule, Usage: Mandatory},
+		{Module: SegmentationSeriesModule, Usage: Mandatory},
+		{Module: FrameOfReferenceModule, Usage: Conditional},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: EnhancedGeneralEquipmentModule, Usage: Mandatory},
+		{Module: ImagePixelModule, Usage: Mandatory},
+		{Module: SegmentationImageModule, Usage: Mandatory},
+		{Module: MultiFrameFunctionalGroupsModule, Usage: Mandatory},
+		{Module: MultiFrameDimensionModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// SRDocumentIOD is the Basic Text, Enhanced and Comprehensive SR IODs, PS3.3 A.35.1 to A.35.3
+var SRDocumentIOD = &IOD{
+	Name:         "SR Document",
+	SOPClassUIDs: []string{uid.BasicTextSRStorage, uid.EnhancedSRStorage, uid.ComprehensiveSRStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: SRDocumentSeriesModule, Usage: Mandatory},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: SRDocumentGeneralModule, Usage: Mandatory},
+		{Module: SRDocumentContentModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// RTStructureSetIOD is the RT Structure Set IOD, PS3.3 A.19
+var RTStructureSetIOD = &IOD{
+	Name:         "RT Structure Set",
+	SOPClassUIDs: []string{uid.RTStructureSetStorage},
+	Modules: []ModuleUsage{
+		{Module: PatientModule, Usage: Mandatory},
+		{Module: GeneralStudyModule, Usage: Mandatory},
+		{Module: RTSeriesModule, Usage: Mandatory},
+		{Module: GeneralEquipmentModule, Usage: Mandatory},
+		{Module: StructureSetModule, Usage: Mandatory},
+		{Module: ROIContourModule, Usage: Mandatory},
+		{Module: RTROIObservationsModule, Usage: Mandatory},
+		{Module: SOPCommonModule, Usage: Mandatory},
+	},
+}
+
+// IODs holds the IODs known to Find and Validate
+var IODs = []*IOD{
+	CTImageIOD,
+	MRImageIOD,
+	CRImageIOD,
+	DXImageIOD,
+	MGImageIOD,
+	USImageIOD,
+	USMultiFrameImageIOD,
+	SCImageIOD,
+	SegmentationIOD,
+	SRDocumentIOD,
+	RTStructureSetIOD,
+}
@@ -0,0 +1,127 @@
+package iod
+
+import (
+	"bufio"
+	"os"
+	"testing"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/okieraised/go2com/pkg/dicom/validate"
+	"github.com/stretchr/testify/assert"
+)
+
+func paths(findings []validate.Finding) []string {
+	res := make([]string, 0)
+	for _, f := range findings {
+		res = append(res, f.Path)
+	}
+	return res
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+
+	ds := go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.ImageType, []string{"ORIGINAL", "TERTIARY", "AXIAL"}),
+		go2com.NewElement(tag.SOPClassUID, uid.CTImageStorage),
+		go2com.NewElement(tag.SOPInstanceUID, "1.2.3.4"),
+		go2com.NewElement(tag.StudyDate, "20200101"),
+		go2com.NewElement(tag.StudyTime, ""),
+		go2com.NewElement(tag.AccessionNumber, ""),
+		go2com.NewElement(tag.Modality, "CT"),
+		go2com.NewElement(tag.Manufacturer, "ACME"),
+		go2com.NewElement(tag.ReferringPhysicianName, ""),
+		go2com.NewElement(tag.PatientName, "Doe^John"),
+		go2com.NewElement(tag.PatientID, "123"),
+		go2com.NewElement(tag.PatientBirthDate, ""),
+		go2com.NewElement(tag.PatientSex, "X"),
+		go2com.NewElement(tag.SliceThickness, 1.25),
+		go2com.NewElement(tag.KVP, 120.0),
+		go2com.NewElement(tag.PatientPosition, "HFS"),
+		go2com.NewElement(tag.StudyInstanceUID, "1.2.3"),
+		go2com.NewElement(tag.SeriesInstanceUID, "1.2.3.1"),
+		go2com.NewElement(tag.StudyID, "1"),
+		go2com.NewElement(tag.SeriesNumber, 1),
+		go2com.NewElement(tag.AcquisitionNumber, 1),
+		go2com.NewElement(tag.InstanceNumber, 1),
+		go2com.NewElement(tag.ImagePositionPatient, []string{"0", "0", "0"}),
+		go2com.NewElement(tag.ImageOrientationPatient, []string{"1", "0", "0", "0", "1", "0"}),
+		go2com.NewElement(tag.FrameOfReferenceUID, ""),
+		go2com.NewElement(tag.PositionReferenceIndicator, ""),
+		go2com.NewElement(tag.SamplesPerPixel, 3),
+		go2com.NewElement(tag.PhotometricInterpretation, "RGB"),
+		go2com.NewElement(tag.Rows, 2),
+		go2com.NewElement(tag.Columns, 2),
+		go2com.NewElement(tag.PixelSpacing, []string{"0.5", "0.5"}),
+		go2com.NewElement(tag.BitsAllocated, 16),
+		go2com.NewElement(tag.BitsStored, 12),
+		go2com.NewElement(tag.HighBit, 11),
+		go2com.NewElement(tag.PixelRepresentation, 0),
+		go2com.NewElement(tag.RescaleIntercept, -1024.0),
+		go2com.NewElement(tag.RescaleSlope, 1.0),
+		go2com.NewElement(tag.PixelData, make([]byte, 24)),
+	}}
+	findings, err := Validate(ds)
+	assert.NoError(err)
+	assert.Equal([]string{
+		"(0010,0040)", // enumerated Patient Sex
+		"(0020,0052)", // empty Type 1 Frame of Reference UID
+		"(0028,0006)", // Type 1C Planar Configuration of 3 samples per pixel
+		"(0008,0008)", // enumerated second value of Image Type
+		"(0028,0002)", // enumerated Samples per Pixel of the CT Image module
+		"(0028,0004)",
+	}, paths(findings))
+	for _, f := range findings {
+		assert.NotEmpty(f.Message)
+	}
+
+	_, err = Validate(go2com.Dataset{Elements: []*go2com.Element{go2com.NewElement(tag.SOPClassUID, "1.2.3")}})
+	assert.Error(err)
+}
+
+func TestValidate_Sequences(t *testing.T) {
+	assert := assert.New(t)
+
+	ds := go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.StructureSetLabel, "RS"),
+		go2com.NewElement(tag.StructureSetROISequence, []*go2com.Element{
+			go2com.NewElement(tag.ROINumber, 1),
+			go2com.NewElement(tag.ReferencedFrameOfReferenceUID, "1.2.3"),
+			go2com.NewElement(tag.ROIName, "Body"),
+			go2com.NewElement(tag.ROIGenerationAlgorithm, "AUTOMATIC"),
+			go2com.NewElement(tag.ROINumber, 2),
+			go2com.NewElement(tag.ROIName, "Lung"),
+			go2com.NewElement(tag.ROIGenerationAlgorithm, "GUESS"),
+		}),
+	}}
+	iod := &IOD{Modules: []ModuleUsage{{Module: StructureSetModule, Usage: Mandatory}}}
+	assert.Equal([]string{
+		"(3006,0008)",
+		"(3006,0009)",
+		"(3006,0020)[1].(3006,0024)",
+		"(3006,0020)[1].(3006,0036)",
+	}, paths(iod.Validate(ds)))
+}
+
+func TestValidate_File(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := os.Open("../../../dicom_test/013.dcm")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	assert.NoError(err)
+	rd := go2com.NewDICOMReader(bufio.NewReader(f), go2com.WithSetFileSize(info.Size()))
+	assert.NoError(rd.Parse())
+	findings, err := Validate(rd.GetDataset())
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, finding := range findings {
+		t.Log(finding)
+	}
+}
@@ -0,0 +1,488 @@
+package iod
+
+import (
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// The modules of PS3.3 C, limited to their Type 1 and 2 attributes, the conditional attributes and the attributes of
+// enumerated values
+
+// codeSequenceMacro holds the attributes of the items of the code sequences, PS3.3 Table 8.8-1
+var codeSequenceMacro = []Attribute{
+	{Tag: tag.CodeValue, Type: Type1C, Condition: Not(Or(Present(tag.LongCodeValue), Present(tag.URNCodeValue)))},
+	{Tag: tag.CodingSchemeDesignator, Type: Type1C, Condition: Or(Present(tag.CodeValue), Present(tag.LongCodeValue))},
+	{Tag: tag.LongCodeValue, Type: Type1C},
+	{Tag: tag.URNCodeValue, Type: Type1C},
+	{Tag: tag.CodeMeaning, Type: Type1},
+}
+
+// PatientModule is the Patient module, PS3.3 C.7.1.1
+var PatientModule = &Module{
+	Name: "Patient",
+	Attributes: []Attribute{
+		{Tag: tag.PatientName, Type: Type2},
+		{Tag: tag.PatientID, Type: Type2},
+		{Tag: tag.PatientBirthDate, Type: Type2},
+		{Tag: tag.PatientSex, Type: Type2, Enumerated: enumerated("M", "F", "O")},
+	},
+}
+
+// GeneralStudyModule is the General Study module, PS3.3 C.7.2.1
+var GeneralStudyModule = &Module{
+	Name: "General Study",
+	Attributes: []Attribute{
+		{Tag: tag.StudyInstanceUID, Type: Type1},
+		{Tag: tag.StudyDate, Type: Type2},
+		{Tag: tag.StudyTime, Type: Type2},
+		{Tag: tag.ReferringPhysicianName, Type: Type2},
+		{Tag: tag.StudyID, Type: Type2},
+		{Tag: tag.AccessionNumber, Type: Type2},
+	},
+}
+
+// GeneralSeriesModule is the General Series module, PS3.3 C.7.3.1
+var GeneralSeriesModule = &Module{
+	Name: "General Series",
+	Attributes: []Attribute{
+		{Tag: tag.Modality, Type: Type1},
+		{Tag: tag.SeriesInstanceUID, Type: Type1},
+		{Tag: tag.SeriesNumber, Type: Type2},
+		{Tag: tag.Laterality, Type: Type2C, Enumerated: enumerated("R", "L")},
+		{Tag: tag.PatientPosition, Type: Type2C, Condition: HasValue(tag.SOPClassUID, uid.CTImageStorage, uid.MRImageStorage)},
+	},
+}
+
+// FrameOfReferenceModule is the Frame of Reference module, PS3.3 C.7.4.1
+var FrameOfReferenceModule = &Module{
+	Name: "Frame of Reference",
+	Attributes: []Attribute{
+		{Tag: tag.FrameOfReferenceUID, Type: Type1},
+		{Tag: tag.PositionReferenceIndicator, Type: Type2},
+	},
+}
+
+// GeneralEquipmentModule is the General Equipment module, PS3.3 C.7.5.1
+var GeneralEquipmentModule = &Module{
+	Name: "General Equipment",
+	Attributes: []Attribute{
+		{Tag: tag.Manufacturer, Type: Type2},
+	},
+}
+
+// EnhancedGeneralEquipmentModule is the Enhanced General Equipment module, PS3.3 C.7.5.2
+var EnhancedGeneralEquipmentModule = &Module{
+	Name: "Enhanced General Equipment",
+	Attributes: []Attribute{
+		{Tag: tag.Manufacturer, Type: Type1},
+		{Tag: tag.ManufacturerModelName, Type: Type1},
+		{Tag: tag.DeviceSerialNumber, Type: Type1},
+		{Tag: tag.SoftwareVersions, Type: Type1},
+	},
+}
+
+// GeneralImageModule is the General Image module, PS3.3 C.7.6.1
+var GeneralImageModule = &Module{
+	Name: "General Image",
+	Attributes: []Attribute{
+		{Tag: tag.InstanceNumber, Type: Type2},
+		// The patient orientation is required for the images without image plane
+		{Tag: tag.PatientOrientation, Type: Type2C, Condition: Not(Present(tag.ImageOrientationPatient))},
+	},
+}
+
+// ImagePlaneModule is the Image Plane module, PS3.3 C.7.6.2
+var ImagePlaneModule = &Module{
+	Name: "Image Plane",
+	Attributes: []Attribute{
+		{Tag: tag.PixelSpacing, Type: Type1},
+		{Tag: tag.ImageOrientationPatient, Type: Type1},
+		{Tag: tag.ImagePositionPatient, Type: Type1},
+		{Tag: tag.SliceThickness, Type: Type2},
+	},
+}
+
+// ImagePixelModule is the Image Pixel module, PS3.3 C.7.6.3
+var ImagePixelModule = &Module{
+	Name: "Image Pixel",
+	Attributes: []Attribute{
+		{Tag: tag.SamplesPerPixel, Type: Type1},
+		{Tag: tag.PhotometricInterpretation, Type: Type1},
+		{Tag: tag.Rows, Type: Type1},
+		{Tag: tag.Columns, Type: Type1},
+		{Tag: tag.BitsAllocated, Type: Type1},
+		{Tag: tag.BitsStored, Type: Type1},
+		{Tag: tag.HighBit, Type: Type1},
+		{Tag: tag.PixelRepresentation, Type: Type1, Enumerated: enumerated("0", "1")},
+		{Tag: tag.PlanarConfiguration, Type: Type1C, Condition: multipleSamples, Enumerated: enumerated("0", "1")},
+		{Tag: tag.PixelData, Type: Type1C, Condition: Not(Or(Present(tag.FloatPixelData), Present(tag.DoubleFloatPixelData)))},
+		{Tag: tag.PixelAspectRatio, Type: Type1C},
+	},
+}
+
+// ContrastBolusModule is the Contrast/Bolus module, PS3.3 C.7.6.4
+var ContrastBolusModule = &Module{
+	Name: "Contrast/Bolus",
+	Attributes: []Attribute{
+		{Tag: tag.ContrastBolusAgent, Type: Type2},
+	},
+}
+
+// CineModule is the Cine module, PS3.3 C.7.6.5
+var CineModule = &Module{
+	Name: "Cine",
+	Attributes: []Attribute{
+		{Tag: tag.FrameTime, Type: Type1C},
+	},
+}
+
+// MultiFrameModule is the Multi-frame module, PS3.3 C.7.6.6
+var MultiFrameModule = &Module{
+	Name: "Multi-frame",
+	Attributes: []Attribute{
+		{Tag: tag.NumberOfFrames, Type: Type1},
+		{Tag: tag.FrameIncrementPointer, Type: Type1},
+	},
+}
+
+// MultiFrameFunctionalGroupsModule is the Multi-frame Functional Groups module, PS3.3 C.7.6.16
+var MultiFrameFunctionalGroupsModule = &Module{
+	Name: "Multi-frame Functional Groups",
+	Attributes: []Attribute{
+		{Tag: tag.SharedFunctionalGroupsSequence, Type: Type2},
+		{Tag: tag.PerFrameFunctionalGroupsSequence, Type: Type1},
+		{Tag: tag.InstanceNumber, Type: Type1},
+		{Tag: tag.ContentDate, Type: Type1},
+		{Tag: tag.ContentTime, Type: Type1},
+		{Tag: tag.NumberOfFrames, Type: Type1},
+	},
+}
+
+// MultiFrameDimensionModule is the Multi-frame Dimension module, PS3.3 C.7.6.17
+var MultiFrameDimensionModule = &Module{
+	Name: "Multi-frame Dimension",
+	Attributes: []Attribute{
+		{Tag: tag.DimensionOrganizationSequence, Type: Type1, Items: []Attribute{
+			{Tag: tag.DimensionOrganizationUID, Type: Type1},
+		}},
+		{Tag: tag.DimensionIndexSequence, Type: Type1, Items: []Attribute{
+			{Tag: tag.DimensionIndexPointer, Type: Type1},
+			{Tag: tag.FunctionalGroupPointer, Type: Type1C},
+			{Tag: tag.DimensionOrganizationUID, Type: Type1C},
+		}},
+	},
+}
+
+// AcquisitionContextModule is the Acquisition Context module, PS3.3 C.7.6.14
+var AcquisitionContextModule = &Module{
+	Name: "Acquisition Context",
+	Attributes: []Attribute{
+		{Tag: tag.AcquisitionContextSequence, Type: Type2},
+	},
+}
+
+// SOPCommonModule is the SOP Common module, PS3.3 C.12.1
+var SOPCommonModule = &Module{
+	Name: "SOP Common",
+	Attributes: []Attribute{
+		{Tag: tag.SOPClassUID, Type: Type1},
+		{Tag: tag.SOPInstanceUID, Type: Type1},
+		{Tag: tag.SpecificCharacterSet, Type: Type1C},
+	},
+}
+
+// CTImageModule is the CT Image module, PS3.3 C.8.2.1
+var CTImageModule = &Module{
+	Name: "CT Image",
+	Attributes: []Attribute{
+		{Tag: tag.ImageType, Type: Type1, Enumerated: imageType},
+		{Tag: tag.SamplesPerPixel, Type: Type1, Enumerated: enumerated("1")},
+		{Tag: tag.PhotometricInterpretation, Type: Type1, Enumerated: enumerated("MONOCHROME1", "MONOCHROME2")},
+		{Tag: tag.BitsAllocated, Type: Type1, Enumerated: enumerated("16")},
+		{Tag: tag.BitsStored, Type: Type1, Enumerated: enumerated("12", "13", "14", "15", "16")},
+		{Tag: tag.HighBit, Type: Type1},
+		{Tag: tag.RescaleIntercept, Type: Type1},
+		{Tag: tag.RescaleSlope, Type: Type1},
+		{Tag: tag.RescaleType, Type: Type1C},
+		{Tag: tag.KVP, Type: Type2},
+		{Tag: tag.AcquisitionNumber, Type: Type2},
+	},
+}
+
+// MRImageModule is the MR Image module, PS3.3 C.8.3.1
+var MRImageModule = &Module{
+	Name: "MR Image",
+	Attributes: []Attribute{
+		{Tag: tag.ImageType, Type: Type1, Enumerated: imageType},
+		{Tag: tag.SamplesPerPixel, Type: Type1, Enumerated: enumerated("1")},
+		{Tag: tag.PhotometricInterpretation, Type: Type1, Enumerated: enumerated("MONOCHROME1", "MONOCHROME2")},
+		{Tag: tag.BitsAllocated, Type: Type1, Enumerated: enumerated("16")},
+		{Tag: tag.ScanningSequence, Type: Type1, Enumerated: enumerated("SE", "IR", "GR", "EP", "RM")},
+		{Tag: tag.SequenceVariant, Type: Type1, Enumerated: enumerated("SK", "MTC", "SS", "TRSS", "SP", "MP", "OSP", "NONE")},
+		{Tag: tag.ScanOptions, Type: Type2},
+		{Tag: tag.MRAcquisitionType, Type: Type2, Enumerated: enumerated("2D", "3D")},
+		{Tag: tag.RepetitionTime, Type: Type2C},
+		{Tag: tag.EchoTime, Type: Type2},
+		{Tag: tag.EchoTrainLength, Type: Type2},
+		{Tag: tag.InversionTime, Type: Type2C, Condition: HasValue(tag.ScanningSequence, "IR")},
+	},
+}
+
+// CRSeriesModule is the CR Series module, PS3.3 C.8.1.1
+var CRSeriesModule = &Module{
+	Name: "CR Series",
+	Attributes: []Attribute{
+		{Tag: tag.BodyPartExamined, Type: Type2},
+		{Tag: tag.ViewPosition, Type: Type2},
+	},
+}
+
+// CRImageModule is the CR Image module, PS3.3 C.8.1.2
+var CRImageModule = &Module{
+	Name: "CR Image",
+	Attributes: []Attribute{
+		{Tag: tag.PhotometricInterpretation, Type: Type1, Enumerated: enumerated("MONOCHROME1", "MONOCHROME2")},
+	},
+}
+
+// DXSeriesModule is the DX Series module, PS3.3 C.8.11.1
+var DXSeriesModule = &Module{
+	Name: "DX Series",
+	Attributes: []Attribute{
+		{Tag: tag.Modality, Type: Type1, Enumerated: enumerated("DX", "PX", "IO", "MG")},
+		{Tag: tag.ReferencedPerformedProcedureStepSequence, Type: Type1C},
+		{Tag: tag.PresentationIntentType, Type: Type1, Enumerated: enumerated("FOR PRESENTATION", "FOR PROCESSING")},
+	},
+}
+
+// DXAnatomyImagedModule is the DX Anatomy Imaged module, PS3.3 C.8.11.2
+var DXAnatomyImagedModule = &Module{
+	Name: "DX Anatomy Imaged",
+	Attributes: []Attribute{
+		{Tag: tag.ImageLaterality, Type: Type1, Enumerated: enumerated("R", "L", "U", "B")},
+	},
+}
+
+// DXImageModule is the DX Image module, PS3.3 C.8.11.3
+var DXImageModule = &Module{
+	Name: "DX Image",
+	Attributes: []Attribute{
+		{Tag: tag.ImageType, Type: Type1, Enumerated: imageType},
+		{Tag: tag.SamplesPerPixel, Type: Type1, Enumerated: enumerated("1")},
+		{Tag: tag.PhotometricInterpretation, Type: Type1, Enumerated: enumerated("MONOCHROME1", "MONOCHROME2")},
+		{Tag: tag.BitsAllocated, Type: Type1, Enumerated: enumerated("8", "16")},
+		{Tag: tag.BitsStored, Type: Type1, Enumerated: enumerated("6", "7", "8", "9", "10", "11", "12", "13", "14", "15", "16")},
+		{Tag: tag.HighBit, Type: Type1},
+		{Tag: tag.PixelRepresentation, Type: Type1, Enumerated: enumerated("0")},
+		{Tag: tag.PixelIntensityRelationship, Type: Type1, Enumerated: enumerated("LIN", "LOG")},
+		{Tag: tag.PixelIntensityRelationshipSign, Type: Type1, Enumerated: enumerated("1", "-1")},
+		{Tag: tag.RescaleIntercept, Type: Type1},
+		{Tag: tag.RescaleSlope, Type: Type1, Enumerated: enumerated("1")},
+		{Tag: tag.RescaleType, Type: Type1, Enumerated: enumerated("US")},
+		{Tag: tag.PresentationLUTShape, Type: Type1C, Condition: HasValue(tag.PresentationIntentType, "FOR PRESENTATION"), Enumerated: enumerated("IDENTITY", "INVERSE")},
+		{Tag: tag.LossyImageCompression, Type: Type1, Enumerated: enumerated("00", "01")},
+		{Tag: tag.BurnedInAnnotation, Type: Type1, Enumerated: enumerated("YES", "NO")},
+	},
+}
+
+// DXDetectorModule is the DX Detector module, PS3.3 C.8.11.4
+var DXDetectorModule = &Module{
+	Name: "DX Detector",
+	Attributes: []Attribute{
+		{Tag: tag.DetectorType, Type: Type2, Enumerated: enumerated("DIRECT", "SCINTILLATOR", "STORAGE", "FILM")},
+		{Tag: tag.ImagerPixelSpacing, Type: Type1},
+	},
+}
+
+// MammographySeriesModule is the Mammography Series module, PS3.3 C.8.11.6
+var MammographySeriesModule = &Module{
+	Name: "Mammography Series",
+	Attributes: []Attribute{
+		{Tag: tag.Modality, Type: Type1, Enumerated: enumerated("MG")},
+	},
+}
+
+// MammographyImageModule is the Mammography Image module, PS3.3 C.8.11.7
+var MammographyImageModule = &Module{
+	Name: "Mammography Image",
+	Attributes: []Attribute{
+		{Tag: tag.ImageType, Type: Type1, Enumerated: imageType},
+		{Tag: tag.PositionerType, Type: Type1, Enumerated: enumerated("MAMMOGRAPHIC", "NONE")},
+		{Tag: tag.ImageLaterality, Type: Type1, Enumerated: enumerated("R", "L", "B")},
+		{Tag: tag.ViewCodeSequence, Type: Type1, Items: codeSequenceMacro},
+	},
+}
+
+// USImageModule is the US Image module, PS3.3 C.8.5.6
+var USImageModule = &Module{
+	Name: "US Image",
+	Attributes: []Attribute{
+		{Tag: tag.SamplesPerPixel, Type: Type1, Enumerated: enumerated("1", "3")},
+		{Tag: tag.PhotometricInterpretation, Type: Type1, Enumerated: enumerated("MONOCHROME2", "PALETTE COLOR", "RGB",
+			"YBR_FULL", "YBR_FULL_422", "YBR_PARTIAL_422", "YBR_PARTIAL_420", "YBR_RCT", "YBR_ICT")},
+		{Tag: tag.BitsAllocated, Type: Type1, Enumerated: enumerated("8", "16")},
+		{Tag: tag.BitsStored, Type: Type1},
+		{Tag: tag.HighBit, Type: Type1},
+		{Tag: tag.PlanarConfiguration, Type: Type1C, Condition: multipleSamples, Enumerated: enumerated("0", "1")},
+		{Tag: tag.PixelRepresentation, Type: Type1, Enumerated: enumerated("0")},
+		{Tag: tag.ImageType, Type: Type2, Enumerated: imageType},
+		{Tag: tag.LossyImageCompression, Type: Type1C, Enumerated: enumerated("00", "01")},
+	},
+}
+
+// SCEquipmentModule is the SC Equipment module, PS3.3 C.8.6.1
+var SCEquipmentModule = &Module{
+	Name: "SC Equipment",
+	Attributes: []Attribute{
+		{Tag: tag.ConversionType, Type: Type1},
+	},
+}
+
+// SegmentationSeriesModule is the Segmentation Series module, PS3.3 C.8.20.1
+var SegmentationSeriesModule = &Module{
+	Name: "Segmentation Series",
+	Attributes: []Attribute{
+		{Tag: tag.Modality, Type: Type1, Enumerated: enumerated("SEG")},
+		{Tag: tag.SeriesNumber, Type: Type1},
+		{Tag: tag.ReferencedPerformedProcedureStepSequence, Type: Type1C},
+	},
+}
+
+// SegmentationImageModule is the Segmentation Image module, PS3.3 C.8.20.2
+var SegmentationImageModule = &Module{
+	Name: "Segmentation Image",
+	Attributes: []Attribute{
+		{Tag: tag.ImageType, Type: Type1, Enumerated: []Enumeration{{Value: 1, Values: []string{"DERIVED"}}, {Value: 2, Values: []string{"PRIMARY"}}}},
+		{Tag: tag.InstanceNumber, Type: Type1},
+		{Tag: tag.ContentLabel, Type: Type1},
+		{Tag: tag.ContentDescription, Type: Type2},
+		{Tag: tag.ContentCreatorName, Type: Type2},
+		{Tag: tag.SamplesPerPixel, Type: Type1, Enumerated: enumerated("1")},
+		{Tag: tag.PhotometricInterpretation, Type: Type1, Enumerated: enumerated("MONOCHROME2", "PALETTE COLOR")},
+		{Tag: tag.PixelRepresentation, Type: Type1, Enumerated: enumerated("0")},
+		{Tag: tag.BitsAllocated, Type: Type1, Enumerated: enumerated("1", "8", "16")},
+		{Tag: tag.BitsStored, Type: Type1},
+		{Tag: tag.HighBit, Type: Type1},
+		{Tag: tag.LossyImageCompression, Type: Type1, Enumerated: enumerated("00", "01")},
+		{Tag: tag.SegmentationType, Type: Type1, Enumerated: enumerated("BINARY", "FRACTIONAL", "LABELMAP")},
+		{Tag: tag.SegmentationFractionalType, Type: Type1C, Condition: HasValue(tag.SegmentationType, "FRACTIONAL"), Enumerated: enumerated("PROBABILITY", "OCCUPANCY")},
+		{Tag: tag.MaximumFractionalValue, Type: Type1C, Condition: HasValue(tag.SegmentationType, "FRACTIONAL")},
+		{Tag: tag.SegmentsOverlap, Type: Type3, Enumerated: enumerated("YES", "UNDEFINED", "NO")},
+		{Tag: tag.SegmentSequence, Type: Type1, Items: []Attribute{
+			{Tag: tag.SegmentNumber, Type: Type1},
+			{Tag: tag.SegmentLabel, Type: Type1},
+			{Tag: tag.SegmentAlgorithmType, Type: Type1, Enumerated: enumerated("AUTOMATIC", "SEMIAUTOMATIC", "MANUAL")},
+			{Tag: tag.SegmentAlgorithmName, Type: Type1C, Condition: And(Present(tag.SegmentAlgorithmType), Not(HasValue(tag.SegmentAlgorithmType, "MANUAL")))},
+			{Tag: tag.SegmentedPropertyCategoryCodeSequence, Type: Type1, Items: codeSequenceMacro},
+			{Tag: tag.SegmentedPropertyTypeCodeSequence, Type: Type1, Items: codeSequenceMacro},
+		}},
+	},
+}
+
+// SRDocumentSeriesModule is the SR Document Series module, PS3.3 C.17.1
+var SRDocumentSeriesModule = &Module{
+	Name: "SR Document Series",
+	Attributes: []Attribute{
+		{Tag: tag.Modality, Type: Type1, Enumerated: enumerated("SR")},
+		{Tag: tag.SeriesInstanceUID, Type: Type1},
+		{Tag: tag.SeriesNumber, Type: Type1},
+		{Tag: tag.ReferencedPerformedProcedureStepSequence, Type: Type2},
+	},
+}
+
+// SRDocumentGeneralModule is the SR Document General module, PS3.3 C.17.2
+var SRDocumentGeneralModule = &Module{
+	Name: "SR Document General",
+	Attributes: []Attribute{
+		{Tag: tag.InstanceNumber, Type: Type1},
+		{Tag: tag.CompletionFlag, Type: Type1, Enumerated: enumerated("PARTIAL", "COMPLETE")},
+		{Tag: tag.VerificationFlag, Type: Type1, Enumerated: enumerated("UNVERIFIED", "VERIFIED")},
+		{Tag: tag.ContentDate, Type: Type1},
+		{Tag: tag.ContentTime, Type: Type1},
+		{Tag: tag.VerifyingObserverSequence, Type: Type1C, Condition: HasValue(tag.VerificationFlag, "VERIFIED"), Items: []Attribute{
+			{Tag: tag.VerifyingObserverName, Type: Type1},
+			{Tag: tag.VerifyingOrganization, Type: Type2},
+			{Tag: tag.VerificationDateTime, Type: Type1},
+		}},
+		{Tag: tag.PerformedProcedureCodeSequence, Type: Type2, Items: codeSequenceMacro},
+	},
+}
+
+// SRDocumentContentModule is the SR Document Content module, PS3.3 C.17.3, limited to the root content item
+var SRDocumentContentModule = &Module{
+	Name: "SR Document Content",
+	Attributes: []Attribute{
+		{Tag: tag.ValueType, Type: Type1, Enumerated: enumerated("CONTAINER")},
+		{Tag: tag.ConceptNameCodeSequence, Type: Type1, Items: codeSequenceMacro},
+		{Tag: tag.ContinuityOfContent, Type: Type1, Enumerated: enumerated("SEPARATE", "CONTINUOUS")},
+		{Tag: tag.ContentSequence, Type: Type1C},
+	},
+}
+
+// RTSeriesModule is the RT Series module, PS3.3 C.8.8.1
+var RTSeriesModule = &Module{
+	Name: "RT Series",
+	Attributes: []Attribute{
+		{Tag: tag.Modality, Type: Type1, Enumerated: enumerated("RTIMAGE", "RTDOSE", "RTSTRUCT", "RTPLAN", "RTRECORD")},
+		{Tag: tag.SeriesInstanceUID, Type: Type1},
+		{Tag: tag.SeriesNumber, Type: Type2},
+	},
+}
+
+// StructureSetModule is the Structure Set module, PS3.3 C.8.8.5
+var StructureSetModule = &Module{
+	Name: "Structure Set",
+	Attributes: []Attribute{
+		{Tag: tag.StructureSetLabel, Type: Type1},
+		{Tag: tag.StructureSetDate, Type: Type2},
+		{Tag: tag.StructureSetTime, Type: Type2},
+		{Tag: tag.StructureSetROISequence, Type: Type1, Items: []Attribute{
+			{Tag: tag.ROINumber, Type: Type1},
+			{Tag: tag.ReferencedFrameOfReferenceUID, Type: Type1},
+			{Tag: tag.ROIName, Type: Type2},
+			{Tag: tag.ROIGenerationAlgorithm, Type: Type2, Enumerated: enumerated("AUTOMATIC", "SEMIAUTOMATIC", "MANUAL")},
+		}},
+	},
+}
+
+// ROIContourModule is the ROI Contour module, PS3.3 C.8.8.6
+var ROIContourModule = &Module{
+	Name: "ROI Contour",
+	Attributes: []Attribute{
+		{Tag: tag.ROIContourSequence, Type: Type1, Items: []Attribute{
+			{Tag: tag.ReferencedROINumber, Type: Type1},
+			{Tag: tag.ContourSequence, Type: Type3, Items: []Attribute{
+				{Tag: tag.ContourGeometricType, Type: Type1, Enumerated: enumerated("POINT", "OPEN_PLANAR", "OPEN_NONPLANAR", "CLOSED_PLANAR")},
+				{Tag: tag.NumberOfContourPoints, Type: Type1},
+				{Tag: tag.ContourData, Type: Type1},
+			}},
+		}},
+	},
+}
+
+// RTROIObservationsModule is the RT ROI Observations module, PS3.3 C.8.8.8
+var RTROIObservationsModule = &Module{
+	Name: "RT ROI Observations",
+	Attributes: []Attribute{
+		{Tag: tag.RTROIObservationsSequence, Type: Type1, Items: []Attribute{
+			{Tag: tag.ObservationNumber, Type: Type1},
+			{Tag: tag.ReferencedROINumber, Type: Type1},
+			{Tag: tag.RTROIInterpretedType, Type: Type2},
+			{Tag: tag.ROIInterpreter, Type: Type2},
+		}},
+	},
+}
+
+// multipleSamples is the condition of the planar configuration, required for more than one sample per pixel
+var multipleSamples = And(Present(tag.SamplesPerPixel), Not(HasValue(tag.SamplesPerPixel, "1")))
+
+// imageType holds the enumerated values of the first value of Image Type, the pixel data characteristics, and of the
+// second value, the patient examination characteristics
+var imageType = []Enumeration{
+	{Value: 1, Values: []string{"ORIGINAL", "DERIVED"}},
+	{Value: 2, Values: []string{"PRIMARY", "SECONDARY"}},
+}
+
+func enumerated(values ...string) []Enumeration {
+	return []Enumeration{{Values: values}}
+}
@@ -1,16 +1,14 @@
 package iod
 
 import (
-	"bufio"
-	"bytes"
+	"encoding/binary"
 	"fmt"
-	"github.com/okieraised/go2com/pkg/dicom/dcm_io"
+	"github.com/okieraised/go2com"
 	"github.com/okieraised/go2com/pkg/dicom/tag"
 	"github.com/okieraised/go2com/pkg/dicom/uid"
-	"io"
 )
 
-type PixelDataMacro map[tag.DicomTag]*dcm_io.Element
+type PixelDataMacro map[tag.DicomTag]*go2com.Element
 
 // GetPixelDataMacroAttributes retrieves the tags and values corresponding to the PixelData macro
 //    +------------------------------------------------+
@@ -42,7 +40,7 @@ type PixelDataMacro map[tag.DicomTag]*dcm_io.Element
 //    +-------------+---------------------------+------+
 //    | (7FE0,0010) | PixelData                 | 1C   |
 //    +-------------+---------------------------+------+
-func GetPixelDataMacroAttributes(ds, meta dcm_io.Dataset) PixelDataMacro {
+func GetPixelDataMacroAttributes(ds, meta go2com.Dataset) PixelDataMacro {
 	res := make(PixelDataMacro, 0)
 	for _, elem := range meta.Elements {
 		if elem.Tag == tag.TransferSyntaxUID {
@@ -122,66 +120,33 @@ func (px PixelDataMacro) ReadEncapsulatedPixelData() ([]byte, error) {
 		return nil, fmt.Errorf("cannot convert pixel data to byte array")
 	}
 
-	if uid.UncompressedSyntax[px[tag.TransferSyntaxUID].Value.RawValue.(string)] {
+	if ts, err := uid.TransferSyntaxInfo(px[tag.TransferSyntaxUID].Value.RawValue.(string)); err == nil && !ts.Encapsulated {
 		return rawPixel, nil
 	}
 
-	bufRd := bufio.NewReaderSize(bytes.NewReader(rawPixel), int(pixelDataElem.ValueLength))
-	pixReader := dcm_io.NewDICOMReader(bufRd, dcm_io.WithSkipPixelData(true))
-	actualPixelData := make([]byte, 0, int(pixelDataElem.ValueLength))
+	// The fragments are items of little endian tag and value length, ended by the sequence delimitation item
+	actualPixelData := make([]byte, 0, len(rawPixel))
 	index := 0
-	for {
-		var tGroup, tElem uint16
-		tGroup, err := pixReader.readUInt16()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
-		}
-		tElem, err = pixReader.readUInt16()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
-		}
-
+	for offset := 0; offset+8 <= len(rawPixel); {
 		tTag := tag.DicomTag{
-			Group:   tGroup,
-			Element: tElem,
+			Group:   binary.LittleEndian.Uint16(rawPixel[offset:]),
+			Element: binary.LittleEndian.Uint16(rawPixel[offset+2:]),
 		}
 
 		if tTag == tag.SequenceDelimitationItem {
 			break
 		}
 
-		tValueLength, err := pixReader.ReadUInt32()
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
-		}
-
-		rawValue, err := pixReader.peek(int(tValueLength))
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
+		tValueLength := int(binary.LittleEndian.Uint32(rawPixel[offset+4:]))
+		offset += 8
+		if offset+tValueLength > len(rawPixel) {
+			return nil, fmt.Errorf("pixel data fragment %d of length %d exceeds the pixel data", index, tValueLength)
 		}
 		// The first item is the basic offset table, so we skip it
 		if index > 0 {
-			actualPixelData = append(actualPixelData, rawValue...)
-		}
-		_, err = pixReader.discard(int(tValueLength))
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return nil, err
+			actualPixelData = append(actualPixelData, rawPixel[offset:offset+tValueLength]...)
 		}
+		offset += tValueLength
 		index++
 	}
 
@@ -209,7 +174,7 @@ func (px PixelDataMacro) ValidatePixelData() bool {
 		return false
 	}
 
-	if uid.UncompressedSyntax[transferSyntax] {
+	if ts, err := uid.TransferSyntaxInfo(transferSyntax); err == nil && !ts.Encapsulated {
 		if expected != len(actual) {
 			return false
 		}
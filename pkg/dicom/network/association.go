@@ -0,0 +1,620 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+const (
+	// ImplementationClassUID identifies go2com in the association negotiation
+	ImplementationClassUID = go2com.ImplementationClassUID
+	// ImplementationVersionName identifies the go2com version in the association negotiation
+	ImplementationVersionName = go2com.ImplementationVersionName
+	// DefaultARTIMTimeout is the default Association Request/Reject/Release Timer value
+	DefaultARTIMTimeout = 30 * time.Second
+	// defaultFragmentLength is the PDV fragment size used when the peer does not limit the PDU length
+	defaultFragmentLength = 1 << 20
+)
+
+var (
+	// ErrReleaseRequested is returned when the peer requested the release of the association
+	ErrReleaseRequested = errors.New("network: association release requested by peer")
+	// ErrAssociationClosed is returned when using an association that was released or aborted
+	ErrAssociationClosed = errors.New("network: association closed")
+)
+
+// defaultTransferSyntaxes are the uncompressed transfer syntaxes proposed or accepted when none is configured
+var defaultTransferSyntaxes = []string{uid.ExplicitVRLittleEndian, uid.ImplicitVRLittleEndian}
+
+// AbortError is returned when the peer aborted the association
+type AbortError struct {
+	Source byte
+	Reason byte
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("network: association aborted by peer (source=%d, reason=%d)", e.Source, e.Reason)
+}
+
+// RejectedError is returned when the association acceptor rejected the association
+type RejectedError struct {
+	Result byte
+	Source byte
+	Reason byte
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("network: association rejected (result=%d, source=%d, reason=%d)", e.Result, e.Source, e.Reason)
+}
+
+// PresentationContext is a negotiated presentation context
+type PresentationContext struct {
+	ID             byte
+	AbstractSyntax string
+	TransferSyntax string
+	Result         byte
+	// SCURole and SCPRole are the roles of the association requestor negotiated with SCP/SCU role selection.
+	// Without role selection, the requestor is the SCU
+	SCURole bool
+	SCPRole bool
+}
+
+// Accepted returns true if the presentation context was accepted
+func (pc *PresentationContext) Accepted() bool {
+	return pc.Result == pdu.ResultAcceptance
+}
+
+// Message is a DIMSE message received on an association
+type Message struct {
+	PresentationContextID byte
+	Command               *Command
+	// Data is the data set encoded in the transfer syntax of the presentation context. Nil if the message has no data set
+	Data []byte
+}
+
+// Association is an established DICOM association
+type Association struct {
+	conn             net.Conn
+	isRequestor      bool
+	calledAETitle    string
+	callingAETitle   string
+	contexts         map[byte]*PresentationContext
+	maxPDULength     uint32
+	peerMaxPDULength uint32
+	peerImplClassUID string
+	peerImplVersion  string
+	artimTimeout     time.Duration
+	pending          []pdu.PDV
+	writeMu          sync.Mutex
+	messageID        uint32
+	closed           int32
+
+	// operations holds the cancel functions of the requests processed in the background, by message ID
+	operationsMu sync.Mutex
+	operations   map[uint16]context.CancelFunc
+	operationsWG sync.WaitGroup
+	// dispatched is true when the messages are read by the serving loop of a Server, which dispatches
+	// the responses to the requests sent by the background operations
+	dispatched bool
+	responses  map[uint16]*responseQueue
+	// stopped is true once the operations are stopped, no response can be dispatched anymore
+	stopped bool
+}
+
+// CalledAETitle returns the AE title of the association acceptor
+func (a *Association) CalledAETitle() string {
+	return a.calledAETitle
+}
+
+// CallingAETitle returns the AE title of the association requestor
+func (a *Association) CallingAETitle() string {
+	return a.callingAETitle
+}
+
+// RemoteAddr returns the network address of the peer
+func (a *Association) RemoteAddr() net.Addr {
+	return a.conn.RemoteAddr()
+}
+
+// PeerImplementation returns the implementation class UID and version name sent by the peer
+func (a *Association) PeerImplementation() (string, string) {
+	return a.peerImplClassUID, a.peerImplVersion
+}
+
+// PeerMaxPDULength returns the maximum PDU length the peer accepts. Zero means unlimited
+func (a *Association) PeerMaxPDULength() uint32 {
+	return a.peerMaxPDULength
+}
+
+// PresentationContexts returns all negotiated presentation contexts, accepted or not
+func (a *Association) PresentationContexts() []PresentationContext {
+	res := make([]PresentationContext, 0, len(a.contexts))
+	for id := 1; id < 256; id += 2 {
+		if pc, ok := a.contexts[byte(id)]; ok {
+			res = append(res, *pc)
+		}
+	}
+	return res
+}
+
+// FindPresentationContext returns the first accepted presentation context for the abstract syntax.
+// If transfer syntaxes are given, the context must use one of them
+func (a *Association) FindPresentationContext(abstractSyntax string, transferSyntaxes ...string) (*PresentationContext, error) {
+	for _, pc := range a.PresentationContexts() {
+		if !pc.Accepted() || pc.AbstractSyntax != abstractSyntax {
+			continue
+		}
+		if len(transferSyntaxes) == 0 {
+			return a.contexts[pc.ID], nil
+		}
+		for _, ts := range transferSyntaxes {
+			if pc.TransferSyntax == ts {
+				return a.contexts[pc.ID], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("network: no accepted presentation context for abstract syntax %s", abstractSyntax)
+}
+
+func (a *Association) nextMessageID() uint16 {
+	id := uint16(atomic.AddUint32(&a.messageID, 1))
+	if id == 0 {
+		id = uint16(atomic.AddUint32(&a.messageID, 1))
+	}
+	return id
+}
+
+// watch applies the context deadline and cancellation to the underlying connection.
+// The returned function must be called when the operation is done. The connection of an association served by
+// a Server is shared with the serving loop and is left untouched
+func (a *Association) watch(ctx context.Context) func() {
+	if a.dispatched {
+		return func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = a.conn.SetDeadline(deadline)
+	}
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = a.conn.SetDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	return func() {
+		close(done)
+		_ = a.conn.SetDeadline(time.Time{})
+	}
+}
+
+// startOperation processes a request in the background, so that the association can still receive the
+// C-CANCEL request for its message ID while the responses are sent
+func (a *Association) startOperation(ctx context.Context, messageID uint16, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(ctx)
+	a.operationsMu.Lock()
+	if a.operations == nil {
+		a.operations = make(map[uint16]context.CancelFunc)
+	}
+	a.operations[messageID] = cancel
+	a.operationsMu.Unlock()
+
+	a.operationsWG.Add(1)
+	go func() {
+		defer a.operationsWG.Done()
+		defer func() {
+			a.operationsMu.Lock()
+			delete(a.operations, messageID)
+			a.operationsMu.Unlock()
+			cancel()
+		}()
+		fn(ctx)
+	}()
+}
+
+// cancelOperation cancels the background processing of the request with the message ID, if any
+func (a *Association) cancelOperation(messageID uint16) {
+	a.operationsMu.Lock()
+	defer a.operationsMu.Unlock()
+	if cancel, ok := a.operations[messageID]; ok {
+		cancel()
+	}
+}
+
+// stopOperations cancels the requests processed in the background and waits for them
+func (a *Association) stopOperations() {
+	a.operationsMu.Lock()
+	for _, cancel := range a.operations {
+		cancel()
+	}
+	for messageID, q := range a.responses {
+		q.close()
+		delete(a.responses, messageID)
+	}
+	a.stopped = true
+	a.operationsMu.Unlock()
+	a.operationsWG.Wait()
+}
+
+// expectResponse prepares the reception of the response to the request with the message ID and returns the
+// function waiting for it, called again for the next response while the responses are pending, e.g.: of a C-FIND
+// request. It must be called before sending the request
+func (a *Association) expectResponse(messageID uint16) func() (*Message, error) {
+	if !a.dispatched {
+		return func() (*Message, error) {
+			return a.readResponse(messageID)
+		}
+	}
+	q := &responseQueue{ready: make(chan struct{}, 1)}
+	a.operationsMu.Lock()
+	switch {
+	case a.stopped:
+		q.close()
+	case a.responses == nil:
+		a.responses = map[uint16]*responseQueue{messageID: q}
+	default:
+		a.responses[messageID] = q
+	}
+	a.operationsMu.Unlock()
+	return q.next
+}
+
+// dispatchResponse reads the data set of a response received by the serving loop and hands the response to
+// the operation waiting for it. Unexpected responses are discarded
+func (a *Association) dispatchResponse(pcID byte, cmd *Command) error {
+	msg := &Message{PresentationContextID: pcID, Command: cmd}
+	if cmd.HasDataset {
+		buf := bytes.Buffer{}
+		err := a.readData(pcID, &buf)
+		if err != nil {
+			return err
+		}
+		msg.Data = buf.Bytes()
+	}
+	a.operationsMu.Lock()
+	defer a.operationsMu.Unlock()
+	if q, ok := a.responses[cmd.MessageIDBeingRespondedTo]; ok {
+		if !IsPendingStatus(cmd.Status) {
+			delete(a.responses, cmd.MessageIDBeingRespondedTo)
+		}
+		q.push(msg)
+	}
+	return nil
+}
+
+// responseQueue holds the responses dispatched to a request until the operation reads them, so that the serving
+// loop never waits for the operation
+type responseQueue struct {
+	mu       sync.Mutex
+	messages []*Message
+	closed   bool
+	// ready is signaled when a response is pushed or the queue is closed
+	ready chan struct{}
+}
+
+func (q *responseQueue) push(msg *Message) {
+	q.mu.Lock()
+	q.messages = append(q.messages, msg)
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *responseQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.signal()
+}
+
+func (q *responseQueue) signal() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+// next waits for the next response, or returns ErrAssociationClosed once the queue is closed and empty
+func (q *responseQueue) next() (*Message, error) {
+	for {
+		q.mu.Lock()
+		if len(q.messages) > 0 {
+			msg := q.messages[0]
+			q.messages = q.messages[1:]
+			q.mu.Unlock()
+			return msg, nil
+		}
+		closed := q.closed
+		q.mu.Unlock()
+		if closed {
+			return nil, ErrAssociationClosed
+		}
+		<-q.ready
+	}
+}
+
+func (a *Association) isClosed() bool {
+	return atomic.LoadInt32(&a.closed) == 1
+}
+
+func (a *Association) close() error {
+	if !atomic.CompareAndSwapInt32(&a.closed, 0, 1) {
+		return nil
+	}
+	return a.conn.Close()
+}
+
+func (a *Association) writePDU(p pdu.PDU) error {
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	if a.isClosed() {
+		return ErrAssociationClosed
+	}
+	return pdu.Write(a.conn, p)
+}
+
+// readPDU reads the next PDU and translates the A-ABORT PDU into an error
+func (a *Association) readPDU() (pdu.PDU, error) {
+	p, err := pdu.Read(a.conn, a.maxPDULength)
+	if err != nil {
+		var unrecognized *pdu.UnrecognizedPDUError
+		if errors.As(err, &unrecognized) {
+			_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonUnrecognizedPDU)
+		}
+		return nil, err
+	}
+	if ab, ok := p.(*pdu.Abort); ok {
+		_ = a.close()
+		return nil, &AbortError{Source: ab.Source, Reason: ab.Reason}
+	}
+	return p, nil
+}
+
+// nextPDV returns the next presentation data value, reading a new P-DATA-TF PDU if needed
+func (a *Association) nextPDV() (pdu.PDV, error) {
+	for len(a.pending) == 0 {
+		p, err := a.readPDU()
+		if err != nil {
+			return pdu.PDV{}, err
+		}
+		switch v := p.(type) {
+		case *pdu.PDataTF:
+			a.pending = v.Items
+		case *pdu.ReleaseRQ:
+			return pdu.PDV{}, ErrReleaseRequested
+		default:
+			_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonUnexpectedPDU)
+			return pdu.PDV{}, fmt.Errorf("network: unexpected %s while waiting for P-DATA-TF", p.Type())
+		}
+	}
+	pdv := a.pending[0]
+	a.pending = a.pending[1:]
+	if _, ok := a.contexts[pdv.PresentationContextID]; !ok {
+		_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonInvalidPDUParameterValue)
+		return pdu.PDV{}, fmt.Errorf("network: unknown presentation context id %d", pdv.PresentationContextID)
+	}
+	return pdv, nil
+}
+
+// readCommand reads the command fragments of the next DIMSE message
+func (a *Association) readCommand() (byte, *Command, error) {
+	buf := bytes.Buffer{}
+	var pcID byte
+	for {
+		pdv, err := a.nextPDV()
+		if err != nil {
+			return 0, nil, err
+		}
+		if !pdv.IsCommand {
+			return 0, nil, fmt.Errorf("network: unexpected data set fragment while reading command")
+		}
+		if buf.Len() > 0 && pdv.PresentationContextID != pcID {
+			return 0, nil, fmt.Errorf("network: command fragments with different presentation contexts")
+		}
+		pcID = pdv.PresentationContextID
+		buf.Write(pdv.Data)
+		if pdv.IsLast {
+			break
+		}
+	}
+	cmd, err := DecodeCommand(buf.Bytes())
+	if err != nil {
+		return 0, nil, err
+	}
+	return pcID, cmd, nil
+}
+
+// readData streams the data set fragments of the current DIMSE message to the writer
+func (a *Association) readData(pcID byte, w io.Writer) error {
+	for {
+		pdv, err := a.nextPDV()
+		if err != nil {
+			return err
+		}
+		if pdv.IsCommand {
+			return fmt.Errorf("network: unexpected command fragment while reading data set")
+		}
+		if pdv.PresentationContextID != pcID {
+			return fmt.Errorf("network: data set fragment with presentation context %d, expected %d", pdv.PresentationContextID, pcID)
+		}
+		_, err = w.Write(pdv.Data)
+		if err != nil {
+			return err
+		}
+		if pdv.IsLast {
+			return nil
+		}
+	}
+}
+
+// readMessage reads the next DIMSE message and its data set, if any
+func (a *Association) readMessage() (*Message, error) {
+	pcID, cmd, err := a.readCommand()
+	if err != nil {
+		return nil, err
+	}
+	msg := &Message{PresentationContextID: pcID, Command: cmd}
+	if cmd.HasDataset {
+		buf := bytes.Buffer{}
+		err = a.readData(pcID, &buf)
+		if err != nil {
+			return nil, err
+		}
+		msg.Data = buf.Bytes()
+	}
+	return msg, nil
+}
+
+func (a *Association) fragmentLength() int {
+	// A maximum length too small to carry a PDV is not meaningful and is handled as unlimited
+	if a.peerMaxPDULength <= pdu.PDVHeaderLength || a.peerMaxPDULength > defaultFragmentLength {
+		return defaultFragmentLength
+	}
+	return int(a.peerMaxPDULength) - pdu.PDVHeaderLength
+}
+
+// writeMessage sends the command and streams the data set, if not nil, in P-DATA-TF fragments
+// no longer than the maximum PDU length of the peer
+func (a *Association) writeMessage(pcID byte, cmd *Command, data io.Reader) error {
+	cmd.HasDataset = data != nil
+	fragment := a.fragmentLength()
+	b := cmd.Encode()
+	for len(b) > 0 {
+		n := len(b)
+		if n > fragment {
+			n = fragment
+		}
+		err := a.writePDU(&pdu.PDataTF{Items: []pdu.PDV{{
+			PresentationContextID: pcID,
+			IsCommand:             true,
+			IsLast:                n == len(b),
+			Data:                  b[:n],
+		}}})
+		if err != nil {
+			return err
+		}
+		b = b[n:]
+	}
+	if data == nil {
+		return nil
+	}
+
+	// Read one fragment ahead to know which fragment is the last one
+	current := make([]byte, fragment)
+	next := make([]byte, fragment)
+	n, err := io.ReadFull(data, current)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return err
+	}
+	for {
+		var m int
+		if err == nil {
+			m, err = io.ReadFull(data, next)
+			if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+				return err
+			}
+		}
+		isLast := m == 0
+		err2 := a.writePDU(&pdu.PDataTF{Items: []pdu.PDV{{
+			PresentationContextID: pcID,
+			IsLast:                isLast,
+			Data:                  current[:n],
+		}}})
+		if err2 != nil {
+			return err2
+		}
+		if isLast {
+			return nil
+		}
+		current, next = next, current
+		n = m
+	}
+}
+
+// Release requests the release of the association and waits for the peer confirmation
+// at most for the ARTIM timeout before closing the connection
+func (a *Association) Release() error {
+	if a.isClosed() {
+		return ErrAssociationClosed
+	}
+	defer a.close()
+	err := a.writePDU(&pdu.ReleaseRQ{})
+	if err != nil {
+		return err
+	}
+	_ = a.conn.SetReadDeadline(time.Now().Add(a.artimTimeout))
+	for {
+		p, err := a.readPDU()
+		if err != nil {
+			return err
+		}
+		switch p.(type) {
+		case *pdu.ReleaseRP:
+			return nil
+		case *pdu.ReleaseRQ:
+			// Release collision: the requestor sends the A-RELEASE-RP first
+			if a.isRequestor {
+				_ = a.writePDU(&pdu.ReleaseRP{})
+			}
+		}
+	}
+}
+
+// Abort aborts the association and closes the connection
+func (a *Association) Abort() error {
+	return a.abort(pdu.AbortSourceServiceUser, pdu.AbortReasonNotSpecified)
+}
+
+func (a *Association) abort(source, reason byte) error {
+	err := a.writePDU(&pdu.Abort{Source: source, Reason: reason})
+	_ = a.close()
+	if err == ErrAssociationClosed {
+		return nil
+	}
+	return err
+}
+
+// replyRelease confirms the release requested by the peer and closes the connection
+func (a *Association) replyRelease() error {
+	err := a.writePDU(&pdu.ReleaseRP{})
+	_ = a.close()
+	return err
+}
+
+// sendResponse sends a response without data set to the given request
+func (a *Association) sendResponse(pcID byte, req *Command, status uint16) error {
+	return a.writeMessage(pcID, responseTo(req, status), nil)
+}
+
+// responseTo returns the response command to the request with the given status
+func responseTo(req *Command, status uint16) *Command {
+	return &Command{
+		CommandField:              req.CommandField | 0x8000,
+		AffectedSOPClassUID:       req.AffectedSOPClassUID,
+		AffectedSOPInstanceUID:    req.AffectedSOPInstanceUID,
+		MessageIDBeingRespondedTo: req.MessageID,
+		Status:                    status,
+	}
+}
+
+// readResponse waits for the response to the request with the given message ID
+func (a *Association) readResponse(messageID uint16) (*Message, error) {
+	msg, err := a.readMessage()
+	if err != nil {
+		return nil, err
+	}
+	if !msg.Command.IsResponse() || msg.Command.MessageIDBeingRespondedTo != messageID {
+		return nil, fmt.Errorf("network: unexpected %s while waiting for response to message %d", msg.Command, messageID)
+	}
+	return msg, nil
+}
@@ -0,0 +1,338 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+// Query/Retrieve levels of the QueryRetrieveLevel attribute
+const (
+	PatientLevel = "PATIENT"
+	StudyLevel   = "STUDY"
+	SeriesLevel  = "SERIES"
+	ImageLevel   = "IMAGE"
+)
+
+// Query is a typed C-FIND identifier. For Query/Retrieve, the attributes of the query level are included as
+// matching and return keys, together with the unique keys of the levels above and, at the study level, the
+// patient attributes. Without level, the query is a Modality Worklist query. Empty attributes are universal
+// matches whose values are returned by the SCP
+type Query struct {
+	Level string
+
+	PatientName      string
+	PatientID        string
+	PatientBirthDate string
+	PatientSex       string
+
+	StudyInstanceUID       string
+	StudyDate              string
+	StudyTime              string
+	AccessionNumber        string
+	StudyID                string
+	StudyDescription       string
+	ReferringPhysicianName string
+	ModalitiesInStudy      string
+
+	SeriesInstanceUID string
+	Modality          string
+	SeriesNumber      string
+	SeriesDescription string
+
+	SOPInstanceUID string
+	SOPClassUID    string
+	InstanceNumber string
+
+	RequestedProcedureID          string
+	RequestedProcedureDescription string
+	// ScheduledProcedureStep holds the Scheduled Procedure Step keys of a Modality Worklist query
+	ScheduledProcedureStep *ScheduledProcedureStep
+
+	// Keys holds additional matching or return keys
+	Keys []*go2com.Element
+}
+
+// ScheduledProcedureStep holds the keys of the Scheduled Procedure Step Sequence of a Modality Worklist query
+type ScheduledProcedureStep struct {
+	StationAETitle          string
+	StartDate               string
+	StartTime               string
+	Modality                string
+	PerformingPhysicianName string
+	Description             string
+	ID                      string
+}
+
+// Identifier returns the C-FIND identifier of the query, sorted by tag
+func (q *Query) Identifier() go2com.Dataset {
+	elements := make([]*go2com.Element, 0)
+	add := func(t tag.DicomTag, value string) {
+		elements = append(elements, go2com.NewElement(t, value))
+	}
+	addPatient := func() {
+		add(tag.PatientName, q.PatientName)
+		add(tag.PatientID, q.PatientID)
+		add(tag.PatientBirthDate, q.PatientBirthDate)
+		add(tag.PatientSex, q.PatientSex)
+	}
+
+	switch q.Level {
+	case "":
+		addPatient()
+		add(tag.StudyInstanceUID, q.StudyInstanceUID)
+		add(tag.AccessionNumber, q.AccessionNumber)
+		add(tag.ReferringPhysicianName, q.ReferringPhysicianName)
+		add(tag.RequestedProcedureID, q.RequestedProcedureID)
+		add(tag.RequestedProcedureDescription, q.RequestedProcedureDescription)
+		sps := q.ScheduledProcedureStep
+		if sps == nil {
+			sps = &ScheduledProcedureStep{}
+		}
+		elements = append(elements, go2com.NewElement(tag.ScheduledProcedureStepSequence, sortElements([]*go2com.Element{
+			go2com.NewElement(tag.ScheduledStationAETitle, sps.StationAETitle),
+			go2com.NewElement(tag.ScheduledProcedureStepStartDate, sps.StartDate),
+			go2com.NewElement(tag.ScheduledProcedureStepStartTime, sps.StartTime),
+			go2com.NewElement(tag.Modality, sps.Modality),
+			go2com.NewElement(tag.ScheduledPerformingPhysicianName, sps.PerformingPhysicianName),
+			go2com.NewElement(tag.ScheduledProcedureStepDescription, sps.Description),
+			go2com.NewElement(tag.ScheduledProcedureStepID, sps.ID),
+		})))
+	case PatientLevel:
+		addPatient()
+	case StudyLevel:
+		addPatient()
+		add(tag.StudyInstanceUID, q.StudyInstanceUID)
+		add(tag.StudyDate, q.StudyDate)
+		add(tag.StudyTime, q.StudyTime)
+		add(tag.AccessionNumber, q.AccessionNumber)
+		add(tag.StudyID, q.StudyID)
+		add(tag.StudyDescription, q.StudyDescription)
+		add(tag.ReferringPhysicianName, q.ReferringPhysicianName)
+		add(tag.ModalitiesInStudy, q.ModalitiesInStudy)
+	case SeriesLevel:
+		add(tag.PatientID, q.PatientID)
+		add(tag.StudyInstanceUID, q.StudyInstanceUID)
+		add(tag.SeriesInstanceUID, q.SeriesInstanceUID)
+		add(tag.Modality, q.Modality)
+		add(tag.SeriesNumber, q.SeriesNumber)
+		add(tag.SeriesDescription, q.SeriesDescription)
+	default:
+		add(tag.PatientID, q.PatientID)
+		add(tag.StudyInstanceUID, q.StudyInstanceUID)
+		add(tag.SeriesInstanceUID, q.SeriesInstanceUID)
+		add(tag.SOPInstanceUID, q.SOPInstanceUID)
+		add(tag.SOPClassUID, q.SOPClassUID)
+		add(tag.InstanceNumber, q.InstanceNumber)
+	}
+	if q.Level != "" {
+		add(tag.QueryRetrieveLevel, q.Level)
+	}
+	elements = append(elements, q.Keys...)
+	return go2com.Dataset{Elements: sortElements(elements)}
+}
+
+// sortElements sorts the elements by tag, keeping the last of duplicated tags
+func sortElements(elements []*go2com.Element) []*go2com.Element {
+	byTag := make(map[tag.DicomTag]*go2com.Element, len(elements))
+	for _, elem := range elements {
+		byTag[elem.Tag] = elem
+	}
+	res := make([]*go2com.Element, 0, len(byTag))
+	for _, elem := range byTag {
+		res = append(res, elem)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return tagLess(res[i].Tag, res[j].Tag)
+	})
+	return res
+}
+
+// Find sends a C-FIND request with the query and returns the identifiers of the pending responses
+func (a *Association) Find(ctx context.Context, sopClassUID string, query *Query) ([]go2com.Dataset, error) {
+	results := make([]go2com.Dataset, 0)
+	err := a.FindFunc(ctx, sopClassUID, query.Identifier(), func(ds go2com.Dataset) bool {
+		results = append(results, ds)
+		return true
+	})
+	return results, err
+}
+
+// FindFunc sends a C-FIND request with the identifier and calls fn with the identifier of each pending response.
+// When fn returns false, a C-CANCEL request is sent and the remaining pending responses are discarded
+func (a *Association) FindFunc(ctx context.Context, sopClassUID string, identifier go2com.Dataset, fn func(go2com.Dataset) bool) error {
+	pc, err := a.FindPresentationContext(sopClassUID)
+	if err != nil {
+		return err
+	}
+	data, err := encodeDataset(identifier, pc.TransferSyntax)
+	if err != nil {
+		return err
+	}
+	stop := a.watch(ctx)
+	defer stop()
+
+	cmd := &Command{
+		CommandField:        CFindRQ,
+		MessageID:           a.nextMessageID(),
+		Priority:            PriorityMedium,
+		AffectedSOPClassUID: sopClassUID,
+	}
+	wait := a.expectResponse(cmd.MessageID)
+	err = a.writeMessage(pc.ID, cmd, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	canceled := false
+	for {
+		rsp, err := wait()
+		if err != nil {
+			return err
+		}
+		status := rsp.Command.Status
+		if !IsPendingStatus(status) {
+			if IsSuccessStatus(status) || IsWarningStatus(status) || (canceled && status == StatusCancel) {
+				return nil
+			}
+			return &StatusError{Status: status, ErrorComment: rsp.Command.ErrorComment}
+		}
+		if canceled || rsp.Data == nil {
+			continue
+		}
+		ds, err := decodeDataset(rsp.Data, pc.TransferSyntax)
+		if err != nil {
+			return fmt.Errorf("network: cannot parse C-FIND response identifier: %v", err)
+		}
+		if !fn(ds) {
+			canceled = true
+			err = a.writeMessage(pc.ID, &Command{CommandField: CCancelRQ, MessageIDBeingRespondedTo: cmd.MessageID}, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// CFindRequest holds a C-FIND request received by the SCP
+type CFindRequest struct {
+	CallingAETitle string
+	CalledAETitle  string
+	RemoteAddr     net.Addr
+	SOPClassUID    string
+	// Level is the QueryRetrieveLevel of the identifier, empty for Modality Worklist queries
+	Level      string
+	Identifier go2com.Dataset
+}
+
+// QueryProvider returns the candidate data sets of a C-FIND request. The provider may filter the candidates
+// itself; the SCP only returns the candidates matching the identifier, see Match. The context is canceled
+// when the SCU sends a C-CANCEL request
+type QueryProvider func(ctx context.Context, req *CFindRequest) ([]go2com.Dataset, error)
+
+// WithQueryProvider provides option to accept C-FIND requests, answered with the candidates of the provider.
+// The query SOP classes must be accepted with WithSupportedContext
+func WithQueryProvider(provider QueryProvider) func(*Server) {
+	return func(s *Server) {
+		s.queryProvider = provider
+		s.handlers[CFindRQ] = s.handleFind
+	}
+}
+
+// handleFind reads the identifier of the C-FIND request and sends the matches in the background
+func (s *Server) handleFind(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+	if !cmd.HasDataset {
+		return a.sendResponse(pcID, cmd, StatusCannotUnderstand)
+	}
+	buf := bytes.Buffer{}
+	err := a.readData(pcID, &buf)
+	if err != nil {
+		return err
+	}
+	identifier, err := decodeDataset(buf.Bytes(), a.contexts[pcID].TransferSyntax)
+	if err != nil {
+		return a.sendResponse(pcID, cmd, StatusCannotUnderstand)
+	}
+	req := &CFindRequest{
+		CallingAETitle: a.CallingAETitle(),
+		CalledAETitle:  a.CalledAETitle(),
+		RemoteAddr:     a.RemoteAddr(),
+		SOPClassUID:    cmd.AffectedSOPClassUID,
+		Identifier:     identifier,
+	}
+	if elem, err := identifier.FindElementByTag(tag.QueryRetrieveLevel); err == nil {
+		req.Level, _ = elem.Value.RawValue.(string)
+	}
+
+	a.startOperation(ctx, cmd.MessageID, func(ctx context.Context) {
+		err := s.find(ctx, a, pcID, cmd, req)
+		if err != nil {
+			_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonNotSpecified)
+		}
+	})
+	return nil
+}
+
+// find sends a pending response for each candidate matching the identifier, then the final response
+func (s *Server) find(ctx context.Context, a *Association, pcID byte, cmd *Command, req *CFindRequest) error {
+	candidates, err := s.queryProvider(ctx, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return a.sendResponse(pcID, cmd, StatusCancel)
+		}
+		rsp := responseTo(cmd, StatusCannotUnderstand)
+		rsp.ErrorComment = truncateComment(err.Error())
+		return a.writeMessage(pcID, rsp, nil)
+	}
+	for _, candidate := range candidates {
+		if ctx.Err() != nil {
+			return a.sendResponse(pcID, cmd, StatusCancel)
+		}
+		if !Match(req.Identifier, candidate) {
+			continue
+		}
+		data, err := encodeDataset(responseIdentifier(req.Identifier, candidate), a.contexts[pcID].TransferSyntax)
+		if err != nil {
+			return a.sendResponse(pcID, cmd, StatusCannotUnderstand)
+		}
+		err = a.writeMessage(pcID, responseTo(cmd, StatusPending), bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+	}
+	return a.sendResponse(pcID, cmd, StatusSuccess)
+}
+
+// responseIdentifier returns the values of the candidate for the keys of the identifier. Keys without value in
+// the candidate are returned empty
+func responseIdentifier(identifier, candidate go2com.Dataset) go2com.Dataset {
+	elements := make([]*go2com.Element, 0, len(identifier.Elements))
+	for _, key := range identifier.Elements {
+		if key.Tag == tag.QueryRetrieveLevel {
+			elements = append(elements, key)
+			continue
+		}
+		elem, err := candidate.FindElementByTag(key.Tag)
+		if err != nil {
+			elem = go2com.NewElement(key.Tag, nil)
+		}
+		elements = append(elements, elem)
+	}
+	if elem, err := candidate.FindElementByTag(tag.SpecificCharacterSet); err == nil {
+		elements = append(elements, elem)
+	}
+	return go2com.Dataset{Elements: sortElements(elements)}
+}
+
+// truncateComment shortens the text to the 64 characters of the Error Comment attribute
+func truncateComment(comment string) string {
+	if len(comment) > 64 {
+		return comment[:64]
+	}
+	return comment
+}
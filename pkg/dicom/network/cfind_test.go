@@ -0,0 +1,149 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+func studyCandidate(patientName, studyUID, studyDate, modalities string) go2com.Dataset {
+	return go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.StudyDate, studyDate),
+		go2com.NewElement(tag.ModalitiesInStudy, modalities),
+		go2com.NewElement(tag.PatientName, patientName),
+		go2com.NewElement(tag.PatientID, "PID-"+patientName),
+		go2com.NewElement(tag.StudyInstanceUID, studyUID),
+	}}
+}
+
+func TestMatch(t *testing.T) {
+	assert := assert.New(t)
+	candidate := studyCandidate("Doe^John", "1.2.3", "20230115", `CT\MR`)
+
+	cases := []struct {
+		key     *go2com.Element
+		matches bool
+	}{
+		{go2com.NewElement(tag.PatientName, ""), true},
+		{go2com.NewElement(tag.PatientName, "DOE^JOHN"), true},
+		{go2com.NewElement(tag.PatientName, "Doe*"), true},
+		{go2com.NewElement(tag.PatientName, "D?e^J*n"), true},
+		{go2com.NewElement(tag.PatientName, "Smith*"), false},
+		{go2com.NewElement(tag.StudyDate, "20230101-20230131"), true},
+		{go2com.NewElement(tag.StudyDate, "20230116-"), false},
+		{go2com.NewElement(tag.StudyDate, "-20230115"), true},
+		{go2com.NewElement(tag.StudyDate, "20230115"), true},
+		{go2com.NewElement(tag.StudyInstanceUID, []string{"1.2.4", "1.2.3"}), true},
+		{go2com.NewElement(tag.StudyInstanceUID, "1.2.*"), false},
+		{go2com.NewElement(tag.ModalitiesInStudy, "MR"), true},
+		{go2com.NewElement(tag.AccessionNumber, "A1"), false},
+		{go2com.NewElement(tag.AccessionNumber, ""), true},
+	}
+	for _, c := range cases {
+		identifier := go2com.Dataset{Elements: []*go2com.Element{c.key}}
+		assert.Equal(c.matches, Match(identifier, candidate), fmt.Sprintf("%s %v", c.key.Tag, c.key.Value.RawValue))
+	}
+}
+
+func TestFind(t *testing.T) {
+	assert := assert.New(t)
+	candidates := []go2com.Dataset{
+		studyCandidate("DOE^JOHN", "1.2.3.1", "20230115", "CT"),
+		studyCandidate("DOE^JANE", "1.2.3.2", "20221231", "MR"),
+		studyCandidate("ROE^RICHARD", "1.2.3.3", "20230120", "CT"),
+	}
+	levels := make(chan string, 1)
+	addr := startServer(t, NewServer("FIND-SCP",
+		WithSupportedContext(uid.StudyRootQRFind),
+		WithQueryProvider(func(ctx context.Context, req *CFindRequest) ([]go2com.Dataset, error) {
+			levels <- req.Level
+			return candidates, nil
+		}),
+	))
+
+	assoc, err := Associate(context.Background(), addr, "FIND-SCU", "FIND-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.StudyRootQRFind},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer assoc.Release()
+
+	results, err := assoc.Find(context.Background(), uid.StudyRootQRFind, &Query{
+		Level:       StudyLevel,
+		PatientName: "DOE*",
+		StudyDate:   "20230101-",
+	})
+	assert.NoError(err)
+	assert.Equal(StudyLevel, <-levels)
+	if assert.Len(results, 1) {
+		elem, err := results[0].FindElementByTag(tag.StudyInstanceUID)
+		assert.NoError(err)
+		assert.Equal("1.2.3.1", elem.Value.RawValue)
+		elem, err = results[0].FindElementByTag(tag.PatientID)
+		assert.NoError(err)
+		assert.Equal("PID-DOE^JOHN", elem.Value.RawValue)
+		elem, err = results[0].FindElementByTag(tag.QueryRetrieveLevel)
+		assert.NoError(err)
+		assert.Equal(StudyLevel, elem.Value.RawValue)
+	}
+
+	// Cancel after the first response
+	count := 0
+	err = assoc.FindFunc(context.Background(), uid.StudyRootQRFind, (&Query{Level: StudyLevel}).Identifier(), func(ds go2com.Dataset) bool {
+		count++
+		return false
+	})
+	assert.NoError(err)
+	assert.Equal(1, count)
+	<-levels
+
+	// The association is still usable after the cancellation
+	results, err = assoc.Find(context.Background(), uid.StudyRootQRFind, &Query{Level: StudyLevel, StudyInstanceUID: "1.2.3.3"})
+	assert.NoError(err)
+	assert.Len(results, 1)
+	<-levels
+}
+
+func TestFind_ModalityWorklist(t *testing.T) {
+	assert := assert.New(t)
+	worklistItem := func(accession, modality string) go2com.Dataset {
+		return go2com.Dataset{Elements: []*go2com.Element{
+			go2com.NewElement(tag.AccessionNumber, accession),
+			go2com.NewElement(tag.PatientName, "DOE^JOHN"),
+			go2com.NewElement(tag.ScheduledProcedureStepSequence, []*go2com.Element{
+				go2com.NewElement(tag.Modality, modality),
+				go2com.NewElement(tag.ScheduledStationAETitle, "MODALITY"),
+			}),
+		}}
+	}
+	addr := startServer(t, NewServer("MWL-SCP",
+		WithSupportedContext(uid.ModalityWorklistInformationFind),
+		WithQueryProvider(func(ctx context.Context, req *CFindRequest) ([]go2com.Dataset, error) {
+			return []go2com.Dataset{worklistItem("A1", "CT"), worklistItem("A2", "MR")}, nil
+		}),
+	))
+
+	assoc, err := Associate(context.Background(), addr, "MODALITY", "MWL-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.ModalityWorklistInformationFind},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer assoc.Release()
+
+	results, err := assoc.Find(context.Background(), uid.ModalityWorklistInformationFind, &Query{
+		ScheduledProcedureStep: &ScheduledProcedureStep{Modality: "MR", StationAETitle: "MODALITY"},
+	})
+	assert.NoError(err)
+	if assert.Len(results, 1) {
+		elem, err := results[0].FindElementByTag(tag.AccessionNumber)
+		assert.NoError(err)
+		assert.Equal("A2", elem.Value.RawValue)
+	}
+}
@@ -0,0 +1,184 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+)
+
+// PresentationContextProposal is an abstract syntax proposed with its transfer syntaxes in order of preference
+type PresentationContextProposal struct {
+	AbstractSyntax   string
+	TransferSyntaxes []string
+}
+
+type associationConfig struct {
+	maxPDULength   uint32
+	artimTimeout   time.Duration
+	roleSelections []pdu.RoleSelection
+	dialer         net.Dialer
+	tlsConfig      *tls.Config
+}
+
+// WithMaxPDULength provides option to set the maximum PDU length the association requestor accepts
+func WithMaxPDULength(maxPDULength uint32) func(*associationConfig) {
+	return func(c *associationConfig) {
+		c.maxPDULength = maxPDULength
+	}
+}
+
+// WithARTIMTimeout provides option to set the timeout to wait for the association and release responses
+func WithARTIMTimeout(timeout time.Duration) func(*associationConfig) {
+	return func(c *associationConfig) {
+		c.artimTimeout = timeout
+	}
+}
+
+// WithDialTimeout provides option to set the timeout of the TCP connection establishment
+func WithDialTimeout(timeout time.Duration) func(*associationConfig) {
+	return func(c *associationConfig) {
+		c.dialer.Timeout = timeout
+	}
+}
+
+// WithRoleSelection provides option to propose SCP/SCU role selection for the SOP class, e.g.: to act as
+// a Storage SCP on a C-GET association
+func WithRoleSelection(sopClassUID string, scuRole, scpRole bool) func(*associationConfig) {
+	return func(c *associationConfig) {
+		c.roleSelections = append(c.roleSelections, pdu.RoleSelection{
+			SOPClassUID: sopClassUID,
+			SCURole:     scuRole,
+			SCPRole:     scpRole,
+		})
+	}
+}
+
+// Associate connects to the remote application entity and negotiates an association with the given
+// presentation contexts. Proposals without transfer syntaxes use the uncompressed little endian syntaxes
+func Associate(ctx context.Context, address, callingAETitle, calledAETitle string, proposals []PresentationContextProposal, options ...func(*associationConfig)) (*Association, error) {
+	cfg := &associationConfig{
+		maxPDULength: pdu.DefaultMaxPDULength,
+		artimTimeout: DefaultARTIMTimeout,
+	}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	if len(proposals) == 0 {
+		return nil, fmt.Errorf("network: no presentation context proposed")
+	}
+	if len(proposals) > 128 {
+		return nil, fmt.Errorf("network: at most 128 presentation contexts can be proposed, got %d", len(proposals))
+	}
+
+	conn, err := cfg.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.tlsConfig != nil {
+		conn, err = tlsHandshake(ctx, conn, address, cfg.tlsConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return requestAssociation(ctx, conn, callingAETitle, calledAETitle, proposals, cfg)
+}
+
+func requestAssociation(ctx context.Context, conn net.Conn, callingAETitle, calledAETitle string, proposals []PresentationContextProposal, cfg *associationConfig) (*Association, error) {
+	a := &Association{
+		conn:           conn,
+		isRequestor:    true,
+		calledAETitle:  calledAETitle,
+		callingAETitle: callingAETitle,
+		contexts:       make(map[byte]*PresentationContext, len(proposals)),
+		maxPDULength:   cfg.maxPDULength,
+		artimTimeout:   cfg.artimTimeout,
+	}
+
+	rq := &pdu.AssociateRQ{
+		CalledAETitle:  calledAETitle,
+		CallingAETitle: callingAETitle,
+		UserInformation: pdu.UserInformation{
+			MaxPDULength:              cfg.maxPDULength,
+			ImplementationClassUID:    ImplementationClassUID,
+			ImplementationVersionName: ImplementationVersionName,
+			RoleSelections:            cfg.roleSelections,
+		},
+	}
+	for index, proposal := range proposals {
+		id := byte(2*index + 1)
+		transferSyntaxes := proposal.TransferSyntaxes
+		if len(transferSyntaxes) == 0 {
+			transferSyntaxes = defaultTransferSyntaxes
+		}
+		rq.PresentationContexts = append(rq.PresentationContexts, pdu.PresentationContextRQ{
+			ID:               id,
+			AbstractSyntax:   proposal.AbstractSyntax,
+			TransferSyntaxes: transferSyntaxes,
+		})
+		a.contexts[id] = &PresentationContext{
+			ID:             id,
+			AbstractSyntax: proposal.AbstractSyntax,
+			Result:         pdu.ResultNoReasonProviderRejection,
+			SCURole:        true,
+		}
+	}
+
+	stop := a.watch(ctx)
+	defer stop()
+	_ = conn.SetReadDeadline(time.Now().Add(cfg.artimTimeout))
+	err := a.writePDU(rq)
+	if err != nil {
+		_ = a.close()
+		return nil, err
+	}
+	p, err := a.readPDU()
+	if err != nil {
+		_ = a.close()
+		return nil, err
+	}
+
+	switch v := p.(type) {
+	case *pdu.AssociateAC:
+		a.peerMaxPDULength = v.UserInformation.MaxPDULength
+		a.peerImplClassUID = v.UserInformation.ImplementationClassUID
+		a.peerImplVersion = v.UserInformation.ImplementationVersionName
+		for _, ac := range v.PresentationContexts {
+			pc, ok := a.contexts[ac.ID]
+			if !ok {
+				continue
+			}
+			pc.Result = ac.Result
+			pc.TransferSyntax = ac.TransferSyntax
+		}
+		applyRoleSelections(a, cfg.roleSelections, v.UserInformation.RoleSelections)
+		return a, nil
+	case *pdu.AssociateRJ:
+		_ = a.close()
+		return nil, &RejectedError{Result: v.Result, Source: v.Source, Reason: v.Reason}
+	default:
+		_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonUnexpectedPDU)
+		return nil, fmt.Errorf("network: unexpected %s while waiting for association response", p.Type())
+	}
+}
+
+// applyRoleSelections sets the roles accepted by the association acceptor. A proposed role selection without
+// answer falls back to the default roles
+func applyRoleSelections(a *Association, proposed, accepted []pdu.RoleSelection) {
+	for _, proposal := range proposed {
+		for _, role := range accepted {
+			if role.SOPClassUID != proposal.SOPClassUID {
+				continue
+			}
+			for _, pc := range a.contexts {
+				if pc.AbstractSyntax == role.SOPClassUID {
+					pc.SCURole = proposal.SCURole && role.SCURole
+					pc.SCPRole = proposal.SCPRole && role.SCPRole
+				}
+			}
+		}
+	}
+}
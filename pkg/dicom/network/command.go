@@ -0,0 +1,393 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+// Command field values defined in PS3.7 E.1
+const (
+	CStoreRQ        uint16 = 0x0001
+	CStoreRSP       uint16 = 0x8001
+	CGetRQ          uint16 = 0x0010
+	CGetRSP         uint16 = 0x8010
+	CFindRQ         uint16 = 0x0020
+	CFindRSP        uint16 = 0x8020
+	CMoveRQ         uint16 = 0x0021
+	CMoveRSP        uint16 = 0x8021
+	CEchoRQ         uint16 = 0x0030
+	CEchoRSP        uint16 = 0x8030
+	NEventReportRQ  uint16 = 0x0100
+	NEventReportRSP uint16 = 0x8100
+	NGetRQ          uint16 = 0x0110
+	NGetRSP         uint16 = 0x8110
+	NSetRQ          uint16 = 0x0120
+	NSetRSP         uint16 = 0x8120
+	NActionRQ       uint16 = 0x0130
+	NActionRSP      uint16 = 0x8130
+	NCreateRQ       uint16 = 0x0140
+	NCreateRSP      uint16 = 0x8140
+	NDeleteRQ       uint16 = 0x0150
+	NDeleteRSP      uint16 = 0x8150
+	CCancelRQ       uint16 = 0x0FFF
+)
+
+// Priority values of the request messages
+const (
+	PriorityMedium uint16 = 0x0000
+	PriorityHigh   uint16 = 0x0001
+	PriorityLow    uint16 = 0x0002
+)
+
+// Status values shared by the DIMSE services (PS3.7 C)
+const (
+	StatusSuccess                     uint16 = 0x0000
+	StatusCancel                      uint16 = 0xFE00
+	StatusPending                     uint16 = 0xFF00
+	StatusPendingWarning              uint16 = 0xFF01
+	StatusWarning                     uint16 = 0xB000
+	StatusAttributeListError          uint16 = 0x0107
+	StatusAttributeValueOutOfRange    uint16 = 0x0116
+	StatusSOPClassNotSupported        uint16 = 0x0122
+	StatusClassInstanceConflict       uint16 = 0x0119
+	StatusDuplicateSOPInstance        uint16 = 0x0111
+	StatusDuplicateInvocation         uint16 = 0x0210
+	StatusInvalidArgumentValue        uint16 = 0x0115
+	StatusInvalidAttributeValue       uint16 = 0x0106
+	StatusInvalidObjectInstance       uint16 = 0x0117
+	StatusMissingAttribute            uint16 = 0x0120
+	StatusMissingAttributeValue       uint16 = 0x0121
+	StatusMistypedArgument            uint16 = 0x0212
+	StatusNoSuchArgument              uint16 = 0x0114
+	StatusNoSuchAttribute             uint16 = 0x0105
+	StatusNoSuchEventType             uint16 = 0x0113
+	StatusNoSuchObjectInstance        uint16 = 0x0112
+	StatusNoSuchSOPClass              uint16 = 0x0118
+	StatusProcessingFailure           uint16 = 0x0110
+	StatusResourceLimitation          uint16 = 0x0213
+	StatusUnrecognizedOperation       uint16 = 0x0211
+	StatusNoSuchActionType            uint16 = 0x0123
+	StatusNotAuthorized               uint16 = 0x0124
+	StatusRefusedOutOfResources       uint16 = 0xA700
+	StatusUnableToPerformSubOps       uint16 = 0xA702
+	StatusMoveDestinationUnknown      uint16 = 0xA801
+	StatusDataSetDoesNotMatchSOPClass uint16 = 0xA900
+	StatusCannotUnderstand            uint16 = 0xC000
+)
+
+// commandDataSetTypeNull indicates that no data set is present in the message
+const commandDataSetTypeNull uint16 = 0x0101
+
+// SubOperations holds the sub-operation counters of the C-GET and C-MOVE responses
+type SubOperations struct {
+	Remaining uint16
+	Completed uint16
+	Failed    uint16
+	Warning   uint16
+}
+
+// Command is the command set of a DIMSE message (PS3.7 E.1)
+type Command struct {
+	CommandField              uint16
+	AffectedSOPClassUID       string
+	RequestedSOPClassUID      string
+	MessageID                 uint16
+	MessageIDBeingRespondedTo uint16
+	MoveDestination           string
+	Priority                  uint16
+	HasDataset                bool
+	Status                    uint16
+	OffendingElement          []tag.DicomTag
+	ErrorComment              string
+	ErrorID                   uint16
+	AffectedSOPInstanceUID    string
+	RequestedSOPInstanceUID   string
+	EventTypeID               uint16
+	AttributeIdentifierList   []tag.DicomTag
+	ActionTypeID              uint16
+	// SubOperations is encoded only if it is not nil
+	SubOperations                        *SubOperations
+	MoveOriginatorApplicationEntityTitle string
+	MoveOriginatorMessageID              uint16
+}
+
+// IsResponse returns true if the command is a response message
+func (c *Command) IsResponse() bool {
+	return c.CommandField&0x8000 != 0
+}
+
+func (c *Command) String() string {
+	var sb strings.Builder
+	sb.WriteString(CommandFieldName(c.CommandField))
+	if c.IsResponse() {
+		sb.WriteString(fmt.Sprintf(" id=%d status=0x%04X", c.MessageIDBeingRespondedTo, c.Status))
+	} else {
+		sb.WriteString(fmt.Sprintf(" id=%d", c.MessageID))
+	}
+	if c.AffectedSOPClassUID != "" {
+		sb.WriteString(" sop_class=" + c.AffectedSOPClassUID)
+	}
+	if c.AffectedSOPInstanceUID != "" {
+		sb.WriteString(" sop_instance=" + c.AffectedSOPInstanceUID)
+	}
+	return sb.String()
+}
+
+// CommandFieldName returns the name of the DIMSE message of the given command field
+func CommandFieldName(commandField uint16) string {
+	names := map[uint16]string{
+		CStoreRQ: "C-STORE-RQ", CStoreRSP: "C-STORE-RSP",
+		CGetRQ: "C-GET-RQ", CGetRSP: "C-GET-RSP",
+		CFindRQ: "C-FIND-RQ", CFindRSP: "C-FIND-RSP",
+		CMoveRQ: "C-MOVE-RQ", CMoveRSP: "C-MOVE-RSP",
+		CEchoRQ: "C-ECHO-RQ", CEchoRSP: "C-ECHO-RSP",
+		NEventReportRQ: "N-EVENT-REPORT-RQ", NEventReportRSP: "N-EVENT-REPORT-RSP",
+		NGetRQ: "N-GET-RQ", NGetRSP: "N-GET-RSP",
+		NSetRQ: "N-SET-RQ", NSetRSP: "N-SET-RSP",
+		NActionRQ: "N-ACTION-RQ", NActionRSP: "N-ACTION-RSP",
+		NCreateRQ: "N-CREATE-RQ", NCreateRSP: "N-CREATE-RSP",
+		NDeleteRQ: "N-DELETE-RQ", NDeleteRSP: "N-DELETE-RSP",
+		CCancelRQ: "C-CANCEL-RQ",
+	}
+	name, ok := names[commandField]
+	if !ok {
+		return fmt.Sprintf("UNKNOWN-0x%04X", commandField)
+	}
+	return name
+}
+
+// StatusError is returned when a DIMSE response carries a failure or warning status
+type StatusError struct {
+	Status       uint16
+	ErrorComment string
+}
+
+func (e *StatusError) Error() string {
+	if e.ErrorComment == "" {
+		return fmt.Sprintf("dimse: response status 0x%04X", e.Status)
+	}
+	return fmt.Sprintf("dimse: response status 0x%04X: %s", e.Status, e.ErrorComment)
+}
+
+// commandWriter encodes the command elements in Implicit VR Little Endian
+type commandWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *commandWriter) header(element uint16, length int) {
+	_ = binary.Write(&w.buf, binary.LittleEndian, uint16(0x0000))
+	_ = binary.Write(&w.buf, binary.LittleEndian, element)
+	_ = binary.Write(&w.buf, binary.LittleEndian, uint32(length))
+}
+
+func (w *commandWriter) uid(element uint16, value string) {
+	if value == "" {
+		return
+	}
+	b := []byte(value)
+	if len(b)%2 != 0 {
+		b = append(b, 0x00)
+	}
+	w.header(element, len(b))
+	w.buf.Write(b)
+}
+
+func (w *commandWriter) str(element uint16, value string) {
+	if value == "" {
+		return
+	}
+	b := []byte(value)
+	if len(b)%2 != 0 {
+		b = append(b, ' ')
+	}
+	w.header(element, len(b))
+	w.buf.Write(b)
+}
+
+func (w *commandWriter) us(element uint16, value uint16) {
+	w.header(element, 2)
+	_ = binary.Write(&w.buf, binary.LittleEndian, value)
+}
+
+func (w *commandWriter) tags(element uint16, tags []tag.DicomTag) {
+	if len(tags) == 0 {
+		return
+	}
+	w.header(element, 4*len(tags))
+	for _, t := range tags {
+		_ = binary.Write(&w.buf, binary.LittleEndian, t.Group)
+		_ = binary.Write(&w.buf, binary.LittleEndian, t.Element)
+	}
+}
+
+// Encode encodes the command set in Implicit VR Little Endian, including the Command Group Length element
+func (c *Command) Encode() []byte {
+	w := commandWriter{}
+	w.uid(tag.AffectedSOPClassUID.Element, c.AffectedSOPClassUID)
+	w.uid(tag.RequestedSOPClassUID.Element, c.RequestedSOPClassUID)
+	w.us(tag.CommandField.Element, c.CommandField)
+	if c.IsResponse() {
+		w.us(tag.MessageIDBeingRespondedTo.Element, c.MessageIDBeingRespondedTo)
+	} else if c.CommandField == CCancelRQ {
+		w.us(tag.MessageIDBeingRespondedTo.Element, c.MessageIDBeingRespondedTo)
+	} else {
+		w.us(tag.MessageID.Element, c.MessageID)
+	}
+	w.str(tag.MoveDestination.Element, c.MoveDestination)
+	switch c.CommandField {
+	case CStoreRQ, CFindRQ, CGetRQ, CMoveRQ:
+		w.us(tag.Priority.Element, c.Priority)
+	}
+	if c.HasDataset {
+		w.us(tag.CommandDataSetType.Element, 0x0000)
+	} else {
+		w.us(tag.CommandDataSetType.Element, commandDataSetTypeNull)
+	}
+	if c.IsResponse() {
+		w.us(tag.Status.Element, c.Status)
+	}
+	w.tags(tag.OffendingElement.Element, c.OffendingElement)
+	w.str(tag.ErrorComment.Element, c.ErrorComment)
+	if c.ErrorID != 0 {
+		w.us(tag.ErrorID.Element, c.ErrorID)
+	}
+	w.uid(tag.AffectedSOPInstanceUID.Element, c.AffectedSOPInstanceUID)
+	w.uid(tag.RequestedSOPInstanceUID.Element, c.RequestedSOPInstanceUID)
+	switch c.CommandField {
+	case NEventReportRQ, NEventReportRSP:
+		w.us(tag.EventTypeID.Element, c.EventTypeID)
+	}
+	w.tags(tag.AttributeIdentifierList.Element, c.AttributeIdentifierList)
+	switch c.CommandField {
+	case NActionRQ, NActionRSP:
+		w.us(tag.ActionTypeID.Element, c.ActionTypeID)
+	}
+	if c.SubOperations != nil {
+		w.us(tag.NumberOfRemainingSuboperations.Element, c.SubOperations.Remaining)
+		w.us(tag.NumberOfCompletedSuboperations.Element, c.SubOperations.Completed)
+		w.us(tag.NumberOfFailedSuboperations.Element, c.SubOperations.Failed)
+		w.us(tag.NumberOfWarningSuboperations.Element, c.SubOperations.Warning)
+	}
+	if c.MoveOriginatorApplicationEntityTitle != "" {
+		w.str(tag.MoveOriginatorApplicationEntityTitle.Element, c.MoveOriginatorApplicationEntityTitle)
+		w.us(tag.MoveOriginatorMessageID.Element, c.MoveOriginatorMessageID)
+	}
+
+	res := commandWriter{}
+	res.header(tag.CommandGroupLength.Element, 4)
+	_ = binary.Write(&res.buf, binary.LittleEndian, uint32(w.buf.Len()))
+	res.buf.Write(w.buf.Bytes())
+	return res.buf.Bytes()
+}
+
+// DecodeCommand decodes a command set encoded in Implicit VR Little Endian
+func DecodeCommand(b []byte) (*Command, error) {
+	elements := make(map[uint16][]byte)
+	for pos := 0; pos < len(b); {
+		if len(b)-pos < 8 {
+			return nil, fmt.Errorf("dimse: truncated command element at offset %d", pos)
+		}
+		group := binary.LittleEndian.Uint16(b[pos:])
+		element := binary.LittleEndian.Uint16(b[pos+2:])
+		length := int(binary.LittleEndian.Uint32(b[pos+4:]))
+		pos += 8
+		if length < 0 || len(b)-pos < length {
+			return nil, fmt.Errorf("dimse: truncated value of command element (%04x,%04x)", group, element)
+		}
+		if group != 0x0000 {
+			return nil, fmt.Errorf("dimse: unexpected element (%04x,%04x) in command set", group, element)
+		}
+		elements[element] = b[pos : pos+length]
+		pos += length
+	}
+
+	str := func(t tag.DicomTag) string {
+		return strings.TrimRight(string(elements[t.Element]), "\x00 ")
+	}
+	us := func(t tag.DicomTag) uint16 {
+		v := elements[t.Element]
+		if len(v) < 2 {
+			return 0
+		}
+		return binary.LittleEndian.Uint16(v)
+	}
+	tags := func(t tag.DicomTag) []tag.DicomTag {
+		v := elements[t.Element]
+		res := make([]tag.DicomTag, 0, len(v)/4)
+		for i := 0; i+4 <= len(v); i += 4 {
+			res = append(res, tag.DicomTag{
+				Group:   binary.LittleEndian.Uint16(v[i:]),
+				Element: binary.LittleEndian.Uint16(v[i+2:]),
+			})
+		}
+		return res
+	}
+	has := func(t tag.DicomTag) bool {
+		_, ok := elements[t.Element]
+		return ok
+	}
+
+	if !has(tag.CommandField) {
+		return nil, fmt.Errorf("dimse: missing command field in command set")
+	}
+	c := &Command{
+		CommandField:                         us(tag.CommandField),
+		AffectedSOPClassUID:                  str(tag.AffectedSOPClassUID),
+		RequestedSOPClassUID:                 str(tag.RequestedSOPClassUID),
+		MessageID:                            us(tag.MessageID),
+		MessageIDBeingRespondedTo:            us(tag.MessageIDBeingRespondedTo),
+		MoveDestination:                      str(tag.MoveDestination),
+		Priority:                             us(tag.Priority),
+		HasDataset:                           us(tag.CommandDataSetType) != commandDataSetTypeNull,
+		Status:                               us(tag.Status),
+		ErrorComment:                         str(tag.ErrorComment),
+		ErrorID:                              us(tag.ErrorID),
+		AffectedSOPInstanceUID:               str(tag.AffectedSOPInstanceUID),
+		RequestedSOPInstanceUID:              str(tag.RequestedSOPInstanceUID),
+		EventTypeID:                          us(tag.EventTypeID),
+		ActionTypeID:                         us(tag.ActionTypeID),
+		MoveOriginatorApplicationEntityTitle: str(tag.MoveOriginatorApplicationEntityTitle),
+		MoveOriginatorMessageID:              us(tag.MoveOriginatorMessageID),
+	}
+	if has(tag.OffendingElement) {
+		c.OffendingElement = tags(tag.OffendingElement)
+	}
+	if has(tag.AttributeIdentifierList) {
+		c.AttributeIdentifierList = tags(tag.AttributeIdentifierList)
+	}
+	if has(tag.NumberOfRemainingSuboperations) || has(tag.NumberOfCompletedSuboperations) ||
+		has(tag.NumberOfFailedSuboperations) || has(tag.NumberOfWarningSuboperations) {
+		c.SubOperations = &SubOperations{
+			Remaining: us(tag.NumberOfRemainingSuboperations),
+			Completed: us(tag.NumberOfCompletedSuboperations),
+			Failed:    us(tag.NumberOfFailedSuboperations),
+			Warning:   us(tag.NumberOfWarningSuboperations),
+		}
+	}
+	return c, nil
+}
+
+// IsPendingStatus returns true if the status is one of the pending statuses
+func IsPendingStatus(status uint16) bool {
+	return status == StatusPending || status == StatusPendingWarning
+}
+
+// IsWarningStatus returns true if the status is a warning status
+func IsWarningStatus(status uint16) bool {
+	return status == 0x0001 || status&0xF000 == 0xB000 || status == 0x0107 || status == 0x0116
+}
+
+// IsSuccessStatus returns true if the status denotes a successful completion, possibly with warnings
+func IsSuccessStatus(status uint16) bool {
+	return status == StatusSuccess || IsWarningStatus(status)
+}
+
+// IsFailureStatus returns true if the status denotes a failure: neither a success, a warning, a pending status nor
+// a cancellation
+func IsFailureStatus(status uint16) bool {
+	return !IsSuccessStatus(status) && !IsPendingStatus(status) && status != StatusCancel
+}
@@ -0,0 +1,364 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// Storage Commitment Push Model action and event types (PS3.4 J.3)
+const (
+	CommitmentActionRequest       uint16 = 1
+	CommitmentEventSuccess        uint16 = 1
+	CommitmentEventFailuresExists uint16 = 2
+)
+
+// Failure reasons of the Failed SOP Sequence (PS3.4 J.3.2.1.1)
+const (
+	FailureProcessingFailure       uint16 = 0x0110
+	FailureNoSuchObjectInstance    uint16 = 0x0112
+	FailureResourceLimitation      uint16 = 0x0213
+	FailureSOPClassNotSupported    uint16 = 0x0122
+	FailureClassInstanceConflict   uint16 = 0x0119
+	FailureDuplicateTransactionUID uint16 = 0x0131
+)
+
+// SOPReference references a SOP instance of a storage commitment request or result
+type SOPReference struct {
+	SOPClassUID    string
+	SOPInstanceUID string
+	// FailureReason is set for the instances that could not be committed
+	FailureReason uint16
+}
+
+// CommitmentResult is the outcome of a storage commitment transaction
+type CommitmentResult struct {
+	TransactionUID string
+	Committed      []SOPReference
+	Failed         []SOPReference
+}
+
+// CommitmentTracker tracks the storage commitment transactions of an SCU until their result is reported
+type CommitmentTracker struct {
+	mu      sync.Mutex
+	pending map[string]chan *CommitmentResult
+}
+
+// NewCommitmentTracker returns a new storage commitment tracker
+func NewCommitmentTracker() *CommitmentTracker {
+	return &CommitmentTracker{pending: make(map[string]chan *CommitmentResult)}
+}
+
+// Request sends the storage commitment request for the instances and returns the UID of the transaction. The
+// result is reported with an N-EVENT-REPORT, either on the same association, see ReceiveReport, or on a new
+// association to a server created with WithStorageCommitmentSCU
+func (t *CommitmentTracker) Request(ctx context.Context, a *Association, refs []SOPReference) (string, error) {
+	transactionUID, err := uid.GenerateUUID()
+	if err != nil {
+		return "", err
+	}
+	ds := go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.TransactionUID, transactionUID),
+		go2com.NewElement(tag.ReferencedSOPSequence, referenceItems(refs, false)),
+	}}
+
+	t.mu.Lock()
+	t.pending[transactionUID] = make(chan *CommitmentResult, 1)
+	t.mu.Unlock()
+	_, err = a.NAction(ctx, uid.StorageCommitmentPushModel, uid.StorageCommitmentPushModelInst, CommitmentActionRequest, &ds)
+	if err != nil {
+		t.mu.Lock()
+		delete(t.pending, transactionUID)
+		t.mu.Unlock()
+		return "", err
+	}
+	return transactionUID, nil
+}
+
+// Pending returns the UIDs of the transactions waiting for their result
+func (t *CommitmentTracker) Pending() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res := make([]string, 0, len(t.pending))
+	for transactionUID := range t.pending {
+		res = append(res, transactionUID)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Wait waits for the result of the transaction
+func (t *CommitmentTracker) Wait(ctx context.Context, transactionUID string) (*CommitmentResult, error) {
+	t.mu.Lock()
+	ch, ok := t.pending[transactionUID]
+	t.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("network: unknown storage commitment transaction %s", transactionUID)
+	}
+	select {
+	case res := <-ch:
+		t.mu.Lock()
+		delete(t.pending, transactionUID)
+		t.mu.Unlock()
+		return res, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// ReceiveReport waits for the N-EVENT-REPORT of a storage commitment result on the association of the request,
+// answers it and returns the result, which is also delivered to Wait. The reports received on an association served
+// by a Server are delivered by its serving loop to the tracker of WithStorageCommitmentSCU
+func (t *CommitmentTracker) ReceiveReport(ctx context.Context, a *Association) (*CommitmentResult, error) {
+	if a.dispatched {
+		return nil, fmt.Errorf("network: the reports of an association served by a Server are received by its serving loop")
+	}
+	stop := a.watch(ctx)
+	defer stop()
+
+	pcID, cmd, err := a.readCommand()
+	if err != nil {
+		return nil, err
+	}
+	if cmd.CommandField != NEventReportRQ || cmd.AffectedSOPClassUID != uid.StorageCommitmentPushModel {
+		return nil, fmt.Errorf("network: unexpected %s while waiting for storage commitment report", cmd)
+	}
+	req, err := readNRequest(a, pcID, cmd)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil {
+		return nil, fmt.Errorf("network: cannot decode storage commitment report")
+	}
+	res, rsp := t.report(req)
+	err = sendNResponse(a, pcID, cmd, rsp)
+	if err != nil {
+		return nil, err
+	}
+	if res == nil {
+		return nil, &StatusError{Status: rsp.Status, ErrorComment: rsp.ErrorComment}
+	}
+	return res, nil
+}
+
+// report delivers the result of an N-EVENT-REPORT request to the pending transaction and returns the response
+func (t *CommitmentTracker) report(req *NRequest) (*CommitmentResult, *NResponse) {
+	if req.EventTypeID != CommitmentEventSuccess && req.EventTypeID != CommitmentEventFailuresExists {
+		return nil, &NResponse{Status: StatusNoSuchEventType}
+	}
+	if req.Dataset == nil {
+		return nil, &NResponse{Status: StatusMissingAttribute, ErrorComment: "missing event information"}
+	}
+	res := &CommitmentResult{}
+	if elem, err := req.Dataset.FindElementByTag(tag.TransactionUID); err == nil {
+		res.TransactionUID, _ = elem.Value.RawValue.(string)
+	}
+	res.Committed = parseReferences(*req.Dataset, tag.ReferencedSOPSequence)
+	res.Failed = parseReferences(*req.Dataset, tag.FailedSOPSequence)
+
+	t.mu.Lock()
+	ch, ok := t.pending[res.TransactionUID]
+	t.mu.Unlock()
+	if !ok {
+		return nil, &NResponse{Status: StatusInvalidArgumentValue, ErrorComment: "unknown transaction UID " + res.TransactionUID}
+	}
+	select {
+	case ch <- res:
+	default:
+		return nil, &NResponse{Status: StatusDuplicateInvocation, ErrorComment: "transaction already reported"}
+	}
+	return res, &NResponse{Status: StatusSuccess}
+}
+
+// WithStorageCommitmentSCU provides option to receive the storage commitment results reported on new
+// associations and to deliver them to the tracker
+func WithStorageCommitmentSCU(tracker *CommitmentTracker) func(*Server) {
+	return func(s *Server) {
+		s.commitmentTracker = tracker
+		s.registerN(uid.StorageCommitmentPushModel, s.handleCommitment)
+	}
+}
+
+// CommitmentRequest holds a storage commitment request received by the SCP
+type CommitmentRequest struct {
+	CallingAETitle string
+	CalledAETitle  string
+	RemoteAddr     net.Addr
+	TransactionUID string
+	References     []SOPReference
+}
+
+// CommitmentHandler commits the referenced instances and returns the result of the transaction. The references
+// without FailureReason in the Failed list are reported with a processing failure
+type CommitmentHandler func(ctx context.Context, req *CommitmentRequest) *CommitmentResult
+
+// WithStorageCommitmentSCP provides option to accept storage commitment requests. The result of the handler is
+// reported on the association of the request if it is still open, otherwise on a new association to the
+// address of the calling AE title registered with WithCommitmentDestination
+func WithStorageCommitmentSCP(handler CommitmentHandler) func(*Server) {
+	return func(s *Server) {
+		s.commitmentHandler = handler
+		s.registerN(uid.StorageCommitmentPushModel, s.handleCommitment)
+	}
+}
+
+// WithCommitmentDestination provides option to register the address of a storage commitment SCU AE title, where
+// the results are reported once the association of the request is closed
+func WithCommitmentDestination(aeTitle, address string) func(*Server) {
+	return func(s *Server) {
+		s.commitmentDestinations[aeTitle] = address
+	}
+}
+
+// handleCommitment answers the N-ACTION requests of the SCP and the N-EVENT-REPORT requests of the SCU
+func (s *Server) handleCommitment(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+	req, err := readNRequest(a, pcID, cmd)
+	if err != nil || req == nil {
+		return err
+	}
+	switch {
+	case cmd.CommandField == NEventReportRQ && s.commitmentTracker != nil:
+		_, rsp := s.commitmentTracker.report(req)
+		return sendNResponse(a, pcID, cmd, rsp)
+	case cmd.CommandField == NActionRQ && s.commitmentHandler != nil:
+	default:
+		return sendNResponse(a, pcID, cmd, &NResponse{Status: StatusUnrecognizedOperation})
+	}
+
+	if req.ActionTypeID != CommitmentActionRequest {
+		return sendNResponse(a, pcID, cmd, &NResponse{Status: StatusNoSuchActionType})
+	}
+	if req.SOPInstanceUID != uid.StorageCommitmentPushModelInst {
+		return sendNResponse(a, pcID, cmd, &NResponse{Status: StatusNoSuchObjectInstance})
+	}
+	commitment := &CommitmentRequest{
+		CallingAETitle: req.CallingAETitle,
+		CalledAETitle:  req.CalledAETitle,
+		RemoteAddr:     req.RemoteAddr,
+	}
+	if req.Dataset != nil {
+		if elem, err := req.Dataset.FindElementByTag(tag.TransactionUID); err == nil {
+			commitment.TransactionUID, _ = elem.Value.RawValue.(string)
+		}
+		commitment.References = parseReferences(*req.Dataset, tag.ReferencedSOPSequence)
+	}
+	if commitment.TransactionUID == "" || len(commitment.References) == 0 {
+		return sendNResponse(a, pcID, cmd, &NResponse{Status: StatusMissingAttribute})
+	}
+	err = sendNResponse(a, pcID, cmd, &NResponse{Status: StatusSuccess})
+	if err != nil {
+		return err
+	}
+
+	// The commitment may take longer than the association, the result is reported in the background
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		res := s.commitmentHandler(ctx, commitment)
+		if res == nil {
+			res = &CommitmentResult{}
+			for _, ref := range commitment.References {
+				ref.FailureReason = FailureProcessingFailure
+				res.Failed = append(res.Failed, ref)
+			}
+		}
+		res.TransactionUID = commitment.TransactionUID
+		_ = s.reportCommitment(ctx, a, res)
+	}()
+	return nil
+}
+
+// reportCommitment sends the N-EVENT-REPORT of the storage commitment result on the association, or on a new
+// association if it is closed
+func (s *Server) reportCommitment(ctx context.Context, a *Association, res *CommitmentResult) error {
+	eventTypeID := CommitmentEventSuccess
+	elements := []*go2com.Element{
+		go2com.NewElement(tag.TransactionUID, res.TransactionUID),
+	}
+	if len(res.Committed) > 0 {
+		elements = append(elements, go2com.NewElement(tag.ReferencedSOPSequence, referenceItems(res.Committed, false)))
+	}
+	if len(res.Failed) > 0 {
+		eventTypeID = CommitmentEventFailuresExists
+		elements = append(elements, go2com.NewElement(tag.FailedSOPSequence, referenceItems(res.Failed, true)))
+	}
+	ds := go2com.Dataset{Elements: sortElements(elements)}
+
+	if !a.isClosed() {
+		_, err := a.NEventReport(ctx, uid.StorageCommitmentPushModel, uid.StorageCommitmentPushModelInst, eventTypeID, &ds)
+		if err == nil || (err != ErrAssociationClosed && !a.isClosed()) {
+			return err
+		}
+	}
+	address, ok := s.commitmentDestinations[a.CallingAETitle()]
+	if !ok {
+		return fmt.Errorf("network: unknown address of AE title %s", a.CallingAETitle())
+	}
+	target, err := Associate(ctx, address, s.aeTitle, a.CallingAETitle(),
+		[]PresentationContextProposal{{AbstractSyntax: uid.StorageCommitmentPushModel}},
+		WithRoleSelection(uid.StorageCommitmentPushModel, false, true),
+		WithMaxPDULength(s.maxPDULength), WithARTIMTimeout(s.artimTimeout))
+	if err != nil {
+		return err
+	}
+	defer target.Release()
+	_, err = target.NEventReport(ctx, uid.StorageCommitmentPushModel, uid.StorageCommitmentPushModelInst, eventTypeID, &ds)
+	return err
+}
+
+// referenceItems returns the flattened items of a Referenced SOP Sequence or, with failureReason, of a
+// Failed SOP Sequence
+func referenceItems(refs []SOPReference, failureReason bool) []*go2com.Element {
+	items := make([]*go2com.Element, 0, 3*len(refs))
+	for _, ref := range refs {
+		items = append(items,
+			go2com.NewElement(tag.ReferencedSOPClassUID, ref.SOPClassUID),
+			go2com.NewElement(tag.ReferencedSOPInstanceUID, ref.SOPInstanceUID),
+		)
+		if failureReason {
+			reason := ref.FailureReason
+			if reason == 0 {
+				reason = FailureProcessingFailure
+			}
+			items = append(items, go2com.NewElement(tag.FailureReason, int(reason)))
+		}
+	}
+	return items
+}
+
+// parseReferences returns the SOP references of the items of the sequence
+func parseReferences(ds go2com.Dataset, t tag.DicomTag) []SOPReference {
+	elem, err := ds.FindElementByTag(t)
+	if err != nil {
+		return nil
+	}
+	refs := make([]SOPReference, 0)
+	for _, item := range sequenceItems(elem) {
+		ref := SOPReference{}
+		for _, e := range item {
+			switch e.Tag {
+			case tag.ReferencedSOPClassUID:
+				ref.SOPClassUID, _ = e.Value.RawValue.(string)
+			case tag.ReferencedSOPInstanceUID:
+				ref.SOPInstanceUID, _ = e.Value.RawValue.(string)
+			case tag.FailureReason:
+				switch v := e.Value.RawValue.(type) {
+				case int:
+					ref.FailureReason = uint16(v)
+				case []int:
+					if len(v) > 0 {
+						ref.FailureReason = uint16(v[0])
+					}
+				}
+			}
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
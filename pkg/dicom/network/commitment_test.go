@@ -0,0 +1,141 @@
+package network
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+// commitmentHandler commits all the instances except 1.2.3.2
+func commitmentHandler(ctx context.Context, req *CommitmentRequest) *CommitmentResult {
+	res := &CommitmentResult{}
+	for _, ref := range req.References {
+		if ref.SOPInstanceUID == "1.2.3.2" {
+			ref.FailureReason = FailureNoSuchObjectInstance
+			res.Failed = append(res.Failed, ref)
+			continue
+		}
+		res.Committed = append(res.Committed, ref)
+	}
+	return res
+}
+
+var commitmentRefs = []SOPReference{
+	{SOPClassUID: testSOPClassUID, SOPInstanceUID: "1.2.3.1"},
+	{SOPClassUID: testSOPClassUID, SOPInstanceUID: "1.2.3.2"},
+	{SOPClassUID: testSOPClassUID, SOPInstanceUID: "1.2.3.3"},
+}
+
+func TestStorageCommitment_SameAssociation(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t, NewServer("COMMIT-SCP",
+		WithSupportedContext(uid.StorageCommitmentPushModel),
+		WithStorageCommitmentSCP(commitmentHandler),
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assoc, err := Associate(ctx, addr, "COMMIT-SCU", "COMMIT-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.StorageCommitmentPushModel},
+	})
+	assert.NoError(err)
+	defer assoc.Release()
+
+	tracker := NewCommitmentTracker()
+	transactionUID, err := tracker.Request(ctx, assoc, commitmentRefs)
+	assert.NoError(err)
+	assert.Equal([]string{transactionUID}, tracker.Pending())
+
+	res, err := tracker.ReceiveReport(ctx, assoc)
+	assert.NoError(err)
+	assert.Equal(transactionUID, res.TransactionUID)
+	assert.Equal([]SOPReference{commitmentRefs[0], commitmentRefs[2]}, res.Committed)
+	assert.Equal([]SOPReference{{SOPClassUID: testSOPClassUID, SOPInstanceUID: "1.2.3.2", FailureReason: FailureNoSuchObjectInstance}}, res.Failed)
+
+	res2, err := tracker.Wait(ctx, transactionUID)
+	assert.NoError(err)
+	assert.Equal(res, res2)
+	assert.Empty(tracker.Pending())
+}
+
+func TestStorageCommitment_NewAssociation(t *testing.T) {
+	assert := assert.New(t)
+	tracker := NewCommitmentTracker()
+	scuAddr := startServer(t, NewServer("COMMIT-SCU",
+		WithSupportedContext(uid.StorageCommitmentPushModel),
+		WithStorageCommitmentSCU(tracker),
+	))
+	addr := startServer(t, NewServer("COMMIT-SCP",
+		WithSupportedContext(uid.StorageCommitmentPushModel),
+		WithStorageCommitmentSCP(func(ctx context.Context, req *CommitmentRequest) *CommitmentResult {
+			// Report after the release of the association of the request
+			time.Sleep(100 * time.Millisecond)
+			return commitmentHandler(ctx, req)
+		}),
+		WithCommitmentDestination("COMMIT-SCU", scuAddr),
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assoc, err := Associate(ctx, addr, "COMMIT-SCU", "COMMIT-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.StorageCommitmentPushModel},
+	})
+	assert.NoError(err)
+	transactionUID, err := tracker.Request(ctx, assoc, commitmentRefs[:1])
+	assert.NoError(err)
+	assert.NoError(assoc.Release())
+
+	res, err := tracker.Wait(ctx, transactionUID)
+	assert.NoError(err)
+	assert.Equal(commitmentRefs[:1], res.Committed)
+	assert.Empty(res.Failed)
+
+	// The C-MOVE destinations do not receive the storage commitment results
+	srv := NewServer("COMMIT-SCP", WithMoveDestination("COMMIT-SCU", scuAddr))
+	closed := &Association{callingAETitle: "COMMIT-SCU", closed: 1}
+	err = srv.reportCommitment(ctx, closed, &CommitmentResult{TransactionUID: "1.2.3", Committed: commitmentRefs[:1]})
+	assert.ErrorContains(err, "unknown address of AE title COMMIT-SCU")
+}
+
+func TestNService(t *testing.T) {
+	assert := assert.New(t)
+	const sopClassUID = "1.2.840.10008.5.1.1.40"
+	addr := startServer(t, NewServer("N-SCP",
+		WithSupportedContext(sopClassUID),
+		WithSupportedContext(testSOPClassUID),
+		WithNServiceHandler(sopClassUID, func(ctx context.Context, a *Association, req *NRequest) *NResponse {
+			if req.CommandField != NGetRQ {
+				return &NResponse{Status: StatusUnrecognizedOperation}
+			}
+			ds := go2com.Dataset{Elements: []*go2com.Element{go2com.NewElement(tag.PatientName, "DOE^JOHN")}}
+			return &NResponse{Status: StatusSuccess, Dataset: &ds}
+		}),
+	))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assoc, err := Associate(ctx, addr, "N-SCU", "N-SCP", []PresentationContextProposal{
+		{AbstractSyntax: sopClassUID},
+		{AbstractSyntax: testSOPClassUID},
+	})
+	assert.NoError(err)
+	defer assoc.Release()
+
+	res, err := assoc.NGet(ctx, sopClassUID, "1.2.3", []tag.DicomTag{tag.PatientName})
+	assert.NoError(err)
+	assert.Equal("1.2.3", res.AffectedSOPInstanceUID)
+	elem, err := res.Dataset.FindElementByTag(tag.PatientName)
+	assert.NoError(err)
+	assert.Equal("DOE^JOHN", elem.Value.RawValue)
+
+	_, err = assoc.NSet(ctx, sopClassUID, "1.2.3", go2com.Dataset{Elements: []*go2com.Element{go2com.NewElement(tag.PatientName, "DOE")}})
+	assert.Equal(&StatusError{Status: StatusUnrecognizedOperation}, err)
+
+	_, err = assoc.NAction(ctx, testSOPClassUID, "1.2.3", 1, nil)
+	assert.Equal(&StatusError{Status: StatusNoSuchSOPClass}, err)
+}
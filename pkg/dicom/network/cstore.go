@@ -0,0 +1,472 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// CStoreRequest holds an instance received by the C-STORE SCP
+type CStoreRequest struct {
+	CallingAETitle          string
+	CalledAETitle           string
+	RemoteAddr              net.Addr
+	SOPClassUID             string
+	SOPInstanceUID          string
+	TransferSyntaxUID       string
+	Priority                uint16
+	MoveOriginatorAETitle   string
+	MoveOriginatorMessageID uint16
+	// Metadata holds the file meta information of the instance
+	Metadata go2com.Dataset
+	// Dataset holds the parsed data set. It is empty when the instance is stored to disk
+	Dataset go2com.Dataset
+	// FilePath is the path of the Part 10 file when the instance is stored to disk
+	FilePath string
+}
+
+// CStoreHandler processes a received instance and returns the DIMSE status of the C-STORE response
+type CStoreHandler func(ctx context.Context, req *CStoreRequest) uint16
+
+// WithCStoreHandler provides option to accept C-STORE requests and hand the received instances to the handler.
+// The storage SOP classes must be accepted with WithSupportedContext
+func WithCStoreHandler(handler CStoreHandler) func(*Server) {
+	return func(s *Server) {
+		s.storeHandler = handler
+		s.handlers[CStoreRQ] = s.handleStore
+	}
+}
+
+// WithCStoreDirectory provides option to stream the received instances to Part 10 files named after their
+// SOP instance UID in the directory, instead of parsing them in memory
+func WithCStoreDirectory(dir string) func(*Server) {
+	return func(s *Server) {
+		s.storeDirectory = dir
+		s.handlers[CStoreRQ] = s.handleStore
+	}
+}
+
+// handleStore receives the data set of a C-STORE request, parses it or writes it to disk, and answers with the
+// status returned by the handler
+func (s *Server) handleStore(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+	return receiveStore(ctx, a, pcID, cmd, s.storeHandler, s.storeDirectory)
+}
+
+// receiveStore receives the data set of a C-STORE request, in memory or in the directory if not empty, and
+// answers with the status returned by the handler
+func receiveStore(ctx context.Context, a *Association, pcID byte, cmd *Command, handler CStoreHandler, dir string) error {
+	if !cmd.HasDataset {
+		return a.sendResponse(pcID, cmd, StatusCannotUnderstand)
+	}
+	req := &CStoreRequest{
+		CallingAETitle:          a.CallingAETitle(),
+		CalledAETitle:           a.CalledAETitle(),
+		RemoteAddr:              a.RemoteAddr(),
+		SOPClassUID:             cmd.AffectedSOPClassUID,
+		SOPInstanceUID:          cmd.AffectedSOPInstanceUID,
+		TransferSyntaxUID:       a.contexts[pcID].TransferSyntax,
+		Priority:                cmd.Priority,
+		MoveOriginatorAETitle:   cmd.MoveOriginatorApplicationEntityTitle,
+		MoveOriginatorMessageID: cmd.MoveOriginatorMessageID,
+	}
+	req.Metadata = go2com.NewFileMeta(req.SOPClassUID, req.SOPInstanceUID, req.TransferSyntaxUID)
+
+	var status uint16
+	var err error
+	if dir != "" {
+		status, err = receiveToFile(a, pcID, req, dir)
+	} else {
+		status, err = receiveDataset(a, pcID, req)
+	}
+	if err != nil {
+		return err
+	}
+	if status == StatusSuccess && handler != nil {
+		status = handler(ctx, req)
+	}
+	return a.sendResponse(pcID, cmd, status)
+}
+
+// receiveDataset reads the data set and parses it with the file meta information of the instance.
+// The returned error is only set when the association cannot be used anymore
+func receiveDataset(a *Association, pcID byte, req *CStoreRequest) (uint16, error) {
+	buf := bytes.Buffer{}
+	err := a.readData(pcID, &buf)
+	if err != nil {
+		return 0, err
+	}
+	req.Dataset, err = decodeDataset(buf.Bytes(), req.TransferSyntaxUID)
+	if err != nil {
+		return StatusCannotUnderstand, nil
+	}
+	return StatusSuccess, nil
+}
+
+// receiveToFile streams the data set to a Part 10 file in the storage directory. The file is written under a
+// temporary name and renamed once the data set is complete
+func receiveToFile(a *Association, pcID byte, req *CStoreRequest, dir string) (uint16, error) {
+	// A valid UID only holds digits and dots and is usable as a file name
+	if !uid.IsValid(req.SOPInstanceUID) {
+		return StatusCannotUnderstand, a.readData(pcID, io.Discard)
+	}
+	path := filepath.Join(dir, req.SOPInstanceUID+".dcm")
+	f, err := os.CreateTemp(dir, req.SOPInstanceUID+".*.part")
+	if err != nil {
+		return StatusRefusedOutOfResources, a.readData(pcID, io.Discard)
+	}
+	defer os.Remove(f.Name())
+
+	w := bufio.NewWriter(f)
+	err = go2com.NewDICOMWriter(w).WriteFileMeta(req.Metadata)
+	if err != nil {
+		_ = f.Close()
+		return StatusRefusedOutOfResources, a.readData(pcID, io.Discard)
+	}
+	writeErr := &errWriter{w: w}
+	err = a.readData(pcID, writeErr)
+	if err != nil {
+		_ = f.Close()
+		return 0, err
+	}
+	if writeErr.err == nil {
+		writeErr.err = w.Flush()
+	}
+	if closeErr := f.Close(); writeErr.err == nil {
+		writeErr.err = closeErr
+	}
+	if writeErr.err != nil {
+		return StatusRefusedOutOfResources, nil
+	}
+	err = os.Rename(f.Name(), path)
+	if err != nil {
+		return StatusRefusedOutOfResources, nil
+	}
+	req.FilePath = path
+	return StatusSuccess, nil
+}
+
+// errWriter records the first write error and discards the remaining data, so that the data set is always
+// read entirely from the association
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err == nil {
+		_, e.err = e.w.Write(p)
+	}
+	return len(p), nil
+}
+
+// CStoreResult holds the outcome of sending an instance with C-STORE
+type CStoreResult struct {
+	FilePath       string
+	SOPClassUID    string
+	SOPInstanceUID string
+	// TransferSyntaxUID is the transfer syntax the instance was sent with
+	TransferSyntaxUID string
+	Status            uint16
+	ErrorComment      string
+	// Err is set when the instance could not be sent or the response status is a failure
+	Err error
+}
+
+// ProposeStorageContexts returns the presentation contexts to propose for sending the files. For each SOP class,
+// one context is proposed with the uncompressed little endian syntaxes for the files that can be transcoded, and
+// one per original transfer syntax of the other files
+func ProposeStorageContexts(paths ...string) ([]PresentationContextProposal, error) {
+	proposals := make([]PresentationContextProposal, 0)
+	proposed := make(map[[2]string]bool)
+	propose := func(sopClassUID string, transferSyntaxes ...string) {
+		key := [2]string{sopClassUID, strings.Join(transferSyntaxes, "\\")}
+		if proposed[key] {
+			return
+		}
+		proposed[key] = true
+		proposals = append(proposals, PresentationContextProposal{AbstractSyntax: sopClassUID, TransferSyntaxes: transferSyntaxes})
+	}
+	for _, path := range paths {
+		sf, err := openStorageFile(path)
+		if err != nil {
+			return nil, err
+		}
+		_ = sf.Close()
+		if !transcodable(sf.transferSyntaxUID) {
+			propose(sf.sopClassUID, sf.transferSyntaxUID)
+			continue
+		}
+		propose(sf.sopClassUID, defaultTransferSyntaxes...)
+	}
+	if len(proposals) > 128 {
+		return nil, fmt.Errorf("network: %d presentation contexts exceed the maximum of 128", len(proposals))
+	}
+	return proposals, nil
+}
+
+type storeConfig struct {
+	priority                uint16
+	moveOriginatorAETitle   string
+	moveOriginatorMessageID uint16
+}
+
+// WithStorePriority provides option to set the priority of the C-STORE request
+func WithStorePriority(priority uint16) func(*storeConfig) {
+	return func(c *storeConfig) {
+		c.priority = priority
+	}
+}
+
+// WithMoveOriginator provides option to identify the C-MOVE request the C-STORE request is a sub-operation of
+func WithMoveOriginator(aeTitle string, messageID uint16) func(*storeConfig) {
+	return func(c *storeConfig) {
+		c.moveOriginatorAETitle = aeTitle
+		c.moveOriginatorMessageID = messageID
+	}
+}
+
+// StoreFile sends the Part 10 file with a C-STORE request and waits for the response. The file is sent in its
+// original transfer syntax if a presentation context was accepted for it, otherwise an uncompressed file is
+// transcoded to Explicit or Implicit VR Little Endian. The data set is streamed from the file when it is not
+// transcoded; a transcoded data set, pixel data included, is parsed into memory before it is sent.
+// The returned error is set when the instance was not stored; a failure status is returned as a *StatusError
+func (a *Association) StoreFile(ctx context.Context, path string, options ...func(*storeConfig)) (*CStoreResult, error) {
+	cfg := &storeConfig{priority: PriorityMedium}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	res := &CStoreResult{FilePath: path}
+	sf, err := openStorageFile(path)
+	if err != nil {
+		res.Err = err
+		return res, err
+	}
+	defer sf.Close()
+	res.SOPClassUID = sf.sopClassUID
+	res.SOPInstanceUID = sf.sopInstanceUID
+
+	pc, err := a.storageContext(sf.sopClassUID, sf.transferSyntaxUID)
+	if err != nil {
+		res.Err = err
+		return res, err
+	}
+	res.TransferSyntaxUID = pc.TransferSyntax
+
+	data := io.Reader(sf.data)
+	if pc.TransferSyntax != sf.transferSyntaxUID {
+		ds, err := sf.readDataset()
+		if err != nil {
+			res.Err = err
+			return res, err
+		}
+		pr, pw := io.Pipe()
+		defer pr.Close()
+		go func() {
+			w := go2com.NewDICOMWriter(pw, go2com.WithWriterTransferSyntax(binary.LittleEndian, pc.TransferSyntax == uid.ImplicitVRLittleEndian))
+			_ = pw.CloseWithError(w.WriteDataset(ds))
+		}()
+		data = pr
+	}
+
+	stop := a.watch(ctx)
+	defer stop()
+	cmd := &Command{
+		CommandField:                         CStoreRQ,
+		MessageID:                            a.nextMessageID(),
+		Priority:                             cfg.priority,
+		AffectedSOPClassUID:                  sf.sopClassUID,
+		AffectedSOPInstanceUID:               sf.sopInstanceUID,
+		MoveOriginatorApplicationEntityTitle: cfg.moveOriginatorAETitle,
+		MoveOriginatorMessageID:              cfg.moveOriginatorMessageID,
+	}
+	wait := a.expectResponse(cmd.MessageID)
+	err = a.writeMessage(pc.ID, cmd, data)
+	if err == nil {
+		var rsp *Message
+		rsp, err = wait()
+		if err == nil {
+			res.Status = rsp.Command.Status
+			res.ErrorComment = rsp.Command.ErrorComment
+		}
+	}
+	if err != nil {
+		// The state of a partially sent message is unknown to the peer
+		_ = a.Abort()
+		res.Err = err
+		return res, err
+	}
+	if IsFailureStatus(res.Status) {
+		res.Err = &StatusError{Status: res.Status, ErrorComment: res.ErrorComment}
+	}
+	return res, res.Err
+}
+
+// StoreFiles sends the files one after the other on the association and returns the result of each instance.
+// Once the association fails, the remaining instances are not sent and their result holds the error
+func (a *Association) StoreFiles(ctx context.Context, paths ...string) []CStoreResult {
+	results := make([]CStoreResult, 0, len(paths))
+	var assocErr error
+	for _, path := range paths {
+		if assocErr != nil {
+			results = append(results, CStoreResult{FilePath: path, Err: assocErr})
+			continue
+		}
+		res, _ := a.StoreFile(ctx, path)
+		results = append(results, *res)
+		if a.isClosed() {
+			assocErr = res.Err
+			if assocErr == nil {
+				assocErr = ErrAssociationClosed
+			}
+		}
+	}
+	return results
+}
+
+// storageContext returns the accepted presentation context to send an instance of the SOP class, preferring
+// the original transfer syntax over Explicit then Implicit VR Little Endian for the transcodable instances. On the
+// acceptor side, e.g.: for C-GET sub-operations, the requestor must have accepted the SCP role for the SOP class
+func (a *Association) storageContext(sopClassUID, transferSyntaxUID string) (*PresentationContext, error) {
+	transferSyntaxes := []string{transferSyntaxUID}
+	if transcodable(transferSyntaxUID) {
+		transferSyntaxes = append(transferSyntaxes, defaultTransferSyntaxes...)
+	}
+	for _, ts := range transferSyntaxes {
+		for _, pc := range a.PresentationContexts() {
+			if pc.Accepted() && pc.AbstractSyntax == sopClassUID && pc.TransferSyntax == ts && (a.isRequestor || pc.SCPRole) {
+				return a.contexts[pc.ID], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("network: no accepted presentation context for SOP class %s in transfer syntax %s", sopClassUID, transferSyntaxUID)
+}
+
+// storageFile is a Part 10 file positioned at the start of its data set
+type storageFile struct {
+	file              *os.File
+	data              *bufio.Reader
+	metadata          go2com.Dataset
+	sopClassUID       string
+	sopInstanceUID    string
+	transferSyntaxUID string
+}
+
+// openStorageFile opens the file and reads its file meta information, leaving the data set unread
+func openStorageFile(path string) (*storageFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	sf := &storageFile{file: f, data: bufio.NewReaderSize(f, 64*1024)}
+	err = sf.readFileMeta()
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("network: cannot read file meta information of %s: %v", path, err)
+	}
+	return sf, nil
+}
+
+// readFileMeta parses the preamble and the file meta information delimited by its group length
+func (sf *storageFile) readFileMeta() error {
+	const headerLength = 128 + 4 + 12
+	header, err := sf.data.Peek(headerLength)
+	if err != nil {
+		return err
+	}
+	if string(header[128:132]) != go2com.MagicString {
+		return fmt.Errorf("missing DICM magic string")
+	}
+	if binary.LittleEndian.Uint16(header[132:]) != 0x0002 || binary.LittleEndian.Uint16(header[134:]) != 0x0000 {
+		return fmt.Errorf("missing file meta information group length")
+	}
+	metaLength := headerLength + int(binary.LittleEndian.Uint32(header[140:]))
+	if metaLength > sf.data.Size() {
+		return fmt.Errorf("file meta information too long")
+	}
+	// The reader looks past the file meta information for the first data set element
+	meta, err := sf.data.Peek(metaLength + 8)
+	if err != nil && len(meta) <= metaLength {
+		return err
+	}
+	rd := go2com.NewDICOMReader(bufio.NewReader(bytes.NewReader(meta)), go2com.WithSkipDataset(true))
+	err = rd.Parse()
+	if err != nil {
+		return err
+	}
+	sf.metadata = rd.GetMetadata()
+	for _, elem := range sf.metadata.Elements {
+		value, _ := elem.Value.RawValue.(string)
+		switch elem.Tag {
+		case tag.MediaStorageSOPClassUID:
+			sf.sopClassUID = value
+		case tag.MediaStorageSOPInstanceUID:
+			sf.sopInstanceUID = value
+		case tag.TransferSyntaxUID:
+			sf.transferSyntaxUID = value
+		}
+	}
+	if sf.sopClassUID == "" || sf.sopInstanceUID == "" || sf.transferSyntaxUID == "" {
+		return fmt.Errorf("missing SOP class, SOP instance or transfer syntax UID")
+	}
+	_, err = sf.data.Discard(metaLength)
+	return err
+}
+
+// transcodable returns true if the data sets of the transfer syntax can be transcoded to Explicit or Implicit VR
+// Little Endian. Only the uncompressed data sets are transcoded
+func transcodable(transferSyntaxUID string) bool {
+	switch transferSyntaxUID {
+	case uid.ExplicitVRLittleEndian, uid.ImplicitVRLittleEndian, uid.ExplicitVRBigEndian:
+		return true
+	}
+	return false
+}
+
+// readDataset parses the data set following the file meta information for transcoding. The words of the OW pixel
+// data of a big endian data set, kept as read by the reader, are swapped to little endian
+func (sf *storageFile) readDataset() (go2com.Dataset, error) {
+	byteOrder, implicit, err := uid.ParseTransferSyntaxUID(sf.transferSyntaxUID)
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	fInfo, err := sf.file.Stat()
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	rd := go2com.NewDICOMReader(sf.data, go2com.WithSetFileSize(fInfo.Size()),
+		go2com.WithDatasetTransferSyntax(byteOrder, implicit))
+	err = rd.Parse()
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	ds := rd.GetDataset()
+	if byteOrder == binary.BigEndian {
+		for _, elem := range ds.Elements {
+			b, ok := elem.Value.RawValue.([]byte)
+			if !ok || elem.Tag != tag.PixelData || elem.ValueRepresentationStr != vr.OtherWord {
+				continue
+			}
+			for i := 0; i+1 < len(b); i += 2 {
+				b[i], b[i+1] = b[i+1], b[i]
+			}
+		}
+	}
+	return ds, nil
+}
+
+// Close closes the file
+func (sf *storageFile) Close() error {
+	return sf.file.Close()
+}
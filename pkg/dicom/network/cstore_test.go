@@ -0,0 +1,259 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSOPClassUID = "1.2.840.10008.5.1.4.1.1.7"
+
+// sendStore sends a C-STORE request with a small data set and returns the response status
+func sendStore(t *testing.T, assoc *Association, sopInstanceUID string) uint16 {
+	pc, err := assoc.FindPresentationContext(testSOPClassUID)
+	assert.NoError(t, err)
+	buf := bytes.Buffer{}
+	assert.NoError(t, go2com.NewDICOMWriter(&buf).WriteDataset(go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.SOPClassUID, testSOPClassUID),
+		go2com.NewElement(tag.SOPInstanceUID, sopInstanceUID),
+		go2com.NewElement(tag.PatientName, "DOE^JANE"),
+	}}))
+	cmd := &Command{
+		CommandField:           CStoreRQ,
+		MessageID:              assoc.nextMessageID(),
+		AffectedSOPClassUID:    testSOPClassUID,
+		AffectedSOPInstanceUID: sopInstanceUID,
+	}
+	assert.NoError(t, assoc.writeMessage(pc.ID, cmd, &buf))
+	rsp, err := assoc.readResponse(cmd.MessageID)
+	assert.NoError(t, err)
+	return rsp.Command.Status
+}
+
+func TestCStore_Handler(t *testing.T) {
+	assert := assert.New(t)
+	received := make(chan *CStoreRequest, 1)
+	addr := startServer(t, NewServer("STORE-SCP",
+		WithSupportedContext(testSOPClassUID),
+		WithCStoreHandler(func(ctx context.Context, req *CStoreRequest) uint16 {
+			received <- req
+			return StatusSuccess
+		}),
+	))
+
+	assoc, err := Associate(context.Background(), addr, "STORE-SCU", "STORE-SCP", []PresentationContextProposal{
+		{AbstractSyntax: testSOPClassUID, TransferSyntaxes: []string{uid.ImplicitVRLittleEndian}},
+	}, WithMaxPDULength(64))
+	assert.NoError(err)
+	defer assoc.Release()
+
+	assert.Equal(StatusSuccess, sendStore(t, assoc, "1.2.3.4.5"))
+	req := <-received
+	assert.Equal("STORE-SCU", req.CallingAETitle)
+	assert.Equal("1.2.3.4.5", req.SOPInstanceUID)
+	assert.Equal(uid.ImplicitVRLittleEndian, req.TransferSyntaxUID)
+	elem, err := req.Dataset.FindElementByTag(tag.PatientName)
+	assert.NoError(err)
+	assert.Equal("DOE^JANE", elem.Value.RawValue)
+}
+
+func TestCStore_Directory(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	addr := startServer(t, NewServer("STORE-SCP",
+		WithSupportedContext(testSOPClassUID),
+		WithCStoreDirectory(dir),
+		WithCStoreHandler(func(ctx context.Context, req *CStoreRequest) uint16 {
+			if req.SOPInstanceUID == "1.2.3.6" {
+				return StatusRefusedOutOfResources
+			}
+			return StatusSuccess
+		}),
+	))
+
+	assoc, err := Associate(context.Background(), addr, "STORE-SCU", "STORE-SCP", []PresentationContextProposal{
+		{AbstractSyntax: testSOPClassUID},
+	})
+	assert.NoError(err)
+	defer assoc.Release()
+
+	assert.Equal(StatusSuccess, sendStore(t, assoc, "1.2.3.5"))
+	assert.Equal(StatusRefusedOutOfResources, sendStore(t, assoc, "1.2.3.6"))
+	assert.Equal(StatusCannotUnderstand, sendStore(t, assoc, "../1.2.3.7"))
+
+	f, err := os.Open(filepath.Join(dir, "1.2.3.5.dcm"))
+	assert.NoError(err)
+	defer f.Close()
+	fInfo, err := f.Stat()
+	assert.NoError(err)
+	rd := go2com.NewDICOMReader(bufio.NewReader(f), go2com.WithSetFileSize(fInfo.Size()))
+	assert.NoError(rd.Parse())
+	val, err := rd.GetElementByTagString("(0010,0010)")
+	assert.NoError(err)
+	assert.Equal(go2com.Value{RawValue: "DOE^JANE"}, val)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(err)
+	assert.Len(entries, 2)
+}
+
+func TestServer_MaxAssociationsPerAE(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t, NewServer("ECHO-SCP", WithMaxAssociationsPerAE(1), WithMaxAssociations(2)))
+	proposals := []PresentationContextProposal{{AbstractSyntax: uid.VerificationSOPClass}}
+
+	first, err := Associate(context.Background(), addr, "SCU-A", "ECHO-SCP", proposals)
+	assert.NoError(err)
+
+	_, err = Associate(context.Background(), addr, "SCU-A", "ECHO-SCP", proposals)
+	assert.Error(err)
+	rj, ok := err.(*RejectedError)
+	assert.True(ok)
+	assert.Equal(pdu.RejectResultTransient, rj.Result)
+	assert.Equal(pdu.RejectReasonLocalLimitExceeded, rj.Reason)
+
+	second, err := Associate(context.Background(), addr, "SCU-B", "ECHO-SCP", proposals)
+	assert.NoError(err)
+	_, err = Associate(context.Background(), addr, "SCU-C", "ECHO-SCP", proposals)
+	assert.Error(err)
+
+	assert.NoError(first.Release())
+	assert.NoError(second.Release())
+	assert.Eventually(func() bool {
+		assoc, err := Associate(context.Background(), addr, "SCU-A", "ECHO-SCP", proposals)
+		if err != nil {
+			return false
+		}
+		return assoc.Release() == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServer_MaxAssociationsReleasedOnAbort(t *testing.T) {
+	assert := assert.New(t)
+	srv := NewServer("ECHO-SCP", WithMaxAssociations(1))
+	addr := startServer(t, srv)
+	proposals := []PresentationContextProposal{{AbstractSyntax: uid.VerificationSOPClass}}
+
+	assoc, err := Associate(context.Background(), addr, "SCU-A", "ECHO-SCP", proposals)
+	if assert.NoError(err) {
+		assert.NoError(assoc.Release())
+	}
+
+	// The associations accepted while the server is closing are aborted, after their slot was reserved
+	srv.mu.Lock()
+	srv.closed = true
+	srv.mu.Unlock()
+	assoc, err = Associate(context.Background(), addr, "SCU-A", "ECHO-SCP", proposals)
+	if err == nil {
+		err = assoc.Echo(context.Background())
+	}
+	assert.Error(err)
+	srv.mu.Lock()
+	srv.closed = false
+	srv.mu.Unlock()
+
+	assert.Eventually(func() bool {
+		assoc, err := Associate(context.Background(), addr, "SCU-A", "ECHO-SCP", proposals)
+		if err != nil {
+			return false
+		}
+		return assoc.Release() == nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestStoreFiles(t *testing.T) {
+	assert := assert.New(t)
+	received := make(map[string]*CStoreRequest)
+	mu := sync.Mutex{}
+	addr := startServer(t, NewServer("STORE-SCP",
+		WithSupportedContext("1.2.840.10008.5.1.4.1.1.4", uid.ExplicitVRLittleEndian),
+		WithSupportedContext("1.2.840.10008.5.1.4.1.1.1.1", uid.JPEG2000ImageCompressionLosslessOnly),
+		WithSupportedContext("1.2.840.10008.5.1.4.1.1.7"),
+		WithCStoreHandler(func(ctx context.Context, req *CStoreRequest) uint16 {
+			mu.Lock()
+			defer mu.Unlock()
+			received[req.SOPInstanceUID] = req
+			return StatusSuccess
+		}),
+	))
+
+	paths := []string{
+		"../../../dicom_test/010.dcm", // Implicit VR Little Endian, transcoded
+		"../../../dicom_test/014.dcm", // Explicit VR Little Endian
+		"../../../dicom_test/013.dcm", // JPEG 2000, sent as is
+		"../../../dicom_test/026.dcm", // JPEG baseline, not accepted and not transcodable
+		"../../../dicom_test/022.dcm", // Explicit VR Big Endian, transcoded
+	}
+	proposals, err := ProposeStorageContexts(paths...)
+	assert.NoError(err)
+	// The compressed files are only proposed in their original transfer syntax
+	assert.Equal([]PresentationContextProposal{
+		{AbstractSyntax: "1.2.840.10008.5.1.4.1.1.4", TransferSyntaxes: defaultTransferSyntaxes},
+		{AbstractSyntax: "1.2.840.10008.5.1.4.1.1.1.1", TransferSyntaxes: []string{uid.JPEG2000ImageCompressionLosslessOnly}},
+		{AbstractSyntax: "1.2.840.10008.5.1.4.1.1.7", TransferSyntaxes: []string{uid.JPEGBaselineProcess1}},
+	}, proposals)
+
+	assoc, err := Associate(context.Background(), addr, "STORE-SCU", "STORE-SCP", proposals, WithMaxPDULength(4096))
+	if !assert.NoError(err) {
+		return
+	}
+	results := assoc.StoreFiles(context.Background(), paths...)
+	assert.NoError(assoc.Release())
+
+	assert.Len(results, 5)
+	assert.NoError(results[0].Err)
+	assert.Equal(uid.ExplicitVRLittleEndian, results[0].TransferSyntaxUID)
+	assert.NoError(results[1].Err)
+	assert.NoError(results[2].Err)
+	assert.Equal(uid.JPEG2000ImageCompressionLosslessOnly, results[2].TransferSyntaxUID)
+	assert.Error(results[3].Err)
+	assert.NoError(results[4].Err)
+	assert.Equal(uid.ExplicitVRLittleEndian, results[4].TransferSyntaxUID)
+	assert.Len(received, 4)
+
+	// The transcoded instances hold the same elements as the original files
+	for _, i := range []int{0, 4} {
+		f, err := os.Open(paths[i])
+		assert.NoError(err)
+		defer f.Close()
+		fInfo, err := f.Stat()
+		assert.NoError(err)
+		rd := go2com.NewDICOMReader(bufio.NewReader(f), go2com.WithSetFileSize(fInfo.Size()))
+		assert.NoError(rd.Parse())
+		original := rd.GetDataset()
+		req := received[results[i].SOPInstanceUID]
+		if !assert.NotNil(req) {
+			continue
+		}
+		for _, t := range []tag.DicomTag{tag.PatientName, tag.Rows, tag.PixelSpacing} {
+			expected, err := original.FindElementByTag(t)
+			assert.NoError(err)
+			actual, err := req.Dataset.FindElementByTag(t)
+			assert.NoError(err)
+			assert.Equal(expected.Value, actual.Value)
+		}
+		expected, err := original.FindElementByTag(tag.PixelData)
+		assert.NoError(err)
+		actual, err := req.Dataset.FindElementByTag(tag.PixelData)
+		assert.NoError(err)
+		pixels := append([]byte{}, expected.Value.RawValue.([]byte)...)
+		if i == 4 {
+			// The words of the big endian pixel data are swapped
+			for j := 0; j+1 < len(pixels); j += 2 {
+				pixels[j], pixels[j+1] = pixels[j+1], pixels[j]
+			}
+		}
+		assert.Equal(pixels, actual.Value.RawValue)
+	}
+}
@@ -0,0 +1,39 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// encodeDataset encodes the data set in the transfer syntax of a presentation context
+func encodeDataset(ds go2com.Dataset, transferSyntaxUID string) ([]byte, error) {
+	binOrder, isImplicit, err := uid.ParseTransferSyntaxUID(transferSyntaxUID)
+	if err != nil {
+		return nil, err
+	}
+	buf := bytes.Buffer{}
+	err = go2com.NewDICOMWriter(&buf, go2com.WithWriterTransferSyntax(binOrder, isImplicit)).WriteDataset(ds)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeDataset parses a data set received in the transfer syntax of a presentation context. The data set is parsed in
+// lenient mode, as some peers encode it in a transfer syntax other than the one of the presentation context
+func decodeDataset(data []byte, transferSyntaxUID string) (go2com.Dataset, error) {
+	binOrder, isImplicit, err := uid.ParseTransferSyntaxUID(transferSyntaxUID)
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	rd := go2com.NewDICOMReader(bufio.NewReader(bytes.NewReader(data)), go2com.WithSetFileSize(int64(len(data))),
+		go2com.WithDatasetTransferSyntax(binOrder, isImplicit), go2com.WithAllowNonCompliantDcm(true))
+	err = rd.Parse()
+	if err != nil {
+		return go2com.Dataset{}, err
+	}
+	return rd.GetDataset(), nil
+}
@@ -0,0 +1,48 @@
+package network
+
+import (
+	"context"
+	"io"
+
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// Echo sends a C-ECHO request on the Verification presentation context and waits for the response
+func (a *Association) Echo(ctx context.Context) error {
+	pc, err := a.FindPresentationContext(uid.VerificationSOPClass)
+	if err != nil {
+		return err
+	}
+	stop := a.watch(ctx)
+	defer stop()
+
+	cmd := &Command{
+		CommandField:        CEchoRQ,
+		MessageID:           a.nextMessageID(),
+		AffectedSOPClassUID: uid.VerificationSOPClass,
+	}
+	wait := a.expectResponse(cmd.MessageID)
+	err = a.writeMessage(pc.ID, cmd, nil)
+	if err != nil {
+		return err
+	}
+	rsp, err := wait()
+	if err != nil {
+		return err
+	}
+	if rsp.Command.Status != StatusSuccess {
+		return &StatusError{Status: rsp.Command.Status, ErrorComment: rsp.Command.ErrorComment}
+	}
+	return nil
+}
+
+// handleEcho answers a C-ECHO request with a success status
+func handleEcho(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+	if cmd.HasDataset {
+		err := a.readData(pcID, io.Discard)
+		if err != nil {
+			return err
+		}
+	}
+	return a.sendResponse(pcID, cmd, StatusSuccess)
+}
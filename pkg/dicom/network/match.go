@@ -0,0 +1,211 @@
+package network
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// Match returns true if the data set matches all the keys of the C-FIND identifier, following the attribute
+// matching rules of PS3.4 C.2.2.2:
+//   - single value matching, case-insensitive for PN
+//   - universal matching for zero length keys
+//   - wildcard matching with '*' and '?', except for date, time and UI attributes
+//   - range matching for DA, TM and DT with "<from>-<to>", "<from>-" or "-<to>"
+//   - list of UID matching for UI keys with multiple values
+//   - sequence matching, where one item of the data set must match the item of the key
+//
+// The QueryRetrieveLevel and SpecificCharacterSet keys are ignored
+func Match(identifier, ds go2com.Dataset) bool {
+	return matchElements(identifier.Elements, ds.Elements)
+}
+
+func matchElements(keys, elements []*go2com.Element) bool {
+	for _, key := range keys {
+		if key == nil || key.Tag == tag.QueryRetrieveLevel || key.Tag == tag.SpecificCharacterSet {
+			continue
+		}
+		var elem *go2com.Element
+		for _, e := range elements {
+			if e != nil && e.Tag == key.Tag {
+				elem = e
+				break
+			}
+		}
+		if !matchElement(key, elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchElement(key, elem *go2com.Element) bool {
+	if keyItems, ok := key.Value.RawValue.([]*go2com.Element); ok || key.ValueRepresentationStr == vr.SequenceOfItems {
+		if len(keyItems) == 0 {
+			return true
+		}
+		if elem == nil {
+			return false
+		}
+		// The elements of all the items of a parsed sequence are flattened, so the items are split on the tag order
+		for _, item := range sequenceItems(elem) {
+			if matchElements(keyItems, item) {
+				return true
+			}
+		}
+		return false
+	}
+
+	keyValues := valueStrings(key.Value.RawValue)
+	if len(keyValues) == 0 || (len(keyValues) == 1 && keyValues[0] == "") {
+		return true
+	}
+	if elem == nil {
+		return false
+	}
+	values := valueStrings(elem.Value.RawValue)
+	valueRepresentation := key.ValueRepresentationStr
+
+	switch valueRepresentation {
+	case vr.UniqueIdentifier:
+		for _, keyValue := range keyValues {
+			for _, v := range values {
+				if keyValue == v {
+					return true
+				}
+			}
+		}
+		return false
+	case vr.Date, vr.Time, vr.DateTime:
+		keyValue := strings.Join(keyValues, "\\")
+		for _, v := range values {
+			if matchRange(keyValue, v) {
+				return true
+			}
+		}
+		return false
+	default:
+		keyValue := strings.Join(keyValues, "\\")
+		if valueRepresentation == vr.PersonName {
+			keyValue = strings.ToUpper(keyValue)
+		}
+		for _, v := range values {
+			if valueRepresentation == vr.PersonName {
+				v = strings.ToUpper(v)
+			}
+			if matchWildcard(keyValue, v) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// matchRange matches a date, time or date time value against a single value or a range
+func matchRange(keyValue, v string) bool {
+	idx := strings.Index(keyValue, "-")
+	// A negative UTC offset of a DT value is not a range separator
+	if idx < 0 || strings.Count(keyValue, "-") == 1 && idx > 0 && idx == len(keyValue)-5 && len(keyValue) > 14 {
+		return keyValue == v || (len(keyValue) < len(v) && strings.HasPrefix(v, keyValue))
+	}
+	from, to := keyValue[:idx], keyValue[idx+1:]
+	if from != "" && v < from {
+		return false
+	}
+	if to != "" && v > to && !strings.HasPrefix(v, to) {
+		return false
+	}
+	return true
+}
+
+// matchWildcard matches the value against a key where '*' matches any sequence of characters and '?' any single
+// character
+func matchWildcard(key, v string) bool {
+	if !strings.ContainsAny(key, "*?") {
+		return key == v
+	}
+	k, s := []rune(key), []rune(v)
+	ki, si := 0, 0
+	star, mark := -1, 0
+	for si < len(s) {
+		switch {
+		case ki < len(k) && (k[ki] == '?' || k[ki] == s[si]):
+			ki++
+			si++
+		case ki < len(k) && k[ki] == '*':
+			star = ki
+			mark = si
+			ki++
+		case star >= 0:
+			ki = star + 1
+			mark++
+			si = mark
+		default:
+			return false
+		}
+	}
+	for ki < len(k) && k[ki] == '*' {
+		ki++
+	}
+	return ki == len(k)
+}
+
+// valueStrings returns the values of a parsed element as strings
+func valueStrings(rawValue interface{}) []string {
+	switch v := rawValue.(type) {
+	case nil:
+		return nil
+	case string:
+		return strings.Split(v, "\\")
+	case []string:
+		return v
+	case []int:
+		res := make([]string, 0, len(v))
+		for _, i := range v {
+			res = append(res, fmt.Sprint(i))
+		}
+		return res
+	case []float64:
+		res := make([]string, 0, len(v))
+		for _, f := range v {
+			res = append(res, fmt.Sprint(f))
+		}
+		return res
+	case []byte:
+		return []string{strings.TrimRight(string(v), " \x00")}
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
+
+// sequenceItems splits the elements of a parsed sequence into its items, starting a new item whenever a tag is
+// not greater than the previous one
+func sequenceItems(elem *go2com.Element) [][]*go2com.Element {
+	elements, _ := elem.Value.RawValue.([]*go2com.Element)
+	items := make([][]*go2com.Element, 0)
+	var current []*go2com.Element
+	for _, e := range elements {
+		if e == nil {
+			continue
+		}
+		if len(current) > 0 && !tagLess(current[len(current)-1].Tag, e.Tag) {
+			items = append(items, current)
+			current = nil
+		}
+		current = append(current, e)
+	}
+	if len(current) > 0 {
+		items = append(items, current)
+	}
+	return items
+}
+
+func tagLess(a, b tag.DicomTag) bool {
+	if a.Group != b.Group {
+		return a.Group < b.Group
+	}
+	return a.Element < b.Element
+}
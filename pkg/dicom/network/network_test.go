@@ -0,0 +1,166 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+// startServer starts the server on a loopback port and returns its address
+func startServer(t *testing.T, srv *Server) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		_ = srv.Serve(l)
+	}()
+	t.Cleanup(func() {
+		_ = srv.Close()
+	})
+	return l.Addr().String()
+}
+
+func TestCommand_EncodeDecode(t *testing.T) {
+	assert := assert.New(t)
+
+	cmd := &Command{
+		CommandField:              CMoveRSP,
+		AffectedSOPClassUID:       uid.StudyRootQRMove,
+		MessageIDBeingRespondedTo: 7,
+		HasDataset:                false,
+		Status:                    StatusPending,
+		OffendingElement:          []tag.DicomTag{tag.PatientID},
+		ErrorComment:              "odd",
+		SubOperations:             &SubOperations{Remaining: 3, Completed: 2, Failed: 1},
+	}
+	res, err := DecodeCommand(cmd.Encode())
+	assert.NoError(err)
+	assert.Equal(cmd, res)
+
+	_, err = DecodeCommand([]byte{0, 0, 0, 1})
+	assert.Error(err)
+}
+
+func TestEcho(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t, NewServer("ECHO-SCP"))
+
+	// A small maximum PDU length forces the command to be fragmented
+	assoc, err := Associate(context.Background(), addr, "ECHO-SCU", "ECHO-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.VerificationSOPClass},
+		{AbstractSyntax: uid.StudyRootQRFind},
+	}, WithMaxPDULength(32), WithARTIMTimeout(time.Second))
+	assert.NoError(err)
+
+	contexts := assoc.PresentationContexts()
+	assert.Len(contexts, 2)
+	assert.True(contexts[0].Accepted())
+	assert.Equal(uid.ExplicitVRLittleEndian, contexts[0].TransferSyntax)
+	assert.Equal(pdu.ResultAbstractSyntaxNotSupported, contexts[1].Result)
+	implClass, _ := assoc.PeerImplementation()
+	assert.Equal(ImplementationClassUID, implClass)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(assoc.Echo(context.Background()))
+	}
+	assert.NoError(assoc.Release())
+	assert.Equal(ErrAssociationClosed, assoc.Echo(context.Background()))
+}
+
+func TestDispatchResponse(t *testing.T) {
+	assert := assert.New(t)
+	a := &Association{dispatched: true}
+
+	// The pending responses are queued until the final response
+	wait := a.expectResponse(7)
+	for _, status := range []uint16{StatusPending, StatusPending, StatusSuccess} {
+		assert.NoError(a.dispatchResponse(1, &Command{CommandField: CFindRSP, MessageIDBeingRespondedTo: 7, Status: status}))
+	}
+	assert.NoError(a.dispatchResponse(1, &Command{CommandField: CEchoRSP, MessageIDBeingRespondedTo: 8}))
+	for _, status := range []uint16{StatusPending, StatusPending, StatusSuccess} {
+		msg, err := wait()
+		if assert.NoError(err) {
+			assert.Equal(status, msg.Command.Status)
+		}
+	}
+	assert.Empty(a.responses)
+
+	wait = a.expectResponse(9)
+	a.stopOperations()
+	_, err := wait()
+	assert.Equal(ErrAssociationClosed, err)
+}
+
+func TestEcho_SmallServerPDU(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t, NewServer("ECHO-SCP", WithServerMaxPDULength(24)))
+
+	assoc, err := Associate(context.Background(), addr, "ECHO-SCU", "ECHO-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.VerificationSOPClass, TransferSyntaxes: []string{uid.ImplicitVRLittleEndian}},
+	})
+	assert.NoError(err)
+	assert.Equal(uint32(24), assoc.PeerMaxPDULength())
+	assert.NoError(assoc.Echo(context.Background()))
+	assert.NoError(assoc.Release())
+}
+
+func TestAssociate_Rejected(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t, NewServer("ECHO-SCP", WithServerARTIMTimeout(time.Second)))
+
+	_, err := Associate(context.Background(), addr, "ECHO-SCU", "WRONG", []PresentationContextProposal{
+		{AbstractSyntax: uid.VerificationSOPClass},
+	})
+	assert.Error(err)
+	rj, ok := err.(*RejectedError)
+	assert.True(ok)
+	assert.Equal(pdu.RejectReasonCalledAETitleNotRecognized, rj.Reason)
+
+	_, err = Associate(context.Background(), addr, "ECHO-SCU", "ECHO-SCP", nil)
+	assert.Error(err)
+}
+
+func TestAssociate_ARTIMTimeout(t *testing.T) {
+	assert := assert.New(t)
+
+	// A listener that never answers the association request
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err == nil {
+			time.Sleep(time.Second)
+			_ = conn.Close()
+		}
+	}()
+
+	start := time.Now()
+	_, err = Associate(context.Background(), l.Addr().String(), "ECHO-SCU", "ECHO-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.VerificationSOPClass},
+	}, WithARTIMTimeout(100*time.Millisecond))
+	assert.Error(err)
+	assert.Less(time.Since(start), time.Second)
+}
+
+func TestServer_Close(t *testing.T) {
+	assert := assert.New(t)
+	srv := NewServer("ECHO-SCP")
+	addr := startServer(t, srv)
+
+	assoc, err := Associate(context.Background(), addr, "ECHO-SCU", "ECHO-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.VerificationSOPClass},
+	})
+	assert.NoError(err)
+	assert.NoError(srv.Close())
+
+	err = assoc.Echo(context.Background())
+	assert.Error(err)
+	_, ok := err.(*AbortError)
+	assert.True(ok)
+}
@@ -0,0 +1,250 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+// NResponse holds the response to a DIMSE-N request
+type NResponse struct {
+	Status                 uint16
+	ErrorComment           string
+	AffectedSOPInstanceUID string
+	// Dataset is the data set of the response, nil if none
+	Dataset *go2com.Dataset
+}
+
+// NEventReport sends an N-EVENT-REPORT request for the SOP instance and waits for the response
+func (a *Association) NEventReport(ctx context.Context, sopClassUID, sopInstanceUID string, eventTypeID uint16, info *go2com.Dataset) (*NResponse, error) {
+	return a.requestN(ctx, &Command{
+		CommandField:           NEventReportRQ,
+		AffectedSOPClassUID:    sopClassUID,
+		AffectedSOPInstanceUID: sopInstanceUID,
+		EventTypeID:            eventTypeID,
+	}, info)
+}
+
+// NGet sends an N-GET request for the attributes of the SOP instance, all of them if attributes is empty, and
+// waits for the response
+func (a *Association) NGet(ctx context.Context, sopClassUID, sopInstanceUID string, attributes []tag.DicomTag) (*NResponse, error) {
+	return a.requestN(ctx, &Command{
+		CommandField:            NGetRQ,
+		RequestedSOPClassUID:    sopClassUID,
+		RequestedSOPInstanceUID: sopInstanceUID,
+		AttributeIdentifierList: attributes,
+	}, nil)
+}
+
+// NSet sends an N-SET request with the modifications of the SOP instance and waits for the response
+func (a *Association) NSet(ctx context.Context, sopClassUID, sopInstanceUID string, modifications go2com.Dataset) (*NResponse, error) {
+	return a.requestN(ctx, &Command{
+		CommandField:            NSetRQ,
+		RequestedSOPClassUID:    sopClassUID,
+		RequestedSOPInstanceUID: sopInstanceUID,
+	}, &modifications)
+}
+
+// NAction sends an N-ACTION request for the SOP instance and waits for the response
+func (a *Association) NAction(ctx context.Context, sopClassUID, sopInstanceUID string, actionTypeID uint16, info *go2com.Dataset) (*NResponse, error) {
+	return a.requestN(ctx, &Command{
+		CommandField:            NActionRQ,
+		RequestedSOPClassUID:    sopClassUID,
+		RequestedSOPInstanceUID: sopInstanceUID,
+		ActionTypeID:            actionTypeID,
+	}, info)
+}
+
+// NCreate sends an N-CREATE request and waits for the response. When sopInstanceUID is empty, the SCP assigns
+// the UID of the new instance, returned in the AffectedSOPInstanceUID of the response
+func (a *Association) NCreate(ctx context.Context, sopClassUID, sopInstanceUID string, attributes *go2com.Dataset) (*NResponse, error) {
+	return a.requestN(ctx, &Command{
+		CommandField:           NCreateRQ,
+		AffectedSOPClassUID:    sopClassUID,
+		AffectedSOPInstanceUID: sopInstanceUID,
+	}, attributes)
+}
+
+// requestN sends the DIMSE-N request with the optional data set and waits for the response. A failure status is
+// returned as a StatusError along with the response
+func (a *Association) requestN(ctx context.Context, cmd *Command, ds *go2com.Dataset) (*NResponse, error) {
+	sopClassUID, sopInstanceUID := cmd.AffectedSOPClassUID, cmd.AffectedSOPInstanceUID
+	if sopClassUID == "" {
+		sopClassUID, sopInstanceUID = cmd.RequestedSOPClassUID, cmd.RequestedSOPInstanceUID
+	}
+	pc, err := a.FindPresentationContext(sopClassUID)
+	if err != nil {
+		return nil, err
+	}
+	var data io.Reader
+	if ds != nil {
+		b, err := encodeDataset(*ds, pc.TransferSyntax)
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.NewReader(b)
+	}
+	stop := a.watch(ctx)
+	defer stop()
+
+	cmd.MessageID = a.nextMessageID()
+	wait := a.expectResponse(cmd.MessageID)
+	err = a.writeMessage(pc.ID, cmd, data)
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := wait()
+	if err != nil {
+		return nil, err
+	}
+
+	res := &NResponse{
+		Status:                 rsp.Command.Status,
+		ErrorComment:           rsp.Command.ErrorComment,
+		AffectedSOPInstanceUID: rsp.Command.AffectedSOPInstanceUID,
+	}
+	if res.AffectedSOPInstanceUID == "" {
+		res.AffectedSOPInstanceUID = sopInstanceUID
+	}
+	if rsp.Data != nil {
+		ds, err := decodeDataset(rsp.Data, pc.TransferSyntax)
+		if err != nil {
+			return res, err
+		}
+		res.Dataset = &ds
+	}
+	if !IsSuccessStatus(res.Status) {
+		return res, &StatusError{Status: res.Status, ErrorComment: res.ErrorComment}
+	}
+	return res, nil
+}
+
+// NRequest holds a DIMSE-N request received by the SCP
+type NRequest struct {
+	CallingAETitle string
+	CalledAETitle  string
+	RemoteAddr     net.Addr
+	// CommandField is one of NEventReportRQ, NGetRQ, NSetRQ, NActionRQ, NCreateRQ or NDeleteRQ
+	CommandField   uint16
+	SOPClassUID    string
+	SOPInstanceUID string
+	// EventTypeID is set for N-EVENT-REPORT requests
+	EventTypeID uint16
+	// ActionTypeID is set for N-ACTION requests
+	ActionTypeID uint16
+	// AttributeIdentifierList holds the requested attributes of N-GET requests
+	AttributeIdentifierList []tag.DicomTag
+	// Dataset is the data set of the request, nil if none
+	Dataset *go2com.Dataset
+}
+
+// NServiceHandler processes a DIMSE-N request and returns its response. The association may be used to send
+// requests to the SCU, such as N-EVENT-REPORT, once the handler has returned
+type NServiceHandler func(ctx context.Context, a *Association, req *NRequest) *NResponse
+
+// WithNServiceHandler provides option to accept the DIMSE-N requests of the SOP class with the handler. The SOP
+// class must be accepted with WithSupportedContext
+func WithNServiceHandler(sopClassUID string, handler NServiceHandler) func(*Server) {
+	return func(s *Server) {
+		s.registerN(sopClassUID, func(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+			req, err := readNRequest(a, pcID, cmd)
+			if err != nil || req == nil {
+				return err
+			}
+			return sendNResponse(a, pcID, cmd, handler(ctx, a, req))
+		})
+	}
+}
+
+// registerN routes the DIMSE-N requests of the SOP class to the handler
+func (s *Server) registerN(sopClassUID string, handler serviceHandler) {
+	s.nHandlers[sopClassUID] = handler
+	for _, commandField := range []uint16{NEventReportRQ, NGetRQ, NSetRQ, NActionRQ, NCreateRQ, NDeleteRQ} {
+		s.handlers[commandField] = s.handleN
+	}
+}
+
+// handleN dispatches a DIMSE-N request to the handler of its SOP class
+func (s *Server) handleN(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+	sopClassUID := cmd.AffectedSOPClassUID
+	if sopClassUID == "" {
+		sopClassUID = cmd.RequestedSOPClassUID
+	}
+	handler, ok := s.nHandlers[sopClassUID]
+	if !ok {
+		if cmd.HasDataset {
+			err := a.readData(pcID, io.Discard)
+			if err != nil {
+				return err
+			}
+		}
+		return sendNResponse(a, pcID, cmd, &NResponse{Status: StatusNoSuchSOPClass})
+	}
+	return handler(ctx, a, pcID, cmd)
+}
+
+// readNRequest reads the data set of the DIMSE-N request. If the data set cannot be decoded, a processing
+// failure is sent and the returned request is nil
+func readNRequest(a *Association, pcID byte, cmd *Command) (*NRequest, error) {
+	req := &NRequest{
+		CallingAETitle:          a.CallingAETitle(),
+		CalledAETitle:           a.CalledAETitle(),
+		RemoteAddr:              a.RemoteAddr(),
+		CommandField:            cmd.CommandField,
+		SOPClassUID:             cmd.AffectedSOPClassUID,
+		SOPInstanceUID:          cmd.AffectedSOPInstanceUID,
+		EventTypeID:             cmd.EventTypeID,
+		ActionTypeID:            cmd.ActionTypeID,
+		AttributeIdentifierList: cmd.AttributeIdentifierList,
+	}
+	if req.SOPClassUID == "" {
+		req.SOPClassUID, req.SOPInstanceUID = cmd.RequestedSOPClassUID, cmd.RequestedSOPInstanceUID
+	}
+	if !cmd.HasDataset {
+		return req, nil
+	}
+	buf := bytes.Buffer{}
+	err := a.readData(pcID, &buf)
+	if err != nil {
+		return nil, err
+	}
+	ds, err := decodeDataset(buf.Bytes(), a.contexts[pcID].TransferSyntax)
+	if err != nil {
+		return nil, sendNResponse(a, pcID, cmd, &NResponse{Status: StatusProcessingFailure, ErrorComment: truncateComment(err.Error())})
+	}
+	req.Dataset = &ds
+	return req, nil
+}
+
+// sendNResponse answers the DIMSE-N request with the response of the handler
+func sendNResponse(a *Association, pcID byte, req *Command, res *NResponse) error {
+	if res == nil {
+		res = &NResponse{Status: StatusProcessingFailure}
+	}
+	rsp := responseTo(req, res.Status)
+	if rsp.AffectedSOPClassUID == "" {
+		rsp.AffectedSOPClassUID = req.RequestedSOPClassUID
+	}
+	switch {
+	case res.AffectedSOPInstanceUID != "":
+		rsp.AffectedSOPInstanceUID = res.AffectedSOPInstanceUID
+	case rsp.AffectedSOPInstanceUID == "":
+		rsp.AffectedSOPInstanceUID = req.RequestedSOPInstanceUID
+	}
+	rsp.ErrorComment = truncateComment(res.ErrorComment)
+	rsp.EventTypeID = req.EventTypeID
+	rsp.ActionTypeID = req.ActionTypeID
+	if res.Dataset == nil {
+		return a.writeMessage(pcID, rsp, nil)
+	}
+	data, err := encodeDataset(*res.Dataset, a.contexts[pcID].TransferSyntax)
+	if err != nil {
+		rsp.Status = StatusProcessingFailure
+		return a.writeMessage(pcID, rsp, nil)
+	}
+	return a.writeMessage(pcID, rsp, bytes.NewReader(data))
+}
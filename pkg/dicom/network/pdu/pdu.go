@@ -0,0 +1,761 @@
+package pdu
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Type is the PDU type defined in PS3.8 9.3
+type Type byte
+
+const (
+	TypeAssociateRQ Type = 0x01
+	TypeAssociateAC Type = 0x02
+	TypeAssociateRJ Type = 0x03
+	TypePDataTF     Type = 0x04
+	TypeReleaseRQ   Type = 0x05
+	TypeReleaseRP   Type = 0x06
+	TypeAbort       Type = 0x07
+)
+
+// Item types of the variable fields of the A-ASSOCIATE-RQ and A-ASSOCIATE-AC PDUs
+const (
+	ItemApplicationContext      byte = 0x10
+	ItemPresentationContextRQ   byte = 0x20
+	ItemPresentationContextAC   byte = 0x21
+	ItemAbstractSyntax          byte = 0x30
+	ItemTransferSyntax          byte = 0x40
+	ItemUserInformation         byte = 0x50
+	ItemMaximumLength           byte = 0x51
+	ItemImplementationClassUID  byte = 0x52
+	ItemAsynchronousOperations  byte = 0x53
+	ItemRoleSelection           byte = 0x54
+	ItemImplementationVersion   byte = 0x55
+	ItemSOPClassExtendedNego    byte = 0x56
+	ItemSOPClassCommonExtNego   byte = 0x57
+	ItemUserIdentity            byte = 0x58
+	ItemUserIdentityServerReply byte = 0x59
+)
+
+const (
+	// ProtocolVersion is the only protocol version defined by the standard
+	ProtocolVersion uint16 = 0x0001
+	// ApplicationContextName is the DICOM Application Context Name
+	ApplicationContextName = "1.2.840.10008.3.1.1.1"
+	// DefaultMaxPDULength is the maximum PDU length advertised when none is configured
+	DefaultMaxPDULength uint32 = 16384
+	// headerLength is the length of the common PDU header: type, reserved and PDU length
+	headerLength = 6
+	// maxControlPDULength is the maximum length of the PDUs other than P-DATA-TF, e.g.: an A-ASSOCIATE-RQ of 128
+	// presentation contexts of many transfer syntaxes, and of user information of up to 64 KiB
+	maxControlPDULength uint32 = 1024 * 1024
+	// maxItemLength is the maximum length of the items of the A-ASSOCIATE-RQ and AC PDUs, of 16-bit length
+	maxItemLength = 0xFFFF
+	// maxPDataTFLength is the maximum length of a P-DATA-TF PDU when no maximum length is set
+	maxPDataTFLength uint32 = 16 * 1024 * 1024
+)
+
+// Presentation context results of the A-ASSOCIATE-AC PDU
+const (
+	ResultAcceptance                   byte = 0
+	ResultUserRejection                byte = 1
+	ResultNoReasonProviderRejection    byte = 2
+	ResultAbstractSyntaxNotSupported   byte = 3
+	ResultTransferSyntaxesNotSupported byte = 4
+)
+
+// A-ASSOCIATE-RJ result, source and reason values
+const (
+	RejectResultPermanent byte = 1
+	RejectResultTransient byte = 2
+
+	RejectSourceServiceUser                 byte = 1
+	RejectSourceServiceProviderACSE         byte = 2
+	RejectSourceServiceProviderPresentation byte = 3
+
+	// Reasons when the source is the service user
+	RejectReasonNoReasonGiven                      byte = 1
+	RejectReasonApplicationContextNameNotSupported byte = 2
+	RejectReasonCallingAETitleNotRecognized        byte = 3
+	RejectReasonCalledAETitleNotRecognized         byte = 7
+	// Reasons when the source is the ACSE service provider
+	RejectReasonProtocolVersionNotSupported byte = 2
+	// Reasons when the source is the presentation service provider
+	RejectReasonTemporaryCongestion byte = 1
+	RejectReasonLocalLimitExceeded  byte = 2
+)
+
+// A-ABORT source and reason values
+const (
+	AbortSourceServiceUser     byte = 0
+	AbortSourceServiceProvider byte = 2
+
+	AbortReasonNotSpecified             byte = 0
+	AbortReasonUnrecognizedPDU          byte = 1
+	AbortReasonUnexpectedPDU            byte = 2
+	AbortReasonUnrecognizedPDUParameter byte = 4
+	AbortReasonUnexpectedPDUParameter   byte = 5
+	AbortReasonInvalidPDUParameterValue byte = 6
+)
+
+// PDU is implemented by every protocol data unit
+type PDU interface {
+	Type() Type
+	encode(buf *bytes.Buffer) error
+}
+
+// RoleSelection is the SCP/SCU Role Selection sub-item (PS3.7 D.3.3.4)
+type RoleSelection struct {
+	SOPClassUID string
+	SCURole     bool
+	SCPRole     bool
+}
+
+// UserInformation holds the sub-items of the User Information item
+type UserInformation struct {
+	MaxPDULength              uint32
+	ImplementationClassUID    string
+	ImplementationVersionName string
+	// MaxOperationsInvoked and MaxOperationsPerformed are only sent when one of them is not zero
+	MaxOperationsInvoked   uint16
+	MaxOperationsPerformed uint16
+	RoleSelections         []RoleSelection
+	// Unknown keeps the raw sub-items that are not interpreted, e.g.: extended negotiation or user identity
+	Unknown []RawItem
+}
+
+// RawItem is a variable item kept in its raw form
+type RawItem struct {
+	Type byte
+	Data []byte
+}
+
+// PresentationContextRQ is a presentation context proposed by the association requestor
+type PresentationContextRQ struct {
+	ID               byte
+	AbstractSyntax   string
+	TransferSyntaxes []string
+}
+
+// PresentationContextAC is the answer of the association acceptor to a proposed presentation context
+type PresentationContextAC struct {
+	ID             byte
+	Result         byte
+	TransferSyntax string
+}
+
+// AssociateRQ is the A-ASSOCIATE-RQ PDU
+type AssociateRQ struct {
+	ProtocolVersion      uint16
+	CalledAETitle        string
+	CallingAETitle       string
+	ApplicationContext   string
+	PresentationContexts []PresentationContextRQ
+	UserInformation      UserInformation
+}
+
+// AssociateAC is the A-ASSOCIATE-AC PDU
+type AssociateAC struct {
+	ProtocolVersion      uint16
+	CalledAETitle        string
+	CallingAETitle       string
+	ApplicationContext   string
+	PresentationContexts []PresentationContextAC
+	UserInformation      UserInformation
+}
+
+// AssociateRJ is the A-ASSOCIATE-RJ PDU
+type AssociateRJ struct {
+	Result byte
+	Source byte
+	Reason byte
+}
+
+// PDV is a Presentation Data Value item of a P-DATA-TF PDU
+type PDV struct {
+	PresentationContextID byte
+	IsCommand             bool
+	IsLast                bool
+	Data                  []byte
+}
+
+// PDataTF is the P-DATA-TF PDU
+type PDataTF struct {
+	Items []PDV
+}
+
+// ReleaseRQ is the A-RELEASE-RQ PDU
+type ReleaseRQ struct{}
+
+// ReleaseRP is the A-RELEASE-RP PDU
+type ReleaseRP struct{}
+
+// Abort is the A-ABORT PDU
+type Abort struct {
+	Source byte
+	Reason byte
+}
+
+func (AssociateRQ) Type() Type { return TypeAssociateRQ }
+func (AssociateAC) Type() Type { return TypeAssociateAC }
+func (AssociateRJ) Type() Type { return TypeAssociateRJ }
+func (PDataTF) Type() Type     { return TypePDataTF }
+func (ReleaseRQ) Type() Type   { return TypeReleaseRQ }
+func (ReleaseRP) Type() Type   { return TypeReleaseRP }
+func (Abort) Type() Type       { return TypeAbort }
+
+func (t Type) String() string {
+	switch t {
+	case TypeAssociateRQ:
+		return "A-ASSOCIATE-RQ"
+	case TypeAssociateAC:
+		return "A-ASSOCIATE-AC"
+	case TypeAssociateRJ:
+		return "A-ASSOCIATE-RJ"
+	case TypePDataTF:
+		return "P-DATA-TF"
+	case TypeReleaseRQ:
+		return "A-RELEASE-RQ"
+	case TypeReleaseRP:
+		return "A-RELEASE-RP"
+	case TypeAbort:
+		return "A-ABORT"
+	default:
+		return fmt.Sprintf("unknown PDU type 0x%02x", byte(t))
+	}
+}
+
+func (rj AssociateRJ) String() string {
+	return fmt.Sprintf("A-ASSOCIATE-RJ result=%d source=%d reason=%d", rj.Result, rj.Source, rj.Reason)
+}
+
+func (ab Abort) String() string {
+	return fmt.Sprintf("A-ABORT source=%d reason=%d", ab.Source, ab.Reason)
+}
+
+// PDVHeaderLength is the number of bytes used by a PDV item in addition to its data:
+// item length, presentation context ID and message control header
+const PDVHeaderLength = 6
+
+// Write encodes the PDU and writes it to the writer
+func Write(w io.Writer, p PDU) error {
+	body := bytes.Buffer{}
+	err := p.encode(&body)
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, headerLength)
+	header[0] = byte(p.Type())
+	binary.BigEndian.PutUint32(header[2:], uint32(body.Len()))
+	_, err = w.Write(append(header, body.Bytes()...))
+	return err
+}
+
+// Read reads the next PDU from the reader. A P-DATA-TF PDU whose variable field is longer than maxLength, or than
+// 16 MiB if maxLength is zero, is rejected, as are the other PDUs longer than 1 MiB. The limits are checked before
+// the variable field is allocated
+func Read(r io.Reader, maxLength uint32) (PDU, error) {
+	header := make([]byte, headerLength)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+	pduType := Type(header[0])
+	length := binary.BigEndian.Uint32(header[2:])
+	if pduType < TypeAssociateRQ || pduType > TypeAbort {
+		return nil, &UnrecognizedPDUError{Type: pduType}
+	}
+	limit := maxControlPDULength
+	if pduType == TypePDataTF {
+		limit = maxLength
+		if limit == 0 {
+			limit = maxPDataTFLength
+		}
+	}
+	if length > limit {
+		return nil, fmt.Errorf("pdu: %s length %d exceeds maximum length %d", pduType, length, limit)
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(r, body)
+	if err != nil {
+		return nil, err
+	}
+	d := decoder{buf: body}
+	switch pduType {
+	case TypeAssociateRQ:
+		return decodeAssociateRQ(&d)
+	case TypeAssociateAC:
+		return decodeAssociateAC(&d)
+	case TypeAssociateRJ:
+		return decodeAssociateRJ(&d)
+	case TypePDataTF:
+		return decodePDataTF(&d)
+	case TypeReleaseRQ:
+		return &ReleaseRQ{}, nil
+	case TypeReleaseRP:
+		return &ReleaseRP{}, nil
+	default:
+		return decodeAbort(&d)
+	}
+}
+
+// UnrecognizedPDUError is returned when the PDU type is not defined by the standard
+type UnrecognizedPDUError struct {
+	Type Type
+}
+
+func (e *UnrecognizedPDUError) Error() string {
+	return fmt.Sprintf("pdu: unrecognized PDU type 0x%02x", byte(e.Type))
+}
+
+// padAETitle returns the AE title padded with spaces to 16 bytes
+func padAETitle(aeTitle string) []byte {
+	b := []byte(fmt.Sprintf("%-16s", aeTitle))
+	return b[:16]
+}
+
+// writeItem writes the item of the A-ASSOCIATE-RQ or AC PDU, whose data cannot be longer than the 16-bit item length
+func writeItem(buf *bytes.Buffer, itemType byte, data []byte) error {
+	if len(data) > maxItemLength {
+		return fmt.Errorf("pdu: item 0x%02x length %d exceeds maximum length %d", itemType, len(data), maxItemLength)
+	}
+	buf.WriteByte(itemType)
+	buf.WriteByte(0)
+	_ = binary.Write(buf, binary.BigEndian, uint16(len(data)))
+	buf.Write(data)
+	return nil
+}
+
+func encodeAssociateHeader(buf *bytes.Buffer, version uint16, called, calling, appContext string) error {
+	if version == 0 {
+		version = ProtocolVersion
+	}
+	if appContext == "" {
+		appContext = ApplicationContextName
+	}
+	_ = binary.Write(buf, binary.BigEndian, version)
+	buf.Write([]byte{0, 0})
+	buf.Write(padAETitle(called))
+	buf.Write(padAETitle(calling))
+	buf.Write(make([]byte, 32))
+	return writeItem(buf, ItemApplicationContext, []byte(appContext))
+}
+
+func (ui UserInformation) encode(buf *bytes.Buffer) error {
+	sub := bytes.Buffer{}
+	maxLength := make([]byte, 4)
+	binary.BigEndian.PutUint32(maxLength, ui.MaxPDULength)
+	err := writeItem(&sub, ItemMaximumLength, maxLength)
+	if err != nil {
+		return err
+	}
+	if ui.ImplementationClassUID != "" {
+		err = writeItem(&sub, ItemImplementationClassUID, []byte(ui.ImplementationClassUID))
+		if err != nil {
+			return err
+		}
+	}
+	if ui.MaxOperationsInvoked != 0 || ui.MaxOperationsPerformed != 0 {
+		ops := make([]byte, 4)
+		binary.BigEndian.PutUint16(ops, ui.MaxOperationsInvoked)
+		binary.BigEndian.PutUint16(ops[2:], ui.MaxOperationsPerformed)
+		err = writeItem(&sub, ItemAsynchronousOperations, ops)
+		if err != nil {
+			return err
+		}
+	}
+	for _, role := range ui.RoleSelections {
+		// The SOP class UID and the roles follow the 16-bit length of the UID within the item
+		if len(role.SOPClassUID) > maxItemLength-4 {
+			return fmt.Errorf("pdu: role selection SOP class UID length %d exceeds maximum length %d", len(role.SOPClassUID), maxItemLength-4)
+		}
+		data := make([]byte, 2, 4+len(role.SOPClassUID))
+		binary.BigEndian.PutUint16(data, uint16(len(role.SOPClassUID)))
+		data = append(data, role.SOPClassUID...)
+		data = append(data, boolByte(role.SCURole), boolByte(role.SCPRole))
+		err = writeItem(&sub, ItemRoleSelection, data)
+		if err != nil {
+			return err
+		}
+	}
+	if ui.ImplementationVersionName != "" {
+		err = writeItem(&sub, ItemImplementationVersion, []byte(ui.ImplementationVersionName))
+		if err != nil {
+			return err
+		}
+	}
+	for _, item := range ui.Unknown {
+		err = writeItem(&sub, item.Type, item.Data)
+		if err != nil {
+			return err
+		}
+	}
+	return writeItem(buf, ItemUserInformation, sub.Bytes())
+}
+
+func (rq AssociateRQ) encode(buf *bytes.Buffer) error {
+	err := encodeAssociateHeader(buf, rq.ProtocolVersion, rq.CalledAETitle, rq.CallingAETitle, rq.ApplicationContext)
+	if err != nil {
+		return err
+	}
+	for _, pc := range rq.PresentationContexts {
+		sub := bytes.Buffer{}
+		sub.Write([]byte{pc.ID, 0, 0, 0})
+		err = writeItem(&sub, ItemAbstractSyntax, []byte(pc.AbstractSyntax))
+		if err != nil {
+			return err
+		}
+		for _, ts := range pc.TransferSyntaxes {
+			err = writeItem(&sub, ItemTransferSyntax, []byte(ts))
+			if err != nil {
+				return err
+			}
+		}
+		err = writeItem(buf, ItemPresentationContextRQ, sub.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return rq.UserInformation.encode(buf)
+}
+
+func (ac AssociateAC) encode(buf *bytes.Buffer) error {
+	err := encodeAssociateHeader(buf, ac.ProtocolVersion, ac.CalledAETitle, ac.CallingAETitle, ac.ApplicationContext)
+	if err != nil {
+		return err
+	}
+	for _, pc := range ac.PresentationContexts {
+		sub := bytes.Buffer{}
+		sub.Write([]byte{pc.ID, 0, pc.Result, 0})
+		// The transfer syntax sub-item is not significant when the context is rejected, but it shall be present
+		err = writeItem(&sub, ItemTransferSyntax, []byte(pc.TransferSyntax))
+		if err != nil {
+			return err
+		}
+		err = writeItem(buf, ItemPresentationContextAC, sub.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	return ac.UserInformation.encode(buf)
+}
+
+func (rj AssociateRJ) encode(buf *bytes.Buffer) error {
+	buf.Write([]byte{0, rj.Result, rj.Source, rj.Reason})
+	return nil
+}
+
+func (p PDataTF) encode(buf *bytes.Buffer) error {
+	for _, pdv := range p.Items {
+		_ = binary.Write(buf, binary.BigEndian, uint32(len(pdv.Data)+2))
+		var control byte
+		if pdv.IsCommand {
+			control |= 0x01
+		}
+		if pdv.IsLast {
+			control |= 0x02
+		}
+		buf.Write([]byte{pdv.PresentationContextID, control})
+		buf.Write(pdv.Data)
+	}
+	return nil
+}
+
+func (ReleaseRQ) encode(buf *bytes.Buffer) error {
+	buf.Write(make([]byte, 4))
+	return nil
+}
+
+func (ReleaseRP) encode(buf *bytes.Buffer) error {
+	buf.Write(make([]byte, 4))
+	return nil
+}
+
+func (ab Abort) encode(buf *bytes.Buffer) error {
+	buf.Write([]byte{0, 0, ab.Source, ab.Reason})
+	return nil
+}
+
+func boolByte(b bool) byte {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// decoder reads big endian fields from a PDU body
+type decoder struct {
+	buf []byte
+	pos int
+}
+
+var errShortPDU = fmt.Errorf("pdu: unexpected end of PDU")
+
+func (d *decoder) remaining() int {
+	return len(d.buf) - d.pos
+}
+
+func (d *decoder) bytes(n int) ([]byte, error) {
+	if n < 0 || d.remaining() < n {
+		return nil, errShortPDU
+	}
+	b := d.buf[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *decoder) uint8() (byte, error) {
+	b, err := d.bytes(1)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (d *decoder) uint16() (uint16, error) {
+	b, err := d.bytes(2)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(b), nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	b, err := d.bytes(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+// item reads an item header and returns its type and content
+func (d *decoder) item() (byte, []byte, error) {
+	itemType, err := d.uint8()
+	if err != nil {
+		return 0, nil, err
+	}
+	_, err = d.uint8()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, err := d.uint16()
+	if err != nil {
+		return 0, nil, err
+	}
+	data, err := d.bytes(int(length))
+	if err != nil {
+		return 0, nil, err
+	}
+	return itemType, data, nil
+}
+
+// trimUID removes the trailing null padding and spaces that some implementations append to UIDs
+func trimUID(b []byte) string {
+	return strings.TrimRight(string(b), "\x00 ")
+}
+
+func decodeAssociateHeader(d *decoder) (version uint16, called, calling string, err error) {
+	version, err = d.uint16()
+	if err != nil {
+		return
+	}
+	if _, err = d.bytes(2); err != nil {
+		return
+	}
+	calledB, err := d.bytes(16)
+	if err != nil {
+		return
+	}
+	callingB, err := d.bytes(16)
+	if err != nil {
+		return
+	}
+	if _, err = d.bytes(32); err != nil {
+		return
+	}
+	return version, strings.TrimSpace(string(calledB)), strings.TrimSpace(string(callingB)), nil
+}
+
+func decodeUserInformation(data []byte) (UserInformation, error) {
+	ui := UserInformation{}
+	d := decoder{buf: data}
+	for d.remaining() > 0 {
+		itemType, item, err := d.item()
+		if err != nil {
+			return ui, err
+		}
+		sub := decoder{buf: item}
+		switch itemType {
+		case ItemMaximumLength:
+			ui.MaxPDULength, err = sub.uint32()
+		case ItemImplementationClassUID:
+			ui.ImplementationClassUID = trimUID(item)
+		case ItemImplementationVersion:
+			ui.ImplementationVersionName = strings.TrimSpace(string(item))
+		case ItemAsynchronousOperations:
+			ui.MaxOperationsInvoked, err = sub.uint16()
+			if err == nil {
+				ui.MaxOperationsPerformed, err = sub.uint16()
+			}
+		case ItemRoleSelection:
+			var uidLength uint16
+			var uidB []byte
+			var scu, scp byte
+			if uidLength, err = sub.uint16(); err != nil {
+				break
+			}
+			if uidB, err = sub.bytes(int(uidLength)); err != nil {
+				break
+			}
+			if scu, err = sub.uint8(); err != nil {
+				break
+			}
+			if scp, err = sub.uint8(); err != nil {
+				break
+			}
+			ui.RoleSelections = append(ui.RoleSelections, RoleSelection{
+				SOPClassUID: trimUID(uidB),
+				SCURole:     scu == 1,
+				SCPRole:     scp == 1,
+			})
+		default:
+			ui.Unknown = append(ui.Unknown, RawItem{Type: itemType, Data: append([]byte(nil), item...)})
+		}
+		if err != nil {
+			return ui, err
+		}
+	}
+	return ui, nil
+}
+
+func decodeAssociateRQ(d *decoder) (*AssociateRQ, error) {
+	rq := &AssociateRQ{}
+	var err error
+	rq.ProtocolVersion, rq.CalledAETitle, rq.CallingAETitle, err = decodeAssociateHeader(d)
+	if err != nil {
+		return nil, err
+	}
+	for d.remaining() > 0 {
+		itemType, item, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		switch itemType {
+		case ItemApplicationContext:
+			rq.ApplicationContext = trimUID(item)
+		case ItemPresentationContextRQ:
+			sub := decoder{buf: item}
+			header, err := sub.bytes(4)
+			if err != nil {
+				return nil, err
+			}
+			pc := PresentationContextRQ{ID: header[0]}
+			for sub.remaining() > 0 {
+				subType, subItem, err := sub.item()
+				if err != nil {
+					return nil, err
+				}
+				switch subType {
+				case ItemAbstractSyntax:
+					pc.AbstractSyntax = trimUID(subItem)
+				case ItemTransferSyntax:
+					pc.TransferSyntaxes = append(pc.TransferSyntaxes, trimUID(subItem))
+				}
+			}
+			rq.PresentationContexts = append(rq.PresentationContexts, pc)
+		case ItemUserInformation:
+			rq.UserInformation, err = decodeUserInformation(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rq, nil
+}
+
+func decodeAssociateAC(d *decoder) (*AssociateAC, error) {
+	ac := &AssociateAC{}
+	var err error
+	ac.ProtocolVersion, ac.CalledAETitle, ac.CallingAETitle, err = decodeAssociateHeader(d)
+	if err != nil {
+		return nil, err
+	}
+	for d.remaining() > 0 {
+		itemType, item, err := d.item()
+		if err != nil {
+			return nil, err
+		}
+		switch itemType {
+		case ItemApplicationContext:
+			ac.ApplicationContext = trimUID(item)
+		case ItemPresentationContextAC:
+			sub := decoder{buf: item}
+			header, err := sub.bytes(4)
+			if err != nil {
+				return nil, err
+			}
+			pc := PresentationContextAC{ID: header[0], Result: header[2]}
+			for sub.remaining() > 0 {
+				subType, subItem, err := sub.item()
+				if err != nil {
+					return nil, err
+				}
+				if subType == ItemTransferSyntax {
+					pc.TransferSyntax = trimUID(subItem)
+				}
+			}
+			ac.PresentationContexts = append(ac.PresentationContexts, pc)
+		case ItemUserInformation:
+			ac.UserInformation, err = decodeUserInformation(item)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return ac, nil
+}
+
+func decodeAssociateRJ(d *decoder) (*AssociateRJ, error) {
+	b, err := d.bytes(4)
+	if err != nil {
+		return nil, err
+	}
+	return &AssociateRJ{Result: b[1], Source: b[2], Reason: b[3]}, nil
+}
+
+func decodePDataTF(d *decoder) (*PDataTF, error) {
+	p := &PDataTF{}
+	for d.remaining() > 0 {
+		length, err := d.uint32()
+		if err != nil {
+			return nil, err
+		}
+		if length < 2 {
+			return nil, fmt.Errorf("pdu: invalid PDV item length %d", length)
+		}
+		b, err := d.bytes(int(length))
+		if err != nil {
+			return nil, err
+		}
+		p.Items = append(p.Items, PDV{
+			PresentationContextID: b[0],
+			IsCommand:             b[1]&0x01 != 0,
+			IsLast:                b[1]&0x02 != 0,
+			Data:                  b[2:],
+		})
+	}
+	return p, nil
+}
+
+func decodeAbort(d *decoder) (*Abort, error) {
+	b, err := d.bytes(4)
+	if err != nil {
+		return nil, err
+	}
+	return &Abort{Source: b[2], Reason: b[3]}, nil
+}
@@ -0,0 +1,134 @@
+package pdu
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteRead_AssociateRQ(t *testing.T) {
+	assert := assert.New(t)
+
+	rq := &AssociateRQ{
+		ProtocolVersion:    ProtocolVersion,
+		CalledAETitle:      "ANY-SCP",
+		CallingAETitle:     "GO2COM",
+		ApplicationContext: ApplicationContextName,
+		PresentationContexts: []PresentationContextRQ{
+			{ID: 1, AbstractSyntax: "1.2.840.10008.1.1", TransferSyntaxes: []string{"1.2.840.10008.1.2.1", "1.2.840.10008.1.2"}},
+			{ID: 3, AbstractSyntax: "1.2.840.10008.5.1.4.1.1.2", TransferSyntaxes: []string{"1.2.840.10008.1.2"}},
+		},
+		UserInformation: UserInformation{
+			MaxPDULength:              16384,
+			ImplementationClassUID:    "1.2.3.4",
+			ImplementationVersionName: "TEST",
+			RoleSelections:            []RoleSelection{{SOPClassUID: "1.2.840.10008.5.1.4.1.1.2", SCURole: false, SCPRole: true}},
+			Unknown:                   []RawItem{{Type: ItemUserIdentity, Data: []byte{1, 0, 0, 4, 'u', 's', 'e', 'r', 0, 0}}},
+		},
+	}
+	buf := bytes.Buffer{}
+	assert.NoError(Write(&buf, rq))
+
+	p, err := Read(&buf, 0)
+	assert.NoError(err)
+	assert.Equal(rq, p)
+}
+
+func TestWriteRead_AssociateAC(t *testing.T) {
+	assert := assert.New(t)
+
+	ac := &AssociateAC{
+		ProtocolVersion:    ProtocolVersion,
+		CalledAETitle:      "ANY-SCP",
+		CallingAETitle:     "GO2COM",
+		ApplicationContext: ApplicationContextName,
+		PresentationContexts: []PresentationContextAC{
+			{ID: 1, Result: ResultAcceptance, TransferSyntax: "1.2.840.10008.1.2.1"},
+			{ID: 3, Result: ResultAbstractSyntaxNotSupported, TransferSyntax: ""},
+		},
+		UserInformation: UserInformation{MaxPDULength: 0, MaxOperationsInvoked: 1, MaxOperationsPerformed: 1},
+	}
+	buf := bytes.Buffer{}
+	assert.NoError(Write(&buf, ac))
+
+	p, err := Read(&buf, 0)
+	assert.NoError(err)
+	assert.Equal(ac, p)
+}
+
+func TestWriteRead_Others(t *testing.T) {
+	assert := assert.New(t)
+
+	pdus := []PDU{
+		&AssociateRJ{Result: RejectResultPermanent, Source: RejectSourceServiceUser, Reason: RejectReasonCalledAETitleNotRecognized},
+		&PDataTF{Items: []PDV{
+			{PresentationContextID: 1, IsCommand: true, IsLast: true, Data: []byte{1, 2, 3, 4}},
+			{PresentationContextID: 1, IsCommand: false, IsLast: false, Data: []byte{5, 6}},
+		}},
+		&ReleaseRQ{},
+		&ReleaseRP{},
+		&Abort{Source: AbortSourceServiceProvider, Reason: AbortReasonUnexpectedPDU},
+	}
+	for _, p := range pdus {
+		buf := bytes.Buffer{}
+		assert.NoError(Write(&buf, p))
+		res, err := Read(&buf, 0)
+		assert.NoError(err)
+		assert.Equal(p, res)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	assert := assert.New(t)
+
+	_, err := Read(bytes.NewReader([]byte{0x09, 0, 0, 0, 0, 0}), 0)
+	assert.Error(err)
+	_, ok := err.(*UnrecognizedPDUError)
+	assert.True(ok)
+
+	buf := bytes.Buffer{}
+	assert.NoError(Write(&buf, &PDataTF{Items: []PDV{{PresentationContextID: 1, Data: make([]byte, 64)}}}))
+	_, err = Read(&buf, 32)
+	assert.Error(err)
+
+	_, err = Read(bytes.NewReader([]byte{0x03, 0, 0, 0, 0, 2, 0, 1}), 0)
+	assert.Error(err)
+
+	// The oversized PDUs are rejected from their header, before their variable field is read
+	_, err = Read(bytes.NewReader([]byte{0x01, 0, 0xFF, 0xFF, 0xFF, 0xFF}), DefaultMaxPDULength)
+	assert.ErrorContains(err, "exceeds maximum length 1048576")
+	_, err = Read(bytes.NewReader([]byte{0x04, 0, 0xFF, 0xFF, 0xFF, 0xFF}), 0)
+	assert.ErrorContains(err, "exceeds maximum length 16777216")
+}
+
+func TestWrite_ItemLength(t *testing.T) {
+	assert := assert.New(t)
+
+	// A user identity longer than the 16-bit length of its item
+	buf := bytes.Buffer{}
+	rq := &AssociateRQ{UserInformation: UserInformation{Unknown: []RawItem{{Type: ItemUserIdentity, Data: make([]byte, 0x10000)}}}}
+	assert.ErrorContains(Write(&buf, rq), "exceeds maximum length 65535")
+	assert.Zero(buf.Len())
+
+	rq = &AssociateRQ{UserInformation: UserInformation{RoleSelections: []RoleSelection{{SOPClassUID: strings.Repeat("1", 0x10000)}}}}
+	assert.Error(Write(&buf, rq))
+
+	// The A-ASSOCIATE-RQ of 128 presentation contexts of 20 transfer syntaxes is longer than 64 KiB
+	rq = &AssociateRQ{}
+	for i := 0; i < 128; i++ {
+		pc := PresentationContextRQ{ID: byte(2*i + 1), AbstractSyntax: "1.2.840.10008.5.1.4.1.1.2"}
+		for j := 0; j < 20; j++ {
+			pc.TransferSyntaxes = append(pc.TransferSyntaxes, fmt.Sprintf("1.2.840.10008.1.2.4.%d", 50+j))
+		}
+		rq.PresentationContexts = append(rq.PresentationContexts, pc)
+	}
+	assert.NoError(Write(&buf, rq))
+	assert.Greater(buf.Len(), 64*1024)
+	res, err := Read(&buf, DefaultMaxPDULength)
+	if assert.NoError(err) {
+		assert.Len(res.(*AssociateRQ).PresentationContexts, 128)
+	}
+}
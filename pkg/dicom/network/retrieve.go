@@ -0,0 +1,327 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+// RetrieveIdentifier returns the C-MOVE and C-GET identifier of the query, made of the QueryRetrieveLevel and
+// the unique keys that are set
+func (q *Query) RetrieveIdentifier() go2com.Dataset {
+	elements := []*go2com.Element{go2com.NewElement(tag.QueryRetrieveLevel, q.Level)}
+	for t, value := range map[tag.DicomTag]string{
+		tag.PatientID:         q.PatientID,
+		tag.StudyInstanceUID:  q.StudyInstanceUID,
+		tag.SeriesInstanceUID: q.SeriesInstanceUID,
+		tag.SOPInstanceUID:    q.SOPInstanceUID,
+	} {
+		if value != "" {
+			elements = append(elements, go2com.NewElement(t, value))
+		}
+	}
+	elements = append(elements, q.Keys...)
+	return go2com.Dataset{Elements: sortElements(elements)}
+}
+
+// Move sends a C-MOVE request asking the SCP to send the instances matching the query to the destination AE
+// title. If not nil, progress is called with the sub-operation counters of each pending response; when it returns
+// false, a C-CANCEL request is sent. The counters of the final response are returned
+func (a *Association) Move(ctx context.Context, sopClassUID, destination string, query *Query, progress func(SubOperations) bool) (SubOperations, error) {
+	cmd := &Command{
+		CommandField:        CMoveRQ,
+		Priority:            PriorityMedium,
+		AffectedSOPClassUID: sopClassUID,
+		MoveDestination:     destination,
+	}
+	return a.retrieve(ctx, cmd, query.RetrieveIdentifier(), nil, progress)
+}
+
+// Get sends a C-GET request and receives the instances matching the query on the association, handing them to
+// the handler. The storage SOP classes must be proposed with the SCP role, see WithRoleSelection. If not nil,
+// progress is called with the sub-operation counters of each pending response; when it returns false, a C-CANCEL
+// request is sent. The counters of the final response are returned
+func (a *Association) Get(ctx context.Context, sopClassUID string, query *Query, handler CStoreHandler, progress func(SubOperations) bool) (SubOperations, error) {
+	cmd := &Command{
+		CommandField:        CGetRQ,
+		Priority:            PriorityMedium,
+		AffectedSOPClassUID: sopClassUID,
+	}
+	if handler == nil {
+		handler = func(ctx context.Context, req *CStoreRequest) uint16 {
+			return StatusSuccess
+		}
+	}
+	return a.retrieve(ctx, cmd, query.RetrieveIdentifier(), handler, progress)
+}
+
+// retrieve sends the C-MOVE or C-GET request and waits for the final response. The C-STORE sub-operations of
+// a C-GET request are received with the handler
+func (a *Association) retrieve(ctx context.Context, cmd *Command, identifier go2com.Dataset, handler CStoreHandler, progress func(SubOperations) bool) (SubOperations, error) {
+	pc, err := a.FindPresentationContext(cmd.AffectedSOPClassUID)
+	if err != nil {
+		return SubOperations{}, err
+	}
+	data, err := encodeDataset(identifier, pc.TransferSyntax)
+	if err != nil {
+		return SubOperations{}, err
+	}
+	stop := a.watch(ctx)
+	defer stop()
+
+	cmd.MessageID = a.nextMessageID()
+	next := func() (*Command, error) {
+		return a.readRetrieveResponse(ctx, cmd.MessageID, handler)
+	}
+	if a.dispatched {
+		// The serving loop dispatches the responses, and receives the C-STORE sub-operations of a C-GET request
+		// with the handlers of the server
+		wait := a.expectResponse(cmd.MessageID)
+		next = func() (*Command, error) {
+			msg, err := wait()
+			if err != nil {
+				return nil, err
+			}
+			return msg.Command, nil
+		}
+	}
+	err = a.writeMessage(pc.ID, cmd, bytes.NewReader(data))
+	if err != nil {
+		return SubOperations{}, err
+	}
+	canceled := false
+	for {
+		rsp, err := next()
+		if err != nil {
+			return SubOperations{}, err
+		}
+		ops := SubOperations{}
+		if rsp.SubOperations != nil {
+			ops = *rsp.SubOperations
+		}
+		if IsPendingStatus(rsp.Status) {
+			if progress != nil && !canceled && !progress(ops) {
+				canceled = true
+				err = a.writeMessage(pc.ID, &Command{CommandField: CCancelRQ, MessageIDBeingRespondedTo: cmd.MessageID}, nil)
+				if err != nil {
+					return ops, err
+				}
+			}
+			continue
+		}
+		if IsSuccessStatus(rsp.Status) || (canceled && rsp.Status == StatusCancel) {
+			return ops, nil
+		}
+		return ops, &StatusError{Status: rsp.Status, ErrorComment: rsp.ErrorComment}
+	}
+}
+
+// readRetrieveResponse reads the next response to the C-MOVE or C-GET request with the message ID, receiving the
+// C-STORE sub-operations of a C-GET request with the handler meanwhile
+func (a *Association) readRetrieveResponse(ctx context.Context, messageID uint16, handler CStoreHandler) (*Command, error) {
+	for {
+		pcID, rsp, err := a.readCommand()
+		if err != nil {
+			return nil, err
+		}
+		if rsp.CommandField == CStoreRQ && handler != nil {
+			err = receiveStore(ctx, a, pcID, rsp, handler, "")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !rsp.IsResponse() || rsp.MessageIDBeingRespondedTo != messageID {
+			_ = a.abort(pdu.AbortSourceServiceUser, pdu.AbortReasonNotSpecified)
+			return nil, fmt.Errorf("network: unexpected %s while waiting for response to message %d", rsp, messageID)
+		}
+		if rsp.HasDataset {
+			err = a.readData(pcID, io.Discard)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return rsp, nil
+	}
+}
+
+// RetrieveRequest holds a C-MOVE or C-GET request received by the SCP
+type RetrieveRequest struct {
+	CallingAETitle string
+	CalledAETitle  string
+	RemoteAddr     net.Addr
+	SOPClassUID    string
+	Level          string
+	Identifier     go2com.Dataset
+	// MoveDestination is the AE title of the C-MOVE destination, empty for C-GET requests
+	MoveDestination string
+}
+
+// RetrieveProvider returns the paths of the Part 10 files of the instances to send for a C-MOVE or C-GET request.
+// The context is canceled when the SCU sends a C-CANCEL request. A request of more than 65535 instances, the
+// maximum number of sub-operations, is refused. The files that cannot be read count as failed sub-operations
+// but are missing from the Failed SOP Instance UID List of the response, since their UID is unknown
+type RetrieveProvider func(ctx context.Context, req *RetrieveRequest) ([]string, error)
+
+// WithRetrieveProvider provides option to accept C-MOVE and C-GET requests, answered by sending the files of the
+// provider. The retrieve and storage SOP classes must be accepted with WithSupportedContext
+func WithRetrieveProvider(provider RetrieveProvider) func(*Server) {
+	return func(s *Server) {
+		s.retrieveProvider = provider
+		s.handlers[CMoveRQ] = s.handleRetrieve
+		s.handlers[CGetRQ] = s.handleRetrieve
+	}
+}
+
+// WithMoveDestination provides option to register the address of a C-MOVE destination AE title
+func WithMoveDestination(aeTitle, address string) func(*Server) {
+	return func(s *Server) {
+		s.moveDestinations[aeTitle] = address
+	}
+}
+
+// handleRetrieve reads the identifier of the C-MOVE or C-GET request and performs the sub-operations in the
+// background
+func (s *Server) handleRetrieve(ctx context.Context, a *Association, pcID byte, cmd *Command) error {
+	if !cmd.HasDataset {
+		return a.sendResponse(pcID, cmd, StatusCannotUnderstand)
+	}
+	buf := bytes.Buffer{}
+	err := a.readData(pcID, &buf)
+	if err != nil {
+		return err
+	}
+	identifier, err := decodeDataset(buf.Bytes(), a.contexts[pcID].TransferSyntax)
+	if err != nil {
+		return a.sendResponse(pcID, cmd, StatusCannotUnderstand)
+	}
+	req := &RetrieveRequest{
+		CallingAETitle:  a.CallingAETitle(),
+		CalledAETitle:   a.CalledAETitle(),
+		RemoteAddr:      a.RemoteAddr(),
+		SOPClassUID:     cmd.AffectedSOPClassUID,
+		Identifier:      identifier,
+		MoveDestination: cmd.MoveDestination,
+	}
+	if elem, err := identifier.FindElementByTag(tag.QueryRetrieveLevel); err == nil {
+		req.Level, _ = elem.Value.RawValue.(string)
+	}
+
+	a.startOperation(ctx, cmd.MessageID, func(ctx context.Context) {
+		err := s.retrieve(ctx, a, pcID, cmd, req)
+		if err != nil {
+			_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonNotSpecified)
+		}
+	})
+	return nil
+}
+
+// retrieve sends the files of the provider to the C-MOVE destination, or on the association for C-GET requests,
+// with a pending response after each sub-operation
+func (s *Server) retrieve(ctx context.Context, a *Association, pcID byte, cmd *Command, req *RetrieveRequest) error {
+	fail := func(status uint16, comment string) error {
+		rsp := responseTo(cmd, status)
+		rsp.ErrorComment = truncateComment(comment)
+		return a.writeMessage(pcID, rsp, nil)
+	}
+
+	var address string
+	if cmd.CommandField == CMoveRQ {
+		var ok bool
+		address, ok = s.moveDestinations[cmd.MoveDestination]
+		if !ok {
+			return fail(StatusMoveDestinationUnknown, "unknown move destination "+cmd.MoveDestination)
+		}
+	}
+	paths, err := s.retrieveProvider(ctx, req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return a.sendResponse(pcID, cmd, StatusCancel)
+		}
+		return fail(StatusCannotUnderstand, err.Error())
+	}
+
+	if len(paths) > math.MaxUint16 {
+		return fail(StatusUnableToPerformSubOps, fmt.Sprintf("%d instances exceed the maximum of %d sub-operations", len(paths), math.MaxUint16))
+	}
+
+	target := a
+	if cmd.CommandField == CMoveRQ && len(paths) > 0 {
+		proposals, err := ProposeStorageContexts(paths...)
+		if err != nil {
+			return fail(StatusUnableToPerformSubOps, err.Error())
+		}
+		target, err = Associate(ctx, address, s.aeTitle, cmd.MoveDestination, proposals, WithMaxPDULength(s.maxPDULength), WithARTIMTimeout(s.artimTimeout))
+		if err != nil {
+			return fail(StatusUnableToPerformSubOps, err.Error())
+		}
+		defer target.Release()
+	}
+
+	var options []func(*storeConfig)
+	if cmd.CommandField == CMoveRQ {
+		options = append(options, WithMoveOriginator(a.CallingAETitle(), cmd.MessageID))
+	}
+	ops := &SubOperations{Remaining: uint16(len(paths))}
+	failed := make([]string, 0)
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			rsp := responseTo(cmd, StatusCancel)
+			rsp.SubOperations = ops
+			return a.writeMessage(pcID, rsp, nil)
+		}
+		// Each sub-operation runs to completion, a C-CANCEL request only stops the next ones
+		res, err := target.StoreFile(context.Background(), path, options...)
+		ops.Remaining--
+		switch {
+		case err != nil:
+			ops.Failed++
+			// The UID of a file that cannot be read is unknown
+			if res.SOPInstanceUID != "" {
+				failed = append(failed, res.SOPInstanceUID)
+			}
+		case IsWarningStatus(res.Status):
+			ops.Warning++
+		default:
+			ops.Completed++
+		}
+		if target == a && a.isClosed() {
+			return ErrAssociationClosed
+		}
+		if ops.Remaining > 0 {
+			rsp := responseTo(cmd, StatusPending)
+			rsp.SubOperations = &SubOperations{Remaining: ops.Remaining, Completed: ops.Completed, Failed: ops.Failed, Warning: ops.Warning}
+			err = a.writeMessage(pcID, rsp, nil)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	status := StatusSuccess
+	if ops.Failed > 0 || ops.Warning > 0 {
+		status = StatusWarning
+	}
+	if ops.Failed > 0 && ops.Completed == 0 && ops.Warning == 0 {
+		status = StatusUnableToPerformSubOps
+	}
+	rsp := responseTo(cmd, status)
+	rsp.SubOperations = ops
+	if len(failed) == 0 {
+		return a.writeMessage(pcID, rsp, nil)
+	}
+	data, err := encodeDataset(go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.FailedSOPInstanceUIDList, failed),
+	}}, a.contexts[pcID].TransferSyntax)
+	if err != nil {
+		return a.writeMessage(pcID, rsp, nil)
+	}
+	return a.writeMessage(pcID, rsp, bytes.NewReader(data))
+}
@@ -0,0 +1,115 @@
+package network
+
+import (
+	"context"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+const mrImageStorage = "1.2.840.10008.5.1.4.1.1.4"
+
+var retrievePaths = []string{"../../../dicom_test/010.dcm", "../../../dicom_test/014.dcm"}
+
+func retrieveProvider(ctx context.Context, req *RetrieveRequest) ([]string, error) {
+	return retrievePaths, nil
+}
+
+func TestGet(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t, NewServer("QR-SCP",
+		WithSupportedContext(uid.StudyRootQRGet),
+		WithSupportedContext(mrImageStorage),
+		WithRetrieveProvider(retrieveProvider),
+	))
+
+	assoc, err := Associate(context.Background(), addr, "GET-SCU", "QR-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.StudyRootQRGet},
+		{AbstractSyntax: mrImageStorage},
+	}, WithRoleSelection(mrImageStorage, false, true))
+	if !assert.NoError(err) {
+		return
+	}
+	defer assoc.Release()
+
+	received := make([]string, 0)
+	progress := make([]SubOperations, 0)
+	ops, err := assoc.Get(context.Background(), uid.StudyRootQRGet, &Query{Level: StudyLevel, StudyInstanceUID: "1.2.3"},
+		func(ctx context.Context, req *CStoreRequest) uint16 {
+			received = append(received, req.SOPInstanceUID)
+			return StatusSuccess
+		},
+		func(ops SubOperations) bool {
+			progress = append(progress, ops)
+			return true
+		},
+	)
+	assert.NoError(err)
+	assert.Equal(SubOperations{Completed: 2}, ops)
+	assert.Len(received, 2)
+	assert.Equal([]SubOperations{{Remaining: 1, Completed: 1}}, progress)
+
+	// The number of sub-operations is limited to 65535
+	addr = startServer(t, NewServer("QR-SCP",
+		WithSupportedContext(uid.StudyRootQRGet),
+		WithRetrieveProvider(func(ctx context.Context, req *RetrieveRequest) ([]string, error) {
+			return make([]string, math.MaxUint16+1), nil
+		}),
+	))
+	large, err := Associate(context.Background(), addr, "GET-SCU", "QR-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.StudyRootQRGet},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer large.Release()
+	_, err = large.Get(context.Background(), uid.StudyRootQRGet, &Query{Level: StudyLevel, StudyInstanceUID: "1.2.3"}, nil, nil)
+	statusErr, ok := err.(*StatusError)
+	if assert.True(ok) {
+		assert.Equal(StatusUnableToPerformSubOps, statusErr.Status)
+	}
+}
+
+func TestMove(t *testing.T) {
+	assert := assert.New(t)
+	mu := sync.Mutex{}
+	originators := make([]string, 0)
+	destAddr := startServer(t, NewServer("DEST",
+		WithSupportedContext(mrImageStorage),
+		WithCStoreHandler(func(ctx context.Context, req *CStoreRequest) uint16 {
+			mu.Lock()
+			defer mu.Unlock()
+			originators = append(originators, req.MoveOriginatorAETitle)
+			return StatusSuccess
+		}),
+	))
+	addr := startServer(t, NewServer("QR-SCP",
+		WithSupportedContext(uid.PatientRootQRMove),
+		WithRetrieveProvider(retrieveProvider),
+		WithMoveDestination("DEST", destAddr),
+	))
+
+	assoc, err := Associate(context.Background(), addr, "MOVE-SCU", "QR-SCP", []PresentationContextProposal{
+		{AbstractSyntax: uid.PatientRootQRMove},
+	})
+	if !assert.NoError(err) {
+		return
+	}
+	defer assoc.Release()
+
+	ops, err := assoc.Move(context.Background(), uid.PatientRootQRMove, "DEST", &Query{Level: PatientLevel, PatientID: "P1"}, nil)
+	assert.NoError(err)
+	assert.Equal(SubOperations{Completed: 2}, ops)
+	mu.Lock()
+	assert.Equal([]string{"MOVE-SCU", "MOVE-SCU"}, originators)
+	mu.Unlock()
+
+	_, err = assoc.Move(context.Background(), uid.PatientRootQRMove, "UNKNOWN", &Query{Level: PatientLevel, PatientID: "P1"}, nil)
+	statusErr, ok := err.(*StatusError)
+	if assert.True(ok) {
+		assert.Equal(StatusMoveDestinationUnknown, statusErr.Status)
+	}
+}
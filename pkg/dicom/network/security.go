@@ -0,0 +1,121 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"log"
+	"net"
+
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+)
+
+// WithTLS provides option to establish the association over TLS, as specified by the basic TLS secure transport
+// connection profile of PS3.15 B.1. The server name is taken from the address when not set in the config
+func WithTLS(config *tls.Config) func(*associationConfig) {
+	return func(c *associationConfig) {
+		c.tlsConfig = config
+	}
+}
+
+// WithServerTLS provides option to accept associations over TLS only. Set ClientAuth in the config to require
+// the certificates of the peers
+func WithServerTLS(config *tls.Config) func(*Server) {
+	return func(s *Server) {
+		s.tlsConfig = config
+	}
+}
+
+// WithCalledAETitles provides option to accept associations called with other AE titles than the one of the server
+func WithCalledAETitles(aeTitles ...string) func(*Server) {
+	return func(s *Server) {
+		for _, aeTitle := range aeTitles {
+			s.calledAETitles[aeTitle] = true
+		}
+	}
+}
+
+// WithAllowedCallingAETitle provides option to accept associations from the calling AE title, only from the given
+// networks if any. Once an AE title is allowed, the associations from the other AE titles are rejected
+func WithAllowedCallingAETitle(aeTitle string, networks ...*net.IPNet) func(*Server) {
+	return func(s *Server) {
+		s.callingAETitles[aeTitle] = append(s.callingAETitles[aeTitle], networks...)
+	}
+}
+
+// WithAllowedNetworks provides option to accept associations only from the source addresses of the networks
+func WithAllowedNetworks(networks ...*net.IPNet) func(*Server) {
+	return func(s *Server) {
+		s.networks = append(s.networks, networks...)
+	}
+}
+
+// WithLogger provides option to set the logger of the association rejections, log.Default() by default
+func WithLogger(logger *log.Logger) func(*Server) {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// checkAccess returns the rejection to send, and its reason, if the calling AE title is not allowed from the
+// remote address
+func (s *Server) checkAccess(callingAETitle string, remoteAddr net.Addr) (*pdu.AssociateRJ, string) {
+	var ip net.IP
+	if addr, ok := remoteAddr.(*net.TCPAddr); ok {
+		ip = addr.IP
+	}
+	if len(s.networks) > 0 && !containsIP(s.networks, ip) {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultPermanent,
+			Source: pdu.RejectSourceServiceUser,
+			Reason: pdu.RejectReasonNoReasonGiven,
+		}, "source address not allowed"
+	}
+	if len(s.callingAETitles) == 0 {
+		return nil, ""
+	}
+	networks, ok := s.callingAETitles[callingAETitle]
+	if !ok {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultPermanent,
+			Source: pdu.RejectSourceServiceUser,
+			Reason: pdu.RejectReasonCallingAETitleNotRecognized,
+		}, "calling AE title not allowed"
+	}
+	if len(networks) > 0 && !containsIP(networks, ip) {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultPermanent,
+			Source: pdu.RejectSourceServiceUser,
+			Reason: pdu.RejectReasonCallingAETitleNotRecognized,
+		}, "calling AE title not allowed from source address"
+	}
+	return nil, ""
+}
+
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsHandshake performs the client TLS handshake on the connection, which is closed on failure
+func tlsHandshake(ctx context.Context, conn net.Conn, address string, config *tls.Config) (net.Conn, error) {
+	if config.ServerName == "" && !config.InsecureSkipVerify {
+		if host, _, err := net.SplitHostPort(address); err == nil {
+			config = config.Clone()
+			config.ServerName = host
+		}
+	}
+	tlsConn := tls.Client(conn, config)
+	err := tlsConn.HandshakeContext(ctx)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
@@ -0,0 +1,142 @@
+package network
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"log"
+	"math/big"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+// selfSignedCertificate returns a self-signed certificate for 127.0.0.1 and the pool trusting it
+func selfSignedCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "go2com test"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert}, pool
+}
+
+// syncBuffer is a buffer safe for concurrent use by a logger and the test
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestEcho_TLS(t *testing.T) {
+	assert := assert.New(t)
+	cert, pool := selfSignedCertificate(t)
+	logs := &syncBuffer{}
+	addr := startServer(t, NewServer("ECHO-SCP",
+		WithServerTLS(&tls.Config{
+			Certificates: []tls.Certificate{cert},
+			ClientCAs:    pool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+		}),
+		WithLogger(log.New(logs, "", 0)),
+	))
+	proposals := []PresentationContextProposal{{AbstractSyntax: uid.VerificationSOPClass}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assoc, err := Associate(ctx, addr, "ECHO-SCU", "ECHO-SCP", proposals, WithTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+	}))
+	assert.NoError(err)
+	assert.NoError(assoc.Echo(ctx))
+	assert.NoError(assoc.Release())
+
+	// The server certificate is not trusted
+	_, err = Associate(ctx, addr, "ECHO-SCU", "ECHO-SCP", proposals, WithTLS(&tls.Config{}))
+	assert.Error(err)
+
+	// Plain TCP is refused
+	_, err = Associate(ctx, addr, "ECHO-SCU", "ECHO-SCP", proposals, WithARTIMTimeout(time.Second))
+	assert.Error(err)
+	assert.Eventually(func() bool {
+		return bytes.Contains([]byte(logs.String()), []byte("TLS handshake"))
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestServer_AccessControl(t *testing.T) {
+	assert := assert.New(t)
+	_, loopback, _ := net.ParseCIDR("127.0.0.0/8")
+	_, private, _ := net.ParseCIDR("10.0.0.0/8")
+	logs := &syncBuffer{}
+	addr := startServer(t, NewServer("ECHO-SCP",
+		WithCalledAETitles("ALIAS"),
+		WithAllowedNetworks(loopback),
+		WithAllowedCallingAETitle("ALLOWED", loopback),
+		WithAllowedCallingAETitle("REMOTE", private),
+		WithServerARTIMTimeout(time.Second),
+		WithLogger(log.New(logs, "", 0)),
+	))
+	proposals := []PresentationContextProposal{{AbstractSyntax: uid.VerificationSOPClass}}
+
+	for _, calledAETitle := range []string{"ECHO-SCP", "ALIAS"} {
+		assoc, err := Associate(context.Background(), addr, "ALLOWED", calledAETitle, proposals)
+		assert.NoError(err)
+		assert.NoError(assoc.Release())
+	}
+
+	for _, callingAETitle := range []string{"UNKNOWN", "REMOTE"} {
+		_, err := Associate(context.Background(), addr, callingAETitle, "ECHO-SCP", proposals)
+		rj, ok := err.(*RejectedError)
+		assert.True(ok)
+		if ok {
+			assert.Equal(pdu.RejectReasonCallingAETitleNotRecognized, rj.Reason)
+		}
+	}
+	assert.Contains(logs.String(), `calling "UNKNOWN", called "ECHO-SCP") with result=1, source=1, reason=3: calling AE title not allowed`)
+	assert.Contains(logs.String(), `calling "REMOTE", called "ECHO-SCP") with result=1, source=1, reason=3: calling AE title not allowed from source address`)
+
+	srv := NewServer("ECHO-SCP", WithAllowedNetworks(private), WithServerARTIMTimeout(time.Second), WithLogger(log.New(logs, "", 0)))
+	_, err := Associate(context.Background(), startServer(t, srv), "ALLOWED", "ECHO-SCP", proposals)
+	rj, ok := err.(*RejectedError)
+	assert.True(ok)
+	if ok {
+		assert.Equal(pdu.RejectReasonNoReasonGiven, rj.Reason)
+	}
+	assert.Contains(logs.String(), "source address not allowed")
+}
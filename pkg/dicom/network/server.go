@@ -0,0 +1,477 @@
+package network
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/okieraised/go2com/pkg/dicom/network/pdu"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+)
+
+// ErrServerClosed is returned by Serve after the server is closed
+var ErrServerClosed = errors.New("network: server closed")
+
+// serviceHandler processes a request received on the association. The handler is responsible for reading
+// the data set of the request, if any, and for sending the response(s)
+type serviceHandler func(ctx context.Context, a *Association, pcID byte, cmd *Command) error
+
+// Server is a DICOM application entity accepting associations
+type Server struct {
+	aeTitle      string
+	contexts     map[string][]string
+	maxPDULength uint32
+	artimTimeout time.Duration
+	handlers     map[uint16]serviceHandler
+
+	storeHandler   CStoreHandler
+	storeDirectory string
+	queryProvider  QueryProvider
+
+	retrieveProvider RetrieveProvider
+	moveDestinations map[string]string
+
+	// nHandlers holds the handlers of the DIMSE-N requests by SOP class UID
+	nHandlers map[string]serviceHandler
+
+	commitmentHandler      CommitmentHandler
+	commitmentTracker      *CommitmentTracker
+	commitmentDestinations map[string]string
+
+	maxAssociations      int
+	maxAssociationsPerAE int
+
+	tlsConfig       *tls.Config
+	calledAETitles  map[string]bool
+	callingAETitles map[string][]*net.IPNet
+	networks        []*net.IPNet
+	logger          *log.Logger
+
+	mu       sync.Mutex
+	listener net.Listener
+	assocs   map[*Association]struct{}
+	active   int
+	activeAE map[string]int
+	closed   bool
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+// NewServer returns a new server with the given AE title. The Verification SOP class is always supported
+func NewServer(aeTitle string, options ...func(*Server)) *Server {
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		aeTitle:      aeTitle,
+		contexts:     make(map[string][]string),
+		maxPDULength: pdu.DefaultMaxPDULength,
+		artimTimeout: DefaultARTIMTimeout,
+		handlers:     make(map[uint16]serviceHandler),
+		assocs:       make(map[*Association]struct{}),
+		activeAE:     make(map[string]int),
+		ctx:          ctx,
+		cancel:       cancel,
+
+		moveDestinations:       make(map[string]string),
+		nHandlers:              make(map[string]serviceHandler),
+		commitmentDestinations: make(map[string]string),
+		calledAETitles:         make(map[string]bool),
+		callingAETitles:        make(map[string][]*net.IPNet),
+		logger:                 log.Default(),
+	}
+	s.contexts[uid.VerificationSOPClass] = defaultTransferSyntaxes
+	s.handlers[CEchoRQ] = handleEcho
+	for _, opt := range options {
+		opt(s)
+	}
+	return s
+}
+
+// WithSupportedContext provides option to accept the abstract syntax with the given transfer syntaxes, in order
+// of preference. Without transfer syntaxes, the uncompressed little endian syntaxes are accepted
+func WithSupportedContext(abstractSyntax string, transferSyntaxes ...string) func(*Server) {
+	return func(s *Server) {
+		if len(transferSyntaxes) == 0 {
+			transferSyntaxes = defaultTransferSyntaxes
+		}
+		s.contexts[abstractSyntax] = transferSyntaxes
+	}
+}
+
+// WithServerMaxPDULength provides option to set the maximum PDU length the server accepts
+func WithServerMaxPDULength(maxPDULength uint32) func(*Server) {
+	return func(s *Server) {
+		s.maxPDULength = maxPDULength
+	}
+}
+
+// WithServerARTIMTimeout provides option to set the timeout to wait for the association request after a
+// connection is accepted
+func WithServerARTIMTimeout(timeout time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.artimTimeout = timeout
+	}
+}
+
+// WithMaxAssociations provides option to limit the number of concurrent associations. Further association
+// requests are rejected with a transient local limit exceeded reason
+func WithMaxAssociations(n int) func(*Server) {
+	return func(s *Server) {
+		s.maxAssociations = n
+	}
+}
+
+// WithMaxAssociationsPerAE provides option to limit the number of concurrent associations of each calling AE title.
+// Further association requests are rejected with a transient local limit exceeded reason
+func WithMaxAssociationsPerAE(n int) func(*Server) {
+	return func(s *Server) {
+		s.maxAssociationsPerAE = n
+	}
+}
+
+// AETitle returns the AE title of the server
+func (s *Server) AETitle() string {
+	return s.aeTitle
+}
+
+// Addr returns the address the server listens on, or nil if the server is not serving
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// ListenAndServe listens on the TCP address and serves the incoming associations
+func (s *Server) ListenAndServe(address string) error {
+	l, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+// Serve accepts connections on the listener and serves each association in its own goroutine.
+// Serve always returns a non-nil error; after Close, the returned error is ErrServerClosed
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		_ = l.Close()
+		return ErrServerClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return ErrServerClosed
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return err
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// Close stops accepting connections, aborts the established associations and waits for their goroutines
+func (s *Server) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.cancel()
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for a := range s.assocs {
+		_ = a.Abort()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) track(a *Association, add bool) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if add {
+		if s.closed {
+			return false
+		}
+		s.assocs[a] = struct{}{}
+		return true
+	}
+	delete(s.assocs, a)
+	return true
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	if s.tlsConfig != nil {
+		tlsConn := tls.Server(conn, s.tlsConfig)
+		_ = conn.SetDeadline(time.Now().Add(s.artimTimeout))
+		err := tlsConn.Handshake()
+		if err != nil {
+			s.logger.Printf("network: TLS handshake with %s failed: %v", conn.RemoteAddr(), err)
+			_ = conn.Close()
+			return
+		}
+		_ = conn.SetDeadline(time.Time{})
+		conn = tlsConn
+	}
+	a, err := s.acceptAssociation(conn)
+	if err != nil {
+		return
+	}
+	// The association slot is reserved by acceptAssociation
+	defer s.release(a.callingAETitle)
+	if !s.track(a, true) {
+		_ = a.Abort()
+		return
+	}
+	defer s.track(a, false)
+	defer a.stopOperations()
+	defer a.close()
+
+	for {
+		pcID, cmd, err := a.readCommand()
+		if err != nil {
+			if err == ErrReleaseRequested {
+				a.stopOperations()
+				_ = a.replyRelease()
+			}
+			return
+		}
+		if cmd.CommandField == CCancelRQ {
+			a.cancelOperation(cmd.MessageIDBeingRespondedTo)
+			continue
+		}
+		if cmd.IsResponse() {
+			err = a.dispatchResponse(pcID, cmd)
+			if err != nil {
+				_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonNotSpecified)
+				return
+			}
+			continue
+		}
+		handler, ok := s.handlers[cmd.CommandField]
+		if !ok {
+			err = s.handleUnrecognized(a, pcID, cmd)
+		} else {
+			err = handler(s.ctx, a, pcID, cmd)
+		}
+		if err != nil {
+			_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonNotSpecified)
+			return
+		}
+	}
+}
+
+// handleUnrecognized discards the data set of an unsupported request and answers with an
+// unrecognized operation status
+func (s *Server) handleUnrecognized(a *Association, pcID byte, cmd *Command) error {
+	if cmd.HasDataset {
+		err := a.readData(pcID, io.Discard)
+		if err != nil {
+			return err
+		}
+	}
+	if cmd.IsResponse() || cmd.CommandField == CCancelRQ {
+		return nil
+	}
+	return a.sendResponse(pcID, cmd, StatusUnrecognizedOperation)
+}
+
+// acceptAssociation waits for the A-ASSOCIATE-RQ and answers with an A-ASSOCIATE-AC or A-ASSOCIATE-RJ
+func (s *Server) acceptAssociation(conn net.Conn) (*Association, error) {
+	a := &Association{
+		conn:         conn,
+		contexts:     make(map[byte]*PresentationContext),
+		maxPDULength: s.maxPDULength,
+		artimTimeout: s.artimTimeout,
+		dispatched:   true,
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(s.artimTimeout))
+	p, err := a.readPDU()
+	if err != nil {
+		_ = a.close()
+		return nil, err
+	}
+	rq, ok := p.(*pdu.AssociateRQ)
+	if !ok {
+		_ = a.abort(pdu.AbortSourceServiceProvider, pdu.AbortReasonUnexpectedPDU)
+		return nil, errors.New("network: unexpected " + p.Type().String() + " while waiting for association request")
+	}
+	a.calledAETitle = rq.CalledAETitle
+	a.callingAETitle = rq.CallingAETitle
+	a.peerMaxPDULength = rq.UserInformation.MaxPDULength
+	a.peerImplClassUID = rq.UserInformation.ImplementationClassUID
+	a.peerImplVersion = rq.UserInformation.ImplementationVersionName
+
+	rj, reason := s.checkAssociateRQ(rq, conn.RemoteAddr())
+	if rj == nil {
+		rj, reason = s.acquire(rq.CallingAETitle), "association limit reached"
+	}
+	if rj != nil {
+		s.logger.Printf("network: rejected association from %s (calling %q, called %q) with result=%d, source=%d, reason=%d: %s",
+			conn.RemoteAddr(), rq.CallingAETitle, rq.CalledAETitle, rj.Result, rj.Source, rj.Reason, reason)
+		_ = s.reject(a, rj)
+		return nil, &RejectedError{Result: rj.Result, Source: rj.Source, Reason: rj.Reason}
+	}
+
+	ac := &pdu.AssociateAC{
+		CalledAETitle:  rq.CalledAETitle,
+		CallingAETitle: rq.CallingAETitle,
+		UserInformation: pdu.UserInformation{
+			MaxPDULength:              s.maxPDULength,
+			ImplementationClassUID:    ImplementationClassUID,
+			ImplementationVersionName: ImplementationVersionName,
+		},
+	}
+	for _, proposal := range rq.PresentationContexts {
+		pc := s.negotiateContext(proposal)
+		a.contexts[pc.ID] = pc
+		ac.PresentationContexts = append(ac.PresentationContexts, pdu.PresentationContextAC{
+			ID:             pc.ID,
+			Result:         pc.Result,
+			TransferSyntax: pc.TransferSyntax,
+		})
+	}
+	// The roles proposed for the supported SOP classes are accepted as is, e.g.: the SCP role of the requestor
+	// for the storage SOP classes of C-GET
+	for _, role := range rq.UserInformation.RoleSelections {
+		if _, ok := s.contexts[role.SOPClassUID]; !ok {
+			continue
+		}
+		ac.UserInformation.RoleSelections = append(ac.UserInformation.RoleSelections, role)
+		for _, pc := range a.contexts {
+			if pc.AbstractSyntax == role.SOPClassUID {
+				pc.SCURole = role.SCURole
+				pc.SCPRole = role.SCPRole
+			}
+		}
+	}
+	err = a.writePDU(ac)
+	if err != nil {
+		s.release(rq.CallingAETitle)
+		_ = a.close()
+		return nil, err
+	}
+	_ = conn.SetReadDeadline(time.Time{})
+	return a, nil
+}
+
+// checkAssociateRQ returns the rejection to send, and its reason, if the association request cannot be accepted
+func (s *Server) checkAssociateRQ(rq *pdu.AssociateRQ, remoteAddr net.Addr) (*pdu.AssociateRJ, string) {
+	if rq.ProtocolVersion&pdu.ProtocolVersion == 0 {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultPermanent,
+			Source: pdu.RejectSourceServiceProviderACSE,
+			Reason: pdu.RejectReasonProtocolVersionNotSupported,
+		}, fmt.Sprintf("protocol version %d not supported", rq.ProtocolVersion)
+	}
+	if rq.ApplicationContext != pdu.ApplicationContextName {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultPermanent,
+			Source: pdu.RejectSourceServiceUser,
+			Reason: pdu.RejectReasonApplicationContextNameNotSupported,
+		}, "application context name " + rq.ApplicationContext + " not supported"
+	}
+	if s.aeTitle != "" && rq.CalledAETitle != s.aeTitle && !s.calledAETitles[rq.CalledAETitle] {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultPermanent,
+			Source: pdu.RejectSourceServiceUser,
+			Reason: pdu.RejectReasonCalledAETitleNotRecognized,
+		}, "called AE title not recognized"
+	}
+	return s.checkAccess(rq.CallingAETitle, remoteAddr)
+}
+
+// acquire reserves an association slot for the calling AE title, or returns the rejection to send if
+// the association limits are reached
+func (s *Server) acquire(callingAETitle string) *pdu.AssociateRJ {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if (s.maxAssociations > 0 && s.active >= s.maxAssociations) ||
+		(s.maxAssociationsPerAE > 0 && s.activeAE[callingAETitle] >= s.maxAssociationsPerAE) {
+		return &pdu.AssociateRJ{
+			Result: pdu.RejectResultTransient,
+			Source: pdu.RejectSourceServiceProviderPresentation,
+			Reason: pdu.RejectReasonLocalLimitExceeded,
+		}
+	}
+	s.active++
+	s.activeAE[callingAETitle]++
+	return nil
+}
+
+// release frees the association slot reserved for the calling AE title
+func (s *Server) release(callingAETitle string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active--
+	s.activeAE[callingAETitle]--
+	if s.activeAE[callingAETitle] <= 0 {
+		delete(s.activeAE, callingAETitle)
+	}
+}
+
+// negotiateContext accepts the proposed context with the first transfer syntax of the server preference
+// that was proposed
+func (s *Server) negotiateContext(proposal pdu.PresentationContextRQ) *PresentationContext {
+	pc := &PresentationContext{
+		ID:             proposal.ID,
+		AbstractSyntax: proposal.AbstractSyntax,
+		SCURole:        true,
+	}
+	supported, ok := s.contexts[proposal.AbstractSyntax]
+	if !ok {
+		pc.Result = pdu.ResultAbstractSyntaxNotSupported
+		return pc
+	}
+	for _, ts := range supported {
+		for _, proposed := range proposal.TransferSyntaxes {
+			if ts == proposed {
+				pc.TransferSyntax = ts
+				pc.Result = pdu.ResultAcceptance
+				return pc
+			}
+		}
+	}
+	pc.Result = pdu.ResultTransferSyntaxesNotSupported
+	return pc
+}
+
+// reject sends the A-ASSOCIATE-RJ and waits at most for the ARTIM timeout for the peer to close the connection
+func (s *Server) reject(a *Association, rj *pdu.AssociateRJ) error {
+	err := a.writePDU(rj)
+	if err == nil {
+		_ = a.conn.SetReadDeadline(time.Now().Add(s.artimTimeout))
+		_, _ = io.Copy(io.Discard, a.conn)
+	}
+	_ = a.close()
+	return err
+}
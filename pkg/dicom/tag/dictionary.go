@@ -0,0 +1,227 @@
+package tag
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// dictMu guards the public and private dictionaries against the entries registered at runtime
+var dictMu sync.RWMutex
+
+// DictionaryEntry is an entry of a JSON dictionary. The tag is formatted as (gggg,eeee), gggg,eeee or ggggeeee, or
+// as a range of public tags in the syntax of RegisterRange. The entries of the private tags are identified by their private creator, with the element offset in the block of the
+// creator (gggg,00ee), or the fixed element (gggg,xxee)
+type DictionaryEntry struct {
+	Tag     string `json:"tag"`
+	Creator string `json:"creator,omitempty"`
+	TagInfo
+}
+
+// Register adds the entry of the public tag to the dictionary, replacing the existing entry
+func Register(t DicomTag, info TagInfo) error {
+	if !IsPublicTag(t.Group) {
+		return fmt.Errorf("tag %s is not public", t)
+	}
+	if info.Name == "" || info.VR == "" {
+		return fmt.Errorf("entry of tag %s has no name or VR", t)
+	}
+	dictMu.Lock()
+	defer dictMu.Unlock()
+	setEntry(t, info)
+	return nil
+}
+
+// RegisterPrivate adds the entry of the private tag of the private creator to the private dictionary, replacing the
+// existing entry. An element lower than 0x0100 is the offset of the element in the block reserved by the creator,
+// e.g.: (0029,0008) matches (0029,1008) and (0029,1108), otherwise the element is fixed
+func RegisterPrivate(creator string, t DicomTag, info TagInfo) error {
+	entry, err := newPrivateEntry(creator, tagRange{first: t.Group, last: t.Group}, t.Element, info)
+	if err != nil {
+		return err
+	}
+	dictMu.Lock()
+	defer dictMu.Unlock()
+	addPrivateEntry(creator, entry)
+	return nil
+}
+
+// LoadDCMTKDictionary adds the entries of the dictionary in the DCMTK format, the format of PublicTagDict and
+// PrivateTagDict, to the dictionaries. The dictionaries are left unchanged if an entry is invalid
+func LoadDCMTKDictionary(r io.Reader) error {
+	public := make(map[DicomTag]TagInfo)
+	var ranges []rangeEntry
+	private := make(map[string][]privateEntry)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Split(text, "\t")
+		if len(fields) < 4 {
+			return fmt.Errorf("invalid dictionary entry at line %d: expected tag, VR, name and VM", line)
+		}
+		if strings.Contains(fields[0], `"`) {
+			creator, entry, err := parsePrivateEntry(fields)
+			if err != nil {
+				return fmt.Errorf("invalid dictionary entry at line %d: %v", line, err)
+			}
+			entry, err = newPrivateEntry(creator, entry.group, entry.element, entry.info)
+			if err != nil {
+				return fmt.Errorf("invalid dictionary entry at line %d: %v", line, err)
+			}
+			private[creator] = append(private[creator], entry)
+			continue
+		}
+		entry, err := parsePublicEntry(fields)
+		if err != nil {
+			return fmt.Errorf("invalid dictionary entry at line %d: %v", line, err)
+		}
+		if isRange(fields[0]) {
+			ranges = append(ranges, entry)
+			continue
+		}
+		public[DicomTag{Group: entry.group.first, Element: entry.element.first}] = entry.info
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("cannot read dictionary: %v", err)
+	}
+
+	dictMu.Lock()
+	defer dictMu.Unlock()
+	for _, entry := range ranges {
+		addRange(entry)
+	}
+	for t, info := range public {
+		setEntry(t, info)
+	}
+	for creator, entries := range private {
+		for _, entry := range entries {
+			addPrivateEntry(creator, entry)
+		}
+	}
+	return nil
+}
+
+// LoadJSONDictionary adds the entries of the JSON array of DictionaryEntry to the dictionaries. The dictionaries are
+// left unchanged if an entry is invalid
+func LoadJSONDictionary(r io.Reader) error {
+	var entries []DictionaryEntry
+	err := json.NewDecoder(r).Decode(&entries)
+	if err != nil {
+		return fmt.Errorf("cannot decode JSON dictionary: %v", err)
+	}
+	public := make(map[DicomTag]TagInfo)
+	var ranges []rangeEntry
+	private := make(map[string][]privateEntry)
+	for i, e := range entries {
+		if e.Creator == "" && isRange(e.Tag) {
+			entry, err := parseRangeEntry(e.Tag, e.TagInfo)
+			if err != nil || e.Name == "" || e.VR == "" {
+				return fmt.Errorf("invalid dictionary entry %d: range of tags %s with a name and VR expected", i, e.Tag)
+			}
+			ranges = append(ranges, entry)
+			continue
+		}
+		t, err := parseTag(e.Tag)
+		if err != nil {
+			return fmt.Errorf("invalid dictionary entry %d: %v", i, err)
+		}
+		if e.Creator == "" {
+			if !IsPublicTag(t.Group) || e.Name == "" || e.VR == "" {
+				return fmt.Errorf("invalid dictionary entry %d: public tag %s with a name and VR expected", i, t)
+			}
+			public[t] = e.TagInfo
+			continue
+		}
+		entry, err := newPrivateEntry(e.Creator, tagRange{first: t.Group, last: t.Group}, t.Element, e.TagInfo)
+		if err != nil {
+			return fmt.Errorf("invalid dictionary entry %d: %v", i, err)
+		}
+		private[e.Creator] = append(private[e.Creator], entry)
+	}
+
+	dictMu.Lock()
+	defer dictMu.Unlock()
+	for _, entry := range ranges {
+		addRange(entry)
+	}
+	for t, info := range public {
+		setEntry(t, info)
+	}
+	for creator, entries := range private {
+		for _, entry := range entries {
+			addPrivateEntry(creator, entry)
+		}
+	}
+	return nil
+}
+
+func newPrivateEntry(creator string, group tagRange, element uint16, info TagInfo) (privateEntry, error) {
+	switch {
+	case creator == "":
+		return privateEntry{}, fmt.Errorf("private tag (%04x,%04x) has no private creator", group.first, element)
+	case !IsPrivateTag(group.first) || group.first != group.last && group.parity != parityOdd:
+		return privateEntry{}, fmt.Errorf("group %04x of the private creator %q is not private", group.first, creator)
+	case element > 0x00FF && element < 0x1000:
+		return privateEntry{}, fmt.Errorf("element %04x of the private creator %q is not a private data element", element, creator)
+	case info.Name == "" || info.VR == "":
+		return privateEntry{}, fmt.Errorf("private tag (%04x,%04x) of %q has no name or VR", group.first, element, creator)
+	}
+	return privateEntry{group: group, element: element, info: info}, nil
+}
+
+// addPrivateEntry adds the entry to the private dictionary, replacing the entry of the same groups and element. The
+// caller holds the lock of the dictionaries
+func addPrivateEntry(creator string, entry privateEntry) {
+	entries := privateDict[creator]
+	for i, e := range entries {
+		if e.group == entry.group && e.element == entry.element {
+			entries[i] = entry
+			return
+		}
+	}
+	privateDict[creator] = append(entries, entry)
+}
+
+// parsePublicEntry parses the entry of a public tag in the DCMTK format, or of a range of tags, e.g.:
+// (6000-60FF,3000)
+func parsePublicEntry(fields []string) (rangeEntry, error) {
+	info := TagInfo{
+		VR:   fields[1],
+		Name: strings.TrimPrefix(fields[2], "RETIRED_"),
+		VM:   strings.ToUpper(fields[3]),
+	}
+	if len(fields) > 4 && strings.HasSuffix(fields[4], TypeRetired) {
+		info.Status = TypeRetired
+	}
+	if info.Name == "" || info.VR == "" {
+		return rangeEntry{}, fmt.Errorf("tag %s has no name or VR", fields[0])
+	}
+	entry, err := parseRangeEntry(fields[0], info)
+	if err != nil {
+		return rangeEntry{}, err
+	}
+	if !isRange(fields[0]) && !IsPublicTag(entry.group.first) {
+		return rangeEntry{}, fmt.Errorf("tag %s is not public", fields[0])
+	}
+	return entry, nil
+}
+
+// parseTag parses the tag formatted as (gggg,eeee), gggg,eeee or ggggeeee
+func parseTag(s string) (DicomTag, error) {
+	s = strings.ReplaceAll(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "("), ")"), ",", "")
+	if len(s) != 8 {
+		return DicomTag{}, fmt.Errorf("invalid tag %q", s)
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return DicomTag{}, fmt.Errorf("invalid tag %q: %v", s, err)
+	}
+	return DicomTag{Group: uint16(v >> 16), Element: uint16(v)}, nil
+}
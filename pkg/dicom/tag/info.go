@@ -0,0 +1,153 @@
+package tag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unbounded is the maximum of a value multiplicity without upper bound, e.g.: 1-n
+const Unbounded = -1
+
+// VM is the value multiplicity of a tag, the allowed numbers of values
+type VM struct {
+	Min int
+	// Max is the maximum number of values, or Unbounded
+	Max int
+	// Step is the increment of the number of values from Min, e.g.: 2 for 2-2n
+	Step int
+}
+
+// keywordEntry is the entry of the keyword index
+type keywordEntry struct {
+	tag  DicomTag
+	info TagInfo
+}
+
+// keywords indexes the tags of the public dictionary by keyword. The tags of the ranges of tags are the first tag of
+// their range
+var keywords map[string]keywordEntry
+
+// ParseVM parses the value multiplicity, e.g.: 1, 1-3, 1-n, 2-2n
+func ParseVM(s string) (VM, error) {
+	bounds := strings.Split(strings.ToLower(strings.TrimSpace(s)), "-")
+	if len(bounds) > 2 {
+		return VM{}, fmt.Errorf("invalid VM %q", s)
+	}
+	min, err := strconv.Atoi(bounds[0])
+	if err != nil || min < 0 {
+		return VM{}, fmt.Errorf("invalid VM %q", s)
+	}
+	res := VM{Min: min, Max: min, Step: 1}
+	if len(bounds) == 1 {
+		return res, nil
+	}
+	switch max := bounds[1]; {
+	case max == "n":
+		res.Max = Unbounded
+	case strings.HasSuffix(max, "n"):
+		// The number of values is a multiple of the step, e.g.: 3-3n
+		res.Step, err = strconv.Atoi(strings.TrimSuffix(max, "n"))
+		if err != nil || res.Step != min || min == 0 {
+			return VM{}, fmt.Errorf("invalid VM %q", s)
+		}
+		res.Max = Unbounded
+	default:
+		res.Max, err = strconv.Atoi(max)
+		if err != nil || res.Max < min {
+			return VM{}, fmt.Errorf("invalid VM %q", s)
+		}
+	}
+	return res, nil
+}
+
+// Allows returns true if the number of values is allowed by the value multiplicity
+func (vm VM) Allows(n int) bool {
+	if n < vm.Min || vm.Max != Unbounded && n > vm.Max {
+		return false
+	}
+	return vm.Step <= 1 || (n-vm.Min)%vm.Step == 0
+}
+
+// String returns the value multiplicity in the format of the dictionary, e.g.: 2-2n
+func (vm VM) String() string {
+	switch {
+	case vm.Max == vm.Min:
+		return strconv.Itoa(vm.Min)
+	case vm.Max != Unbounded:
+		return fmt.Sprintf("%d-%d", vm.Min, vm.Max)
+	case vm.Step > 1:
+		return fmt.Sprintf("%d-%dn", vm.Min, vm.Step)
+	}
+	return fmt.Sprintf("%d-n", vm.Min)
+}
+
+// Multiplicity returns the parsed value multiplicity of the tag
+func (info TagInfo) Multiplicity() (VM, error) {
+	return ParseVM(info.VM)
+}
+
+// VRs returns the VRs allowed for the tag, e.g.: US and SS for the xs VR of the dictionary, or US or SS. Items and
+// delimitation items have no VR
+func (info TagInfo) VRs() []string {
+	switch info.VR {
+	case "xs":
+		return []string{"US", "SS"}
+	case "ox", "px":
+		return []string{"OB", "OW"}
+	case "lt":
+		return []string{"US", "SS", "OW"}
+	case "up":
+		return []string{"UL"}
+	case "na", "":
+		return nil
+	}
+	return strings.Split(info.VR, " or ")
+}
+
+// IsRetired returns true if the tag is retired
+func (info TagInfo) IsRetired() bool {
+	return strings.EqualFold(info.Status, TypeRetired)
+}
+
+// ByKeyword returns the tag and information of the keyword, e.g.: PatientName. The tag of a range of tags, e.g.:
+// OverlayData, is the first tag of the range
+func ByKeyword(keyword string) (DicomTag, TagInfo, error) {
+	dictMu.RLock()
+	defer dictMu.RUnlock()
+	entry, ok := keywords[keyword]
+	if !ok {
+		return DicomTag{}, TagInfo{}, fmt.Errorf("could not find tag %s", keyword)
+	}
+	return entry.tag, entry.info, nil
+}
+
+// indexKeywords indexes the tags of the public dictionary by keyword. The caller holds the lock of the dictionaries
+func indexKeywords() {
+	keywords = make(map[string]keywordEntry, len(TagDict)+len(rangeDict))
+	for _, entry := range rangeDict {
+		indexKeyword(DicomTag{Group: entry.group.first, Element: entry.element.first}, entry.info)
+	}
+	for t, info := range TagDict {
+		indexKeyword(t, info)
+	}
+}
+
+// indexKeyword indexes the tag by its keyword. A retired tag does not replace the current tag of the same keyword.
+// The caller holds the lock of the dictionaries
+func indexKeyword(t DicomTag, info TagInfo) {
+	if other, ok := keywords[info.Name]; ok && other.tag != t && info.IsRetired() && !other.info.IsRetired() {
+		return
+	}
+	keywords[info.Name] = keywordEntry{tag: t, info: info}
+}
+
+// setEntry adds the entry of the public tag to the dictionary and its keyword to the index. The caller holds the lock
+// of the dictionaries
+func setEntry(t DicomTag, info TagInfo) {
+	if old, ok := TagDict[t]; ok && old.Name != info.Name && keywords[old.Name].tag == t {
+		delete(keywords, old.Name)
+	}
+	TagDict[t] = info
+	indexKeyword(t, info)
+}
@@ -0,0 +1,112 @@
+package tag
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	// PrivateCreatorName is the name of the private creator elements (gggg,0010-00FF)
+	PrivateCreatorName = "PrivateCreator"
+	// PrivateGroupLengthName is the name of the group length elements (gggg,0000) of the private groups
+	PrivateGroupLengthName = "PrivateGroupLength"
+)
+
+// privateEntry is an entry of the private dictionary
+type privateEntry struct {
+	// group is the range of the odd groups of the entry
+	group tagRange
+	// element is the element offset in the block of the creator if lower than 0x0100, otherwise the fixed element
+	element uint16
+	info    TagInfo
+}
+
+// privateDict holds the entries of the private dictionary by private creator
+var privateDict map[string][]privateEntry
+
+// IsPrivateCreator returns true if the tag is a private creator element (gggg,0010-00FF)
+func IsPrivateCreator(t DicomTag) bool {
+	return IsPrivateTag(t.Group) && t.Element >= 0x0010 && t.Element <= 0x00FF
+}
+
+// PrivateCreatorTag returns the private creator element reserving the block of the private tag (gggg,xxee), i.e.:
+// (gggg,00xx)
+func PrivateCreatorTag(t DicomTag) DicomTag {
+	return DicomTag{Group: t.Group, Element: t.Element >> 8}
+}
+
+// FindPrivate finds information about the private tag in the block reserved by the private creator
+func FindPrivate(t DicomTag, creator string) (TagInfo, error) {
+	switch {
+	case !IsPrivateTag(t.Group):
+		return TagInfo{}, fmt.Errorf("tag %s is not private", t)
+	case t.Element == 0x0000:
+		return TagInfo{"UL", PrivateGroupLengthName, "1", ""}, nil
+	case IsPrivateCreator(t):
+		return TagInfo{"LO", PrivateCreatorName, "1", ""}, nil
+	}
+	dictMu.RLock()
+	defer dictMu.RUnlock()
+	for _, entry := range privateDict[creator] {
+		if !entry.group.match(t.Group) {
+			continue
+		}
+		if entry.element == t.Element || (entry.element < 0x0100 && t.Element >= 0x1000 && t.Element&0x00FF == entry.element) {
+			return entry.info, nil
+		}
+	}
+	return TagInfo{}, fmt.Errorf("could not find private tag %s of creator %q", t, creator)
+}
+
+// parsePrivateDict parses the private dictionary in the DCMTK format. The entries are identified by
+// (gggg,"CREATOR",ee) or (gggg,"CREATOR",eeee), with (gggg-o-gggg,...) for the odd groups of a range
+func parsePrivateDict(dict string) map[string][]privateEntry {
+	res := make(map[string][]privateEntry)
+	scanner := bufio.NewScanner(strings.NewReader(dict))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "(") {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) < 5 {
+			continue
+		}
+		creator, entry, err := parsePrivateEntry(fields)
+		if err != nil {
+			continue
+		}
+		res[creator] = append(res[creator], entry)
+	}
+	return res
+}
+
+func parsePrivateEntry(fields []string) (string, privateEntry, error) {
+	key := strings.TrimSuffix(strings.TrimPrefix(fields[0], "("), ")")
+	first := strings.Index(key, `"`)
+	last := strings.LastIndex(key, `"`)
+	if first < 1 || last <= first || last+2 > len(key) {
+		return "", privateEntry{}, fmt.Errorf("invalid private tag %s", fields[0])
+	}
+	group, err := parseTagRange(strings.TrimSuffix(key[:first], ","))
+	if err != nil {
+		return "", privateEntry{}, err
+	}
+	element, err := strconv.ParseUint(key[last+2:], 16, 16)
+	if err != nil {
+		return "", privateEntry{}, err
+	}
+
+	valueRepresentation := fields[1]
+	// The VR of the pixel data, OB or OW
+	if valueRepresentation == "px" {
+		valueRepresentation = "ox"
+	}
+	return key[first+1 : last], privateEntry{
+		group:   group,
+		element: uint16(element),
+		info:    TagInfo{VR: valueRepresentation, Name: fields[2], VM: fields[3], Status: ""},
+	}, nil
+}
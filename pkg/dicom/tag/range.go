@@ -0,0 +1,180 @@
+package tag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type parity int
+
+const (
+	parityEven parity = iota
+	parityOdd
+	parityAny
+)
+
+// tagRange matches the groups or elements between first and last of the parity, whose bits of the mask are those of
+// first
+type tagRange struct {
+	first  uint16
+	last   uint16
+	mask   uint16
+	parity parity
+}
+
+// rangeEntry is an entry of the dictionary matching a range of tags
+type rangeEntry struct {
+	group   tagRange
+	element tagRange
+	info    TagInfo
+}
+
+// rangeDict holds the entries of the repeating groups and elements of the dictionary, e.g.: the overlays (60xx,eeee)
+var rangeDict []rangeEntry
+
+// RegisterRange adds the entry of the range of public tags to the dictionary. The range is formatted in the DCMTK
+// syntax, e.g.: (6000-60FF,3000) or (0020,3100-31FF), where the ranges match the even values only, unless written
+// gggg-o-gggg for the odd values or gggg-u-gggg for all the values, or with the masks of PS3.6, e.g.: (60xx,3000) or
+// (1000,xxx0), where the x digits match any digit
+func RegisterRange(key string, info TagInfo) error {
+	entry, err := parseRangeEntry(key, info)
+	if err != nil {
+		return err
+	}
+	if info.Name == "" || info.VR == "" {
+		return fmt.Errorf("entry of tag %s has no name or VR", key)
+	}
+	dictMu.Lock()
+	defer dictMu.Unlock()
+	addRange(entry)
+	return nil
+}
+
+// addRange adds the entry of the range of tags to the dictionary and its keyword to the index. The caller holds the
+// lock of the dictionaries
+func addRange(entry rangeEntry) {
+	rangeDict = append(rangeDict, entry)
+	indexKeyword(DicomTag{Group: entry.group.first, Element: entry.element.first}, entry.info)
+}
+
+// findRange returns the entry of the narrowest range matching the tag, the last registered of the ranges of the same
+// width. The caller holds the lock of the dictionaries
+func findRange(t DicomTag) (TagInfo, bool) {
+	var res *rangeEntry
+	for i := range rangeDict {
+		entry := &rangeDict[i]
+		if !entry.group.match(t.Group) || !entry.element.match(t.Element) {
+			continue
+		}
+		if res == nil || entry.group.width() < res.group.width() ||
+			entry.group.width() == res.group.width() && entry.element.width() <= res.element.width() {
+			res = entry
+		}
+	}
+	if res == nil {
+		return TagInfo{}, false
+	}
+	return res.info, true
+}
+
+func (r tagRange) match(v uint16) bool {
+	if v < r.first || v > r.last || v&r.mask != r.first&r.mask {
+		return false
+	}
+	switch {
+	case r.first == r.last || r.parity == parityAny:
+		return true
+	case r.parity == parityOdd:
+		return v%2 == 1
+	}
+	return v%2 == 0
+}
+
+func (r tagRange) width() uint16 {
+	return r.last - r.first
+}
+
+// isRange returns true if the key of the dictionary entry is a range of tags
+func isRange(key string) bool {
+	return strings.ContainsAny(key, "-xX")
+}
+
+// parseRangeEntry parses the range of tags formatted as (gggg,eeee), with ranges or masks in place of the group or
+// element
+func parseRangeEntry(key string, info TagInfo) (rangeEntry, error) {
+	parts := strings.Split(strings.TrimSuffix(strings.TrimPrefix(key, "("), ")"), ",")
+	if len(parts) != 2 {
+		return rangeEntry{}, fmt.Errorf("invalid tag %s", key)
+	}
+	group, err := parseTagRange(parts[0])
+	if err != nil {
+		return rangeEntry{}, fmt.Errorf("invalid group of tag %s: %v", key, err)
+	}
+	element, err := parseTagRange(parts[1])
+	if err != nil {
+		return rangeEntry{}, fmt.Errorf("invalid element of tag %s: %v", key, err)
+	}
+	return rangeEntry{group: group, element: element, info: info}, nil
+}
+
+// mustParseRangeEntry parses the range of tags of the built-in dictionary
+func mustParseRangeEntry(key string, info TagInfo) rangeEntry {
+	entry, err := parseRangeEntry(key, info)
+	if err != nil {
+		panic(err)
+	}
+	return entry
+}
+
+// parseTagRange parses the value, range or mask of 4 hexadecimal digits
+func parseTagRange(s string) (tagRange, error) {
+	if strings.ContainsAny(s, "xX") {
+		if len(s) != 4 {
+			return tagRange{}, fmt.Errorf("invalid mask %s", s)
+		}
+		r := tagRange{parity: parityEven}
+		digits := strings.NewReplacer("x", "0", "X", "0").Replace(s)
+		for i := 0; i < 4; i++ {
+			if s[i] != 'x' && s[i] != 'X' {
+				r.mask |= 0xF << (4 * (3 - i))
+			}
+		}
+		first, err := strconv.ParseUint(digits, 16, 16)
+		if err != nil {
+			return tagRange{}, err
+		}
+		r.first = uint16(first)
+		r.last = r.first | ^r.mask
+		return r, nil
+	}
+
+	r := tagRange{parity: parityEven}
+	bounds := []string{s}
+	for _, separator := range []struct {
+		s string
+		p parity
+	}{{"-o-", parityOdd}, {"-u-", parityAny}, {"-e-", parityEven}, {"-", parityEven}} {
+		if strings.Contains(s, separator.s) {
+			bounds = strings.SplitN(s, separator.s, 2)
+			r.parity = separator.p
+			break
+		}
+	}
+	first, err := strconv.ParseUint(bounds[0], 16, 16)
+	if err != nil {
+		return tagRange{}, err
+	}
+	last := first
+	if len(bounds) == 2 {
+		last, err = strconv.ParseUint(bounds[1], 16, 16)
+		if err != nil {
+			return tagRange{}, err
+		}
+	}
+	if last < first {
+		return tagRange{}, fmt.Errorf("invalid range %s", s)
+	}
+	r.first, r.last = uint16(first), uint16(last)
+	return r, nil
+}
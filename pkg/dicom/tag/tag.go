@@ -1,9 +1,9 @@
 package tag
 
+//go:generate go run ../../../cmd/dicomgen -part6 $DICOM_STANDARD/part06.xml -part7 $DICOM_STANDARD/part07.xml -tags tag_definitions.go
+
 import (
-	"encoding/binary"
 	"fmt"
-	"sync"
 )
 
 const (
@@ -71,70 +71,41 @@ func (tag DicomTag) StringWithoutParentheses() string {
 	return fmt.Sprintf("%04X%04X", tag.Group, tag.Element)
 }
 
-// Find finds information about the given tag. If the tag is not
-// part of the dictionary, raise error
+// Find finds information about the given tag, in the entries of the tag, or else in the entries of the ranges of
+// tags, e.g.: (60xx,3000). If the tag is not part of the dictionary, raise error
 func Find(tag DicomTag) (TagInfo, error) {
-	err := fmt.Errorf("could not find tag (0x%x, 0x%x)", tag.Group, tag.Element)
-	entry, ok := TagDict[tag]
-	if !ok {
-		if tag.Group%2 == 0 {
-			if tag.Element == 0x0000 {
-				entry = TagInfo{"UL", "GenericGroupLength", "1", ""}
-			} else {
-				b := make([]byte, 2)
-				binary.BigEndian.PutUint16(b, tag.Group)
-				switch int(b[0]) {
-				case 80:
-					entry, kk := TagDict[DicomTag{
-						Group:   0x5000,
-						Element: tag.Element,
-					}]
-					if !kk {
-						return TagInfo{}, err
-					}
-					return entry, nil
-				case 96:
-					entry, kk := TagDict[DicomTag{
-						Group:   0x6000,
-						Element: tag.Element,
-					}]
-					if !kk {
-						return TagInfo{}, err
-					}
-					return entry, nil
-				default:
-					return TagInfo{}, err
-				}
-			}
-		} else {
-			return TagInfo{}, err
-		}
+	dictMu.RLock()
+	defer dictMu.RUnlock()
+	if entry, ok := TagDict[tag]; ok {
+		return entry, nil
+	}
+	if entry, ok := findRange(tag); ok {
+		return entry, nil
 	}
-	return entry, nil
+	return TagInfo{}, fmt.Errorf("could not find tag (0x%x, 0x%x)", tag.Group, tag.Element)
 }
 
 // FindByName searchs for the tag by name
 func FindByName(name string) (TagInfo, error) {
-	for _, tag := range TagDict {
-		if tag.Name == name {
-			return tag, nil
-		}
-	}
-	return TagInfo{}, fmt.Errorf("could not find tag %s", name)
+	_, info, err := ByKeyword(name)
+	return info, err
 }
 
-var once = sync.Once{}
-
 // init generates pre-defined tags as a dictionary
 func init() {
-	once.Do(func() {
-		InitTagDict()
-	})
+	initTag()
+	privateDict = parsePrivateDict(PrivateTagDict)
+	indexKeywords()
 }
 
+// InitTagDict returns a copy of the dictionary of the public tags, with the entries registered so far. The entries
+// registered later are not in the copy
 func InitTagDict() map[DicomTag]TagInfo {
-	if TagDict == nil {
-		initTag()
+	dictMu.RLock()
+	defer dictMu.RUnlock()
+	res := make(map[DicomTag]TagInfo, len(TagDict))
+	for t, info := range TagDict {
+		res[t] = info
 	}
-	return TagDict
+	return res
 }
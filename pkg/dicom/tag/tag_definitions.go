@@ -4995,7 +4995,7 @@ var TagDict map[DicomTag]TagInfo
 
 func initTag() {
 
-	TagDict = make(map[DicomTag]TagInfo, 5058)
+	TagDict = make(map[DicomTag]TagInfo, 4990)
 	TagDict[DicomTag{0x0000, 0x0000}] = TagInfo{"UL", "CommandGroupLength", "1", ""}
 	TagDict[DicomTag{0x0000, 0x0002}] = TagInfo{"UI", "AffectedSOPClassUID", "1", ""}
 	TagDict[DicomTag{0x0000, 0x0003}] = TagInfo{"UI", "RequestedSOPClassUID", "1", ""}
@@ -9549,22 +9549,22 @@ func initTag() {
 	TagDict[DicomTag{0x5400, 0x1010}] = TagInfo{"ox", "WaveformData", "1", ""}
 	TagDict[DicomTag{0x5600, 0x0010}] = TagInfo{"OF", "FirstOrderPhaseCorrectionAngle", "1", ""}
 	TagDict[DicomTag{0x5600, 0x0020}] = TagInfo{"OF", "SpectroscopyData", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0010}] = TagInfo{"US", "OverlayRows", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0011}] = TagInfo{"US", "OverlayColumns", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0015}] = TagInfo{"IS", "NumberOfFramesInOverlay", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0022}] = TagInfo{"LO", "OverlayDescription", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0040}] = TagInfo{"CS", "OverlayType", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0045}] = TagInfo{"LO", "OverlaySubtype", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0050}] = TagInfo{"SS", "OverlayOrigin", "2", ""}
-	TagDict[DicomTag{0x6000, 0x0051}] = TagInfo{"US", "ImageFrameOrigin", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0100}] = TagInfo{"US", "OverlayBitsAllocated", "1", ""}
-	TagDict[DicomTag{0x6000, 0x0102}] = TagInfo{"US", "OverlayBitPosition", "1", ""}
-	TagDict[DicomTag{0x6000, 0x1001}] = TagInfo{"CS", "OverlayActivationLayer", "1", ""}
-	TagDict[DicomTag{0x6000, 0x1301}] = TagInfo{"IS", "ROIArea", "1", ""}
-	TagDict[DicomTag{0x6000, 0x1302}] = TagInfo{"DS", "ROIMean", "1", ""}
-	TagDict[DicomTag{0x6000, 0x1303}] = TagInfo{"DS", "ROIStandardDeviation", "1", ""}
-	TagDict[DicomTag{0x6000, 0x1500}] = TagInfo{"LO", "OverlayLabel", "1", ""}
-	TagDict[DicomTag{0x6000, 0x3000}] = TagInfo{"ox", "OverlayData", "1", ""}
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0010)", TagInfo{"US", "OverlayRows", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0011)", TagInfo{"US", "OverlayColumns", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0015)", TagInfo{"IS", "NumberOfFramesInOverlay", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0022)", TagInfo{"LO", "OverlayDescription", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0040)", TagInfo{"CS", "OverlayType", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0045)", TagInfo{"LO", "OverlaySubtype", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0050)", TagInfo{"SS", "OverlayOrigin", "2", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0051)", TagInfo{"US", "ImageFrameOrigin", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0100)", TagInfo{"US", "OverlayBitsAllocated", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0102)", TagInfo{"US", "OverlayBitPosition", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1001)", TagInfo{"CS", "OverlayActivationLayer", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1301)", TagInfo{"IS", "ROIArea", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1302)", TagInfo{"DS", "ROIMean", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1303)", TagInfo{"DS", "ROIStandardDeviation", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1500)", TagInfo{"LO", "OverlayLabel", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,3000)", TagInfo{"ox", "OverlayData", "1", ""}))
 	TagDict[DicomTag{0x7FE0, 0x0001}] = TagInfo{"OV", "ExtendedOffsetTable", "1", ""}
 	TagDict[DicomTag{0x7FE0, 0x0002}] = TagInfo{"OV", "ExtendedOffsetTableLengths", "1", ""}
 	TagDict[DicomTag{0x7FE0, 0x0003}] = TagInfo{"UV", "EncapsulatedPixelDataValueTotalLength", "1", ""}
@@ -9688,7 +9688,7 @@ func initTag() {
 	TagDict[DicomTag{0x0020, 0x1005}] = TagInfo{"IS", "ImagesInStudy", "1", "retired"}
 	TagDict[DicomTag{0x0020, 0x1020}] = TagInfo{"LO", "Reference", "1-N", "retired"}
 	TagDict[DicomTag{0x0020, 0x1070}] = TagInfo{"IS", "OtherStudyNumbers", "1-N", "retired"}
-	TagDict[DicomTag{0x0020, 0x3100}] = TagInfo{"CS", "SourceImageIDs", "1-N", "retired"}
+	rangeDict = append(rangeDict, mustParseRangeEntry("(0020,3100-31FF)", TagInfo{"CS", "SourceImageIDs", "1-N", "retired"}))
 	TagDict[DicomTag{0x0020, 0x3401}] = TagInfo{"CS", "ModifyingDeviceID", "1", "retired"}
 	TagDict[DicomTag{0x0020, 0x3402}] = TagInfo{"CS", "ModifiedImageID", "1", "retired"}
 	TagDict[DicomTag{0x0020, 0x3403}] = TagInfo{"DA", "ModifiedImageDate", "1", "retired"}
@@ -9984,61 +9984,69 @@ func initTag() {
 	TagDict[DicomTag{0x4008, 0x0212}] = TagInfo{"CS", "InterpretationStatusID", "1", "retired"}
 	TagDict[DicomTag{0x4008, 0x0300}] = TagInfo{"ST", "Impressions", "1", "retired"}
 	TagDict[DicomTag{0x4008, 0x4000}] = TagInfo{"ST", "ResultsComments", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x0005}] = TagInfo{"US", "CurveDimensions", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x0010}] = TagInfo{"US", "NumberOfPoints", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x0020}] = TagInfo{"CS", "TypeOfData", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x0022}] = TagInfo{"LO", "CurveDescription", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x0030}] = TagInfo{"SH", "AxisUnits", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x0103}] = TagInfo{"US", "DataValueRepresentation", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x0104}] = TagInfo{"US", "MinimumCoordinateValue", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x0105}] = TagInfo{"US", "MaximumCoordinateValue", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x0106}] = TagInfo{"SH", "CurveRange", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x0110}] = TagInfo{"US", "CurveDataDescriptor", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x0112}] = TagInfo{"US", "CoordinateStartValue", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x0114}] = TagInfo{"US", "CoordinateStepValue", "1-N", "retired"}
-	TagDict[DicomTag{0x5000, 0x1001}] = TagInfo{"CS", "CurveActivationLayer", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2000}] = TagInfo{"US", "AudioType", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2002}] = TagInfo{"US", "AudioSampleFormat", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2004}] = TagInfo{"US", "NumberOfChannels", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2006}] = TagInfo{"UL", "NumberOfSamples", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2008}] = TagInfo{"UL", "SampleRate", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x200A}] = TagInfo{"UL", "TotalTime", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x200C}] = TagInfo{"ox", "AudioSampleData", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x200E}] = TagInfo{"LT", "AudioComments", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2500}] = TagInfo{"LO", "CurveLabel", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2600}] = TagInfo{"SQ", "CurveReferencedOverlaySequence", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x2610}] = TagInfo{"US", "CurveReferencedOverlayGroup", "1", "retired"}
-	TagDict[DicomTag{0x5000, 0x3000}] = TagInfo{"ox", "CurveData", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0012}] = TagInfo{"US", "OverlayPlanes", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0052}] = TagInfo{"US", "OverlayPlaneOrigin", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0060}] = TagInfo{"CS", "OverlayCompressionCode", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0061}] = TagInfo{"SH", "OverlayCompressionOriginator", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0062}] = TagInfo{"SH", "OverlayCompressionLabel", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0063}] = TagInfo{"CS", "OverlayCompressionDescription", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0066}] = TagInfo{"AT", "OverlayCompressionStepPointers", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x0068}] = TagInfo{"US", "OverlayRepeatInterval", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0069}] = TagInfo{"US", "OverlayBitsGrouped", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0110}] = TagInfo{"CS", "OverlayFormat", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0200}] = TagInfo{"US", "OverlayLocation", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0800}] = TagInfo{"CS", "OverlayCodeLabel", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x0802}] = TagInfo{"US", "OverlayNumberOfTables", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x0803}] = TagInfo{"AT", "OverlayCodeTableLocation", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x0804}] = TagInfo{"US", "OverlayBitsForCodeWord", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x1100}] = TagInfo{"US", "OverlayDescriptorGray", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x1101}] = TagInfo{"US", "OverlayDescriptorRed", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x1102}] = TagInfo{"US", "OverlayDescriptorGreen", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x1103}] = TagInfo{"US", "OverlayDescriptorBlue", "1", "retired"}
-	TagDict[DicomTag{0x6000, 0x1200}] = TagInfo{"US", "OverlaysGray", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x1201}] = TagInfo{"US", "OverlaysRed", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x1202}] = TagInfo{"US", "OverlaysGreen", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x1203}] = TagInfo{"US", "OverlaysBlue", "1-N", "retired"}
-	TagDict[DicomTag{0x6000, 0x4000}] = TagInfo{"LT", "OverlayComments", "1", "retired"}
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0005)", TagInfo{"US", "CurveDimensions", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0010)", TagInfo{"US", "NumberOfPoints", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0020)", TagInfo{"CS", "TypeOfData", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0022)", TagInfo{"LO", "CurveDescription", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0030)", TagInfo{"SH", "AxisUnits", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0040)", TagInfo{"SH", "AxisLabels", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0103)", TagInfo{"US", "DataValueRepresentation", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0104)", TagInfo{"US", "MinimumCoordinateValue", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0105)", TagInfo{"US", "MaximumCoordinateValue", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0106)", TagInfo{"SH", "CurveRange", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0110)", TagInfo{"US", "CurveDataDescriptor", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0112)", TagInfo{"US", "CoordinateStartValue", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,0114)", TagInfo{"US", "CoordinateStepValue", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,1001)", TagInfo{"CS", "CurveActivationLayer", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2000)", TagInfo{"US", "AudioType", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2002)", TagInfo{"US", "AudioSampleFormat", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2004)", TagInfo{"US", "NumberOfChannels", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2006)", TagInfo{"UL", "NumberOfSamples", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2008)", TagInfo{"UL", "SampleRate", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,200A)", TagInfo{"UL", "TotalTime", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,200C)", TagInfo{"ox", "AudioSampleData", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,200E)", TagInfo{"LT", "AudioComments", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2500)", TagInfo{"LO", "CurveLabel", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2600)", TagInfo{"SQ", "CurveReferencedOverlaySequence", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,2610)", TagInfo{"US", "CurveReferencedOverlayGroup", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(5000-50FF,3000)", TagInfo{"ox", "CurveData", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0012)", TagInfo{"US", "OverlayPlanes", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0052)", TagInfo{"US", "OverlayPlaneOrigin", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0060)", TagInfo{"CS", "OverlayCompressionCode", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0061)", TagInfo{"SH", "OverlayCompressionOriginator", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0062)", TagInfo{"SH", "OverlayCompressionLabel", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0063)", TagInfo{"CS", "OverlayCompressionDescription", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0066)", TagInfo{"AT", "OverlayCompressionStepPointers", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0068)", TagInfo{"US", "OverlayRepeatInterval", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0069)", TagInfo{"US", "OverlayBitsGrouped", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0110)", TagInfo{"CS", "OverlayFormat", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0200)", TagInfo{"US", "OverlayLocation", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0800)", TagInfo{"CS", "OverlayCodeLabel", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0802)", TagInfo{"US", "OverlayNumberOfTables", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0803)", TagInfo{"AT", "OverlayCodeTableLocation", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,0804)", TagInfo{"US", "OverlayBitsForCodeWord", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1100)", TagInfo{"US", "OverlayDescriptorGray", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1101)", TagInfo{"US", "OverlayDescriptorRed", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1102)", TagInfo{"US", "OverlayDescriptorGreen", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1103)", TagInfo{"US", "OverlayDescriptorBlue", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1200)", TagInfo{"US", "OverlaysGray", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1201)", TagInfo{"US", "OverlaysRed", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1202)", TagInfo{"US", "OverlaysGreen", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,1203)", TagInfo{"US", "OverlaysBlue", "1-N", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(6000-60FF,4000)", TagInfo{"LT", "OverlayComments", "1", "retired"}))
 	TagDict[DicomTag{0x7FE0, 0x0020}] = TagInfo{"OW", "CoefficientsSDVN", "1", "retired"}
 	TagDict[DicomTag{0x7FE0, 0x0030}] = TagInfo{"OW", "CoefficientsSDHN", "1", "retired"}
 	TagDict[DicomTag{0x7FE0, 0x0040}] = TagInfo{"OW", "CoefficientsSDDN", "1", "retired"}
-	TagDict[DicomTag{0x7FE0, 0x0010}] = TagInfo{"OW", "PixelData", "1", "retired"}
-	TagDict[DicomTag{0x7F00, 0x0011}] = TagInfo{"US", "VariableNextDataGroup", "1", "retired"}
-	TagDict[DicomTag{0x7F00, 0x0020}] = TagInfo{"OW", "VariableCoefficientsSDVN", "1", "retired"}
+	rangeDict = append(rangeDict, mustParseRangeEntry("(7F00-7FFF,0010)", TagInfo{"ox", "VariablePixelData", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(7F00-7FFF,0011)", TagInfo{"US", "VariableNextDataGroup", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(7F00-7FFF,0020)", TagInfo{"OW", "VariableCoefficientsSDVN", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(7F00-7FFF,0030)", TagInfo{"OW", "VariableCoefficientsSDHN", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(7F00-7FFF,0040)", TagInfo{"OW", "VariableCoefficientsSDDN", "1", "retired"}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(0009-o-FFFF,0000)", TagInfo{"UL", "PrivateGroupLength", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(0009-o-FFFF,0010-u-00FF)", TagInfo{"LO", "PrivateCreator", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(0001-o-0007,0000)", TagInfo{"UL", "IllegalGroupLength", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(0001-o-0007,0010-u-00FF)", TagInfo{"LO", "IllegalPrivateCreator", "1", ""}))
+	rangeDict = append(rangeDict, mustParseRangeEntry("(0000-u-FFFF,0000)", TagInfo{"UL", "GenericGroupLength", "1", ""}))
 	TagDict[DicomTag{0x0028, 0x0410}] = TagInfo{"US", "RowsForNthOrderCoefficients", "1", "retired"}
 	TagDict[DicomTag{0x0028, 0x0411}] = TagInfo{"US", "ColumnsForNthOrderCoefficients", "1", "retired"}
 	TagDict[DicomTag{0x0028, 0x0412}] = TagInfo{"LO", "CoefficientCoding", "1-N", "retired"}
@@ -10055,4 +10063,5 @@ func initTag() {
 	TagDict[DicomTag{0x1000, 0x0014}] = TagInfo{"US", "ShiftTableSize", "1", "retired"}
 	TagDict[DicomTag{0x1000, 0x0015}] = TagInfo{"US", "ShiftTableTriplet", "3", "retired"}
 	TagDict[DicomTag{0x1010, 0x0004}] = TagInfo{"US", "ZonalMap", "1-N", "retired"}
+
 }
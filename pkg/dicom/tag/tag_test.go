@@ -3,6 +3,8 @@ package tag
 import (
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -20,3 +22,249 @@ func TestFind(t *testing.T) {
 	fmt.Println(x)
 
 }
+
+func TestFindPrivate(t *testing.T) {
+	assert := assert.New(t)
+
+	info, err := FindPrivate(DicomTag{Group: 0x0029, Element: 0x1108}, "SIEMENS CSA HEADER")
+	assert.NoError(err)
+	assert.Equal("CSAImageHeaderType", info.Name)
+	assert.Equal("CS", info.VR)
+
+	info, err = FindPrivate(DicomTag{Group: 0x6011, Element: 0x1040}, "PAPYRUS 3.0")
+	assert.NoError(err)
+	assert.Equal("OverlayType", info.Name)
+
+	info, err = FindPrivate(DicomTag{Group: 0x0025, Element: 0x1010}, "CMR42 CIRCLECVI")
+	assert.NoError(err)
+	assert.Equal("WorkspaceID", info.Name)
+	_, err = FindPrivate(DicomTag{Group: 0x0025, Element: 0x1110}, "CMR42 CIRCLECVI")
+	assert.Error(err)
+
+	info, err = FindPrivate(DicomTag{Group: 0x0029, Element: 0x0011}, "")
+	assert.NoError(err)
+	assert.Equal(PrivateCreatorName, info.Name)
+	_, err = FindPrivate(DicomTag{Group: 0x0028, Element: 0x1108}, "SIEMENS CSA HEADER")
+	assert.Error(err)
+}
+
+func TestRegister(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.NoError(Register(DicomTag{Group: 0x7FD0, Element: 0x0001}, TagInfo{"LO", "ResearchLabel", "1", ""}))
+	info, err := Find(DicomTag{Group: 0x7FD0, Element: 0x0001})
+	assert.NoError(err)
+	assert.Equal("ResearchLabel", info.Name)
+	info, err = FindByName("ResearchLabel")
+	assert.NoError(err)
+	assert.Equal("LO", info.VR)
+	assert.Error(Register(DicomTag{Group: 0x0029, Element: 0x1001}, TagInfo{"LO", "Private", "1", ""}))
+
+	assert.NoError(RegisterPrivate("ACME 1.0", DicomTag{Group: 0x0041, Element: 0x0002}, TagInfo{"DS", "ScanScore", "1", ""}))
+	info, err = FindPrivate(DicomTag{Group: 0x0041, Element: 0x1202}, "ACME 1.0")
+	assert.NoError(err)
+	assert.Equal("ScanScore", info.Name)
+	assert.Error(RegisterPrivate("", DicomTag{Group: 0x0041, Element: 0x0002}, TagInfo{"DS", "ScanScore", "1", ""}))
+	assert.Error(RegisterPrivate("ACME 1.0", DicomTag{Group: 0x0041, Element: 0x0202}, TagInfo{"DS", "ScanScore", "1", ""}))
+}
+
+func TestLoadDCMTKDictionary(t *testing.T) {
+	assert := assert.New(t)
+
+	dict := "# ACME dictionary\n" +
+		"(00F2,0010)\tUS\tAcmeCounter\t1\tACME\n" +
+		"(00F4-00F8,0120)\tSH\tRETIRED_AcmeCode\t1-n\tACME/retired\n" +
+		"(0043-o-0045,\"ACME 2.0\",10)\tFL\tAcmeWeight\t1\tPrivateTag\n"
+	assert.NoError(LoadDCMTKDictionary(strings.NewReader(dict)))
+
+	info, err := Find(DicomTag{Group: 0x00F2, Element: 0x0010})
+	assert.NoError(err)
+	assert.Equal(TagInfo{"US", "AcmeCounter", "1", ""}, info)
+	info, err = Find(DicomTag{Group: 0x00F8, Element: 0x0120})
+	assert.NoError(err)
+	assert.Equal(TagInfo{"SH", "AcmeCode", "1-N", TypeRetired}, info)
+	_, err = Find(DicomTag{Group: 0x00F5, Element: 0x0120})
+	assert.Error(err)
+	info, err = FindPrivate(DicomTag{Group: 0x0045, Element: 0x1110}, "ACME 2.0")
+	assert.NoError(err)
+	assert.Equal("AcmeWeight", info.Name)
+
+	assert.Error(LoadDCMTKDictionary(strings.NewReader("(00F2,0121)\tUS\tAcmeOther\t1\n(00F2,00ZZ)\tUS\tAcmeBad\t1\n")))
+	_, err = Find(DicomTag{Group: 0x00F2, Element: 0x0121})
+	assert.Error(err)
+
+	assert.NoError(LoadDCMTKDictionary(strings.NewReader(PublicTagDict)))
+	assert.NoError(LoadDCMTKDictionary(strings.NewReader(PrivateTagDict)))
+}
+
+func TestLoadJSONDictionary(t *testing.T) {
+	assert := assert.New(t)
+
+	dict := `[
+		{"tag": "(7FDA,0010)", "vr": "UL", "name": "AcmeSize", "vm": "1"},
+		{"tag": "00470012", "creator": "ACME 3.0", "vr": "CS", "name": "AcmeMode", "vm": "1"}
+	]`
+	assert.NoError(LoadJSONDictionary(strings.NewReader(dict)))
+	info, err := Find(DicomTag{Group: 0x7FDA, Element: 0x0010})
+	assert.NoError(err)
+	assert.Equal("AcmeSize", info.Name)
+	info, err = FindPrivate(DicomTag{Group: 0x0047, Element: 0x1012}, "ACME 3.0")
+	assert.NoError(err)
+	assert.Equal("CS", info.VR)
+
+	assert.Error(LoadJSONDictionary(strings.NewReader(`[{"tag": "00470012", "vr": "CS", "name": "AcmeMode"}]`)))
+	assert.Error(LoadJSONDictionary(strings.NewReader(`{}`)))
+}
+
+func TestRegister_Concurrent(t *testing.T) {
+	assert := assert.New(t)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			assert.NoError(Register(DicomTag{Group: 0x7FE2, Element: uint16(i)}, TagInfo{"LO", fmt.Sprintf("Concurrent%d", i), "1", ""}))
+			assert.NoError(RegisterPrivate("ACME 4.0", DicomTag{Group: 0x0049, Element: uint16(i)}, TagInfo{"LO", fmt.Sprintf("Concurrent%d", i), "1", ""}))
+			_, err := Find(PatientName)
+			assert.NoError(err)
+			_, err = FindPrivate(DicomTag{Group: 0x0029, Element: 0x1008}, "SIEMENS CSA HEADER")
+			assert.NoError(err)
+			// The copy of the dictionary is read while the entries are registered
+			for t := range InitTagDict() {
+				_ = t.String()
+			}
+		}(i)
+	}
+	wg.Wait()
+	info, err := FindPrivate(DicomTag{Group: 0x0049, Element: 0x1007}, "ACME 4.0")
+	assert.NoError(err)
+	assert.Equal("Concurrent7", info.Name)
+
+	dict := InitTagDict()
+	assert.Equal("Concurrent7", dict[DicomTag{Group: 0x7FE2, Element: 7}].Name)
+	delete(dict, PatientName)
+	_, err = Find(PatientName)
+	assert.NoError(err)
+}
+
+func TestFind_Ranges(t *testing.T) {
+	assert := assert.New(t)
+
+	cases := []struct {
+		t    DicomTag
+		name string
+	}{
+		{DicomTag{Group: 0x6000, Element: 0x3000}, "OverlayData"},
+		{DicomTag{Group: 0x601E, Element: 0x0010}, "OverlayRows"},
+		{DicomTag{Group: 0x50FE, Element: 0x3000}, "CurveData"},
+		{DicomTag{Group: 0x0020, Element: 0x31FE}, "SourceImageIDs"},
+		{DicomTag{Group: 0x7F02, Element: 0x0010}, "VariablePixelData"},
+		{DicomTag{Group: 0x0018, Element: 0x0000}, "GenericGroupLength"},
+		{DicomTag{Group: 0x0029, Element: 0x0000}, "PrivateGroupLength"},
+		{DicomTag{Group: 0x0029, Element: 0x0011}, "PrivateCreator"},
+		{DicomTag{Group: 0x0003, Element: 0x0000}, "IllegalGroupLength"},
+	}
+	for _, c := range cases {
+		info, err := Find(c.t)
+		assert.NoError(err, c.t.String())
+		assert.Equal(c.name, info.Name, c.t.String())
+	}
+	for _, t := range []DicomTag{{Group: 0x6001, Element: 0x3000}, {Group: 0x0020, Element: 0x3101}, {Group: 0x6100, Element: 0x3000}} {
+		_, err := Find(t)
+		assert.Error(err, t.String())
+	}
+
+	assert.NoError(RegisterRange("(1000,xxx0)", TagInfo{"US", "EscapeTriplet", "3", TypeRetired}))
+	assert.NoError(RegisterRange("(1000,xxx1)", TagInfo{"US", "RunLengthTriplet", "3", TypeRetired}))
+	info, err := Find(DicomTag{Group: 0x1000, Element: 0x1230})
+	assert.NoError(err)
+	assert.Equal("EscapeTriplet", info.Name)
+	info, err = Find(DicomTag{Group: 0x1000, Element: 0x0011})
+	assert.NoError(err)
+	assert.Equal("RunLengthTriplet", info.Name)
+	_, err = Find(DicomTag{Group: 0x1000, Element: 0x0012})
+	assert.NoError(err)
+
+	// The narrowest range matches
+	assert.NoError(RegisterRange("(60xx,3000)", TagInfo{"OW", "AnyOverlayData", "1", ""}))
+	assert.NoError(RegisterRange("(6002,3000)", TagInfo{"OW", "SecondOverlayData", "1", ""}))
+	info, err = Find(DicomTag{Group: 0x6002, Element: 0x3000})
+	assert.NoError(err)
+	assert.Equal("SecondOverlayData", info.Name)
+	info, err = Find(DicomTag{Group: 0x6004, Element: 0x3000})
+	assert.NoError(err)
+	assert.Equal("AnyOverlayData", info.Name)
+	assert.Error(RegisterRange("(60x,3000)", TagInfo{"OW", "Invalid", "1", ""}))
+	assert.Error(RegisterRange("(60FF-6000,3000)", TagInfo{"OW", "Invalid", "1", ""}))
+}
+
+func TestByKeyword(t *testing.T) {
+	assert := assert.New(t)
+
+	dTag, info, err := ByKeyword("PatientName")
+	assert.NoError(err)
+	assert.Equal(PatientName, dTag)
+	assert.Equal("PN", info.VR)
+	dTag, info, err = ByKeyword("OverlayData")
+	assert.NoError(err)
+	assert.Equal(DicomTag{Group: 0x6000, Element: 0x3000}, dTag)
+	assert.Equal([]string{"OB", "OW"}, info.VRs())
+	_, _, err = ByKeyword("NotAKeyword")
+	assert.Error(err)
+
+	assert.NoError(Register(DicomTag{Group: 0x7FE4, Element: 0x0001}, TagInfo{"LO", "FirstLabel", "1", ""}))
+	assert.NoError(Register(DicomTag{Group: 0x7FE4, Element: 0x0001}, TagInfo{"LO", "SecondLabel", "1", ""}))
+	_, _, err = ByKeyword("FirstLabel")
+	assert.Error(err)
+	dTag, _, err = ByKeyword("SecondLabel")
+	assert.NoError(err)
+	assert.Equal(DicomTag{Group: 0x7FE4, Element: 0x0001}, dTag)
+}
+
+func TestTagInfo(t *testing.T) {
+	assert := assert.New(t)
+
+	info, err := Find(SmallestImagePixelValue)
+	assert.NoError(err)
+	assert.Equal([]string{"US", "SS"}, info.VRs())
+	assert.False(info.IsRetired())
+	info, err = Find(DicomTag{Group: 0x0020, Element: 0x3100})
+	assert.NoError(err)
+	assert.True(info.IsRetired())
+	assert.Equal([]string{"US", "SS"}, TagInfo{VR: "US or SS"}.VRs())
+	assert.Equal([]string{"PN"}, TagInfo{VR: "PN"}.VRs())
+
+	vm, err := info.Multiplicity()
+	assert.NoError(err)
+	assert.Equal(VM{Min: 1, Max: Unbounded, Step: 1}, vm)
+
+	cases := []struct {
+		vm      string
+		parsed  VM
+		allowed []int
+		denied  []int
+	}{
+		{"1", VM{1, 1, 1}, []int{1}, []int{0, 2}},
+		{"1-3", VM{1, 3, 1}, []int{1, 2, 3}, []int{0, 4}},
+		{"2-2N", VM{2, Unbounded, 2}, []int{2, 4, 100}, []int{1, 3, 5}},
+		{"3-3n", VM{3, Unbounded, 3}, []int{3, 6}, []int{4, 5}},
+		{"2-n", VM{2, Unbounded, 1}, []int{2, 3}, []int{1}},
+	}
+	for _, c := range cases {
+		vm, err := ParseVM(c.vm)
+		assert.NoError(err, c.vm)
+		assert.Equal(c.parsed, vm, c.vm)
+		assert.Equal(strings.ToLower(c.vm), vm.String())
+		for _, n := range c.allowed {
+			assert.True(vm.Allows(n), "%s allows %d", c.vm, n)
+		}
+		for _, n := range c.denied {
+			assert.False(vm.Allows(n), "%s denies %d", c.vm, n)
+		}
+	}
+	for _, s := range []string{"", "n", "3-1", "2-3n", "1-2-3"} {
+		_, err := ParseVM(s)
+		assert.Error(err, s)
+	}
+}
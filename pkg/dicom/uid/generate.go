@@ -0,0 +1,89 @@
+package uid
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const (
+	// MaxLength is the maximum length of a UID
+	MaxLength = 64
+	// UUIDRoot is the root of the UIDs derived from UUIDs, as defined by ISO/IEC 9834-8
+	UUIDRoot = "2.25"
+	// minSuffixLength is the minimum number of random digits of a generated UID to make collisions unlikely
+	minSuffixLength = 16
+)
+
+// Generate returns a new UID under the organization root, followed by the decimal form of 128 random bits, of up to
+// 39 digits, truncated to the maximum length of a UID for the long roots
+func Generate(root string) (string, error) {
+	err := Validate(root)
+	if err != nil {
+		return "", fmt.Errorf("invalid UID root: %v", err)
+	}
+	length := MaxLength - len(root) - 1
+	if length < minSuffixLength {
+		return "", fmt.Errorf("UID root %q leaves fewer than %d digits to generate unique UIDs", root, minSuffixLength)
+	}
+	b := make([]byte, 16)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	// The decimal string of the big integer has no leading zeros
+	suffix := new(big.Int).SetBytes(b).String()
+	if len(suffix) > length {
+		suffix = suffix[:length]
+	}
+	return root + "." + suffix, nil
+}
+
+// GenerateUUID returns a new UID derived from a random UUID, under the 2.25 root
+func GenerateUUID() (string, error) {
+	var b [16]byte
+	_, err := rand.Read(b[:])
+	if err != nil {
+		return "", err
+	}
+	// Sets the version 4 and variant bits of a random UUID
+	b[6] = b[6]&0x0F | 0x40
+	b[8] = b[8]&0x3F | 0x80
+	return FromUUID(b), nil
+}
+
+// FromUUID returns the UID of the UUID, its integer value under the 2.25 root
+func FromUUID(uuid [16]byte) string {
+	return UUIDRoot + "." + new(big.Int).SetBytes(uuid[:]).String()
+}
+
+// Validate returns an error if the UID is not made of numeric components separated by dots, without leading zeros,
+// of at most 64 characters
+func Validate(uid string) error {
+	if uid == "" {
+		return fmt.Errorf("empty UID")
+	}
+	if len(uid) > MaxLength {
+		return fmt.Errorf("UID %q is longer than %d characters", uid, MaxLength)
+	}
+	for _, component := range strings.Split(uid, ".") {
+		if component == "" {
+			return fmt.Errorf("UID %q has an empty component", uid)
+		}
+		for _, c := range component {
+			if c < '0' || c > '9' {
+				return fmt.Errorf("UID %q has a non-numeric component %q", uid, component)
+			}
+		}
+		if len(component) > 1 && component[0] == '0' {
+			return fmt.Errorf("UID %q has a component %q with a leading zero", uid, component)
+		}
+	}
+	return nil
+}
+
+// IsValid returns true if the UID is valid
+func IsValid(uid string) bool {
+	return Validate(uid) == nil
+}
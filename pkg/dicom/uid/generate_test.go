@@ -0,0 +1,40 @@
+package uid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	assert := assert.New(t)
+	u, err := Generate("1.2.826.0.1.3680043.10.1234")
+	assert.NoError(err)
+	assert.True(strings.HasPrefix(u, "1.2.826.0.1.3680043.10.1234."))
+	assert.LessOrEqual(len(u), MaxLength)
+	assert.NoError(Validate(u))
+	other, _ := Generate("1.2.826.0.1.3680043.10.1234")
+	assert.NotEqual(u, other)
+
+	_, err = Generate("1.02.3")
+	assert.Error(err)
+	_, err = Generate("1.2.3.4.5.6.7.8.9.10.11.12.13.14.15.16.17.18.19.20.21.22")
+	assert.Error(err)
+
+	u, err = GenerateUUID()
+	assert.NoError(err)
+	assert.True(IsValid(u))
+	assert.True(strings.HasPrefix(u, UUIDRoot+"."))
+	assert.Equal("2.25.0", FromUUID([16]byte{}))
+}
+
+func TestValidate(t *testing.T) {
+	assert := assert.New(t)
+	for _, u := range []string{"1.2.840.10008.1.2.1", "0.1", "2.25.329800735698586629295641978511506172918"} {
+		assert.NoError(Validate(u), u)
+	}
+	for _, u := range []string{"", "1..2", "1.2.", "1.2a", "1.02", strings.Repeat("1.", 32) + "1"} {
+		assert.Error(Validate(u), u)
+	}
+}
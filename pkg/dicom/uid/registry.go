@@ -0,0 +1,180 @@
+package uid
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// TransferSyntax describes the encoding of a transfer syntax
+type TransferSyntax struct {
+	UID       string
+	Name      string
+	ByteOrder binary.ByteOrder
+	// Implicit is true if the VRs are not encoded
+	Implicit bool
+	// Encapsulated is true if the pixel data is encapsulated in fragments
+	Encapsulated bool
+	// Lossy is true if the pixel data compression is lossy
+	Lossy bool
+	// Deflated is true if the dataset is compressed with the Deflate algorithm
+	Deflated bool
+	Retired  bool
+}
+
+// lossyTransferSyntaxes holds the transfer syntaxes of lossy image compression
+var lossyTransferSyntaxes = map[string]bool{
+	"1.2.840.10008.1.2.4.50":  true,
+	"1.2.840.10008.1.2.4.51":  true,
+	"1.2.840.10008.1.2.4.52":  true,
+	"1.2.840.10008.1.2.4.53":  true,
+	"1.2.840.10008.1.2.4.54":  true,
+	"1.2.840.10008.1.2.4.55":  true,
+	"1.2.840.10008.1.2.4.56":  true,
+	"1.2.840.10008.1.2.4.59":  true,
+	"1.2.840.10008.1.2.4.60":  true,
+	"1.2.840.10008.1.2.4.61":  true,
+	"1.2.840.10008.1.2.4.62":  true,
+	"1.2.840.10008.1.2.4.63":  true,
+	"1.2.840.10008.1.2.4.64":  true,
+	"1.2.840.10008.1.2.4.81":  true,
+	"1.2.840.10008.1.2.4.91":  true,
+	"1.2.840.10008.1.2.4.93":  true,
+	"1.2.840.10008.1.2.4.100": true,
+	"1.2.840.10008.1.2.4.101": true,
+	"1.2.840.10008.1.2.4.102": true,
+	"1.2.840.10008.1.2.4.103": true,
+	"1.2.840.10008.1.2.4.104": true,
+	"1.2.840.10008.1.2.4.105": true,
+	"1.2.840.10008.1.2.4.106": true,
+	"1.2.840.10008.1.2.4.107": true,
+	"1.2.840.10008.1.2.4.108": true,
+}
+
+// Transfer syntaxes of the JPIP referenced pixel data, which have no pixel data to encapsulate
+const (
+	jpipReferenced        = "1.2.840.10008.1.2.4.94"
+	jpipReferencedDeflate = "1.2.840.10008.1.2.4.95"
+	papyrus3ImplicitVR    = "1.2.840.10008.1.20"
+)
+
+// ErrUnsupportedTransferSyntax is returned for a UID that is not a transfer syntax of the registry
+type ErrUnsupportedTransferSyntax struct {
+	UID string
+}
+
+func (e *ErrUnsupportedTransferSyntax) Error() string {
+	return fmt.Sprintf("unsupported transfer syntax: %v", e.UID)
+}
+
+// TransferSyntaxInfo returns the description of the transfer syntax
+func TransferSyntaxInfo(uid string) (TransferSyntax, error) {
+	info, ok := uidMap[uid]
+	if !ok || info.Type != TypeTransferSyntax {
+		return TransferSyntax{}, &ErrUnsupportedTransferSyntax{UID: uid}
+	}
+	ts := TransferSyntax{
+		UID:       uid,
+		Name:      info.Name,
+		ByteOrder: binary.LittleEndian,
+		Lossy:     lossyTransferSyntaxes[uid],
+		Retired:   info.Status == "Retired",
+	}
+	switch uid {
+	case ImplicitVRLittleEndian, PrivateGELittleEndianImplicitWithBigEndianPixelData, papyrus3ImplicitVR:
+		ts.Implicit = true
+	case ExplicitVRBigEndian:
+		ts.ByteOrder = binary.BigEndian
+	case DeflatedExplicitVRLittleEndian, jpipReferencedDeflate:
+		ts.Deflated = true
+	case RLELossless:
+		ts.Encapsulated = true
+	case jpipReferenced:
+	default:
+		ts.Encapsulated = strings.HasPrefix(uid, "1.2.840.10008.1.2.4.")
+	}
+	return ts, nil
+}
+
+// IsTransferSyntax returns true if the UID is a known transfer syntax
+func IsTransferSyntax(uid string) bool {
+	return uidMap[uid].Type == TypeTransferSyntax
+}
+
+// IsSOPClass returns true if the UID is a known SOP class
+func IsSOPClass(uid string) bool {
+	return uidMap[uid].Type == TypeSOPClass
+}
+
+// IsStorageSOPClass returns true if the UID is a known SOP class of the Storage Service Class, e.g.: CT Image Storage
+func IsStorageSOPClass(uid string) bool {
+	info, ok := uidMap[uid]
+	return ok && info.Type == TypeSOPClass && strings.Contains(info.Name, "Storage") &&
+		!strings.HasPrefix(info.Name, "Storage Commitment")
+}
+
+// IsRetired returns true if the UID is known and retired
+func IsRetired(uid string) bool {
+	return uidMap[uid].Status == "Retired"
+}
+
+var (
+	keywordsOnce sync.Once
+	keywords     map[string]string
+)
+
+// Keyword returns the keyword of the UID, its name in upper camel case without punctuation, e.g.:
+// ExplicitVRLittleEndian or CTImageStorage. It returns an empty string if the UID is unknown
+func Keyword(uid string) string {
+	return toKeyword(uidMap[uid].Name)
+}
+
+// ByKeyword returns the UID of the keyword
+func ByKeyword(keyword string) (Info, error) {
+	keywordsOnce.Do(func() {
+		keywords = make(map[string]string, len(uidMap))
+		for uid, info := range uidMap {
+			if keyword := toKeyword(info.Name); keyword != "" {
+				// Retired UIDs are superseded by the active UIDs of the same name
+				if other, ok := keywords[keyword]; ok && uidMap[other].Status != "Retired" {
+					continue
+				}
+				keywords[keyword] = uid
+			}
+		}
+	})
+	uid, ok := keywords[keyword]
+	if !ok {
+		return Info{}, fmt.Errorf("UID keyword '%s' not found", keyword)
+	}
+	return uidMap[uid], nil
+}
+
+func toKeyword(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	b := strings.Builder{}
+	for _, word := range words {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+func Lookup(uid string) (Info, error) {
+	e, ok := uidMap[uid]
+	if !ok {
+		return Info{}, fmt.Errorf("UID '%s' not found/supported", uid)
+	}
+	return e, nil
+}
+
+func MustLookup(uid string) Info {
+	e, err := Lookup(uid)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
@@ -0,0 +1,60 @@
+package uid
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransferSyntaxInfo(t *testing.T) {
+	assert := assert.New(t)
+	ts, err := TransferSyntaxInfo(ImplicitVRLittleEndian)
+	assert.NoError(err)
+	assert.Equal(TransferSyntax{UID: ImplicitVRLittleEndian, Name: "Implicit VR Little Endian", ByteOrder: binary.LittleEndian, Implicit: true}, ts)
+
+	ts, err = TransferSyntaxInfo(ExplicitVRBigEndian)
+	assert.NoError(err)
+	assert.Equal(binary.BigEndian, ts.ByteOrder)
+	assert.True(ts.Retired)
+
+	ts, _ = TransferSyntaxInfo(DeflatedExplicitVRLittleEndian)
+	assert.True(ts.Deflated)
+	assert.False(ts.Encapsulated)
+	ts, _ = TransferSyntaxInfo(JPEGBaselineProcess1)
+	assert.True(ts.Encapsulated)
+	assert.True(ts.Lossy)
+	ts, _ = TransferSyntaxInfo(JPEG2000ImageCompressionLosslessOnly)
+	assert.True(ts.Encapsulated)
+	assert.False(ts.Lossy)
+	ts, _ = TransferSyntaxInfo(RLELossless)
+	assert.True(ts.Encapsulated)
+
+	_, err = TransferSyntaxInfo(VerificationSOPClass)
+	assert.Error(err)
+	_, _, err = ParseTransferSyntaxUID("1.2.3")
+	var unsupported *ErrUnsupportedTransferSyntax
+	assert.True(errors.As(err, &unsupported))
+	assert.Equal("1.2.3", unsupported.UID)
+}
+
+func TestRegistry(t *testing.T) {
+	assert := assert.New(t)
+	assert.True(IsStorageSOPClass("1.2.840.10008.5.1.4.1.1.2"))
+	assert.True(IsStorageSOPClass(SecondaryCaptureImageStorage))
+	assert.False(IsStorageSOPClass(StorageCommitmentPushModel))
+	assert.False(IsStorageSOPClass(ExplicitVRLittleEndian))
+	assert.True(IsSOPClass(VerificationSOPClass))
+	assert.True(IsTransferSyntax(ExplicitVRLittleEndian))
+	assert.False(IsTransferSyntax("1.2.3"))
+	assert.True(IsRetired(ExplicitVRBigEndian))
+
+	assert.Equal("ExplicitVRLittleEndian", Keyword(ExplicitVRLittleEndian))
+	assert.Equal("JPEGBaselineProcess1", Keyword(JPEGBaselineProcess1))
+	info, err := ByKeyword("CTImageStorage")
+	assert.NoError(err)
+	assert.Equal("1.2.840.10008.5.1.4.1.1.2", info.UID)
+	_, err = ByKeyword("NoSuchKeyword")
+	assert.Error(err)
+}
@@ -1,26 +1,81 @@
 package uid
 
+//go:generate go run ../../../cmd/dicomgen -part6 $DICOM_STANDARD/part06.xml -uids uid_definitions.go
+
 import (
 	"encoding/binary"
-	"fmt"
 )
 
+const (
+	TypeSOPClass                  string = "SOP Class"
+	TypeTransferSyntax            string = "Transfer Syntax"
+	TypeWellKnownFrameOfReference string = "Well-known frame of reference"
+	TypeWellKnownSOPInstance      string = "Well-known SOP instance"
+	TypeCodingScheme              string = "Coding Scheme"
+)
+
+const (
+	PatientRootQRFind               = "1.2.840.10008.5.1.4.1.2.1.1"
+	StudyRootQRFind                 = "1.2.840.10008.5.1.4.1.2.2.1"
+	PatientRootQRGet                = "1.2.840.10008.5.1.4.1.2.1.3"
+	StudyRootQRGet                  = "1.2.840.10008.5.1.4.1.2.2.3"
+	PatientRootQRMove               = "1.2.840.10008.5.1.4.1.2.1.2"
+	StudyRootQRMove                 = "1.2.840.10008.5.1.4.1.2.2.2"
+	ModalityWorklistInformationFind = "1.2.840.10008.5.1.4.31"
+	VerificationSOPClass            = "1.2.840.10008.1.1"
+	StorageCommitmentPushModel      = "1.2.840.10008.1.20.1"
+	StorageCommitmentPushModelInst  = "1.2.840.10008.1.20.1.1"
+	MediaStorageDirectoryStorage    = "1.2.840.10008.1.3.10"
+	SecondaryCaptureImageStorage    = "1.2.840.10008.5.1.4.1.1.7"
+	CTImageStorage                  = "1.2.840.10008.5.1.4.1.1.2"
+	MRImageStorage                  = "1.2.840.10008.5.1.4.1.1.4"
+	ComputedRadiographyImageStorage = "1.2.840.10008.5.1.4.1.1.1"
+	DXImageStorageForPresentation   = "1.2.840.10008.5.1.4.1.1.1.1"
+	DXImageStorageForProcessing     = "1.2.840.10008.5.1.4.1.1.1.1.1"
+	MGImageStorageForPresentation   = "1.2.840.10008.5.1.4.1.1.1.2"
+	MGImageStorageForProcessing     = "1.2.840.10008.5.1.4.1.1.1.2.1"
+	UltrasoundImageStorage          = "1.2.840.10008.5.1.4.1.1.6.1"
+	UltrasoundMultiFrameStorage     = "1.2.840.10008.5.1.4.1.1.3.1"
+	SegmentationStorage             = "1.2.840.10008.5.1.4.1.1.66.4"
+	BasicTextSRStorage              = "1.2.840.10008.5.1.4.1.1.88.11"
+	EnhancedSRStorage               = "1.2.840.10008.5.1.4.1.1.88.22"
+	ComprehensiveSRStorage          = "1.2.840.10008.5.1.4.1.1.88.33"
+	RTStructureSetStorage           = "1.2.840.10008.5.1.4.1.1.481.3"
+)
+
+// Define the support transfer syntax
+const (
+	ImplicitVRLittleEndian                                   = "1.2.840.10008.1.2"
+	ExplicitVRLittleEndian                                   = "1.2.840.10008.1.2.1"
+	ExplicitVRBigEndian                                      = "1.2.840.10008.1.2.2"
+	PrivateGELittleEndianImplicitWithBigEndianPixelData      = "1.2.840.113619.5.2"
+	RLELossless                                              = "1.2.840.10008.1.2.5"
+	DeflatedExplicitVRLittleEndian                           = "1.2.840.10008.1.2.1.99"
+	JPEGBaselineProcess1                                     = "1.2.840.10008.1.2.4.50"
+	JPEGBaselineProcess2And4                                 = "1.2.840.10008.1.2.4.51"
+	JPEGLosslessNonHierarchicalProcesses14                   = "1.2.840.10008.1.2.4.57"
+	JPEGLosslessNonHierarchicalFirstOrderPredictionProcess14 = "1.2.840.10008.1.2.4.70"
+	JPEGLSLosslessImageCompression                           = "1.2.840.10008.1.2.4.80"
+	JPEGLSLossyNearLosslessImageCompression                  = "1.2.840.10008.1.2.4.81"
+	JPEG2000ImageCompressionLosslessOnly                     = "1.2.840.10008.1.2.4.90"
+	JPEG2000ImageCompression                                 = "1.2.840.10008.1.2.4.91"
+	MPEG4AVCH264highProfile                                  = "1.2.840.10008.1.2.4.102"
+	MPEG4AVCH264BDCompatibleHighProfile                      = "1.2.840.10008.1.2.4.103"
+)
+
+type Info struct {
+	UID    string // "1.2.840.10008.x.y.z"
+	Name   string // The UID string, e.g.,"1.2.840.10008.1.2.1".
+	Type   string // "SOP Class", "Transfer Syntax", etc.
+	Part   string // The part of the standard defining the UID, e.g.: PS3.5, or a note.
+	Status string // "" if active. "Retired", if retired.
+}
+
+// ParseTransferSyntaxUID returns the byte order and VR explicitness of the transfer syntax
 func ParseTransferSyntaxUID(uid string) (bo binary.ByteOrder, implicit bool, err error) {
-	switch uid {
-	case ImplicitVRLittleEndian:
-		return binary.LittleEndian, true, nil
-	case DeflatedExplicitVRLittleEndian:
-		fallthrough
-	case ExplicitVRLittleEndian, JPEGBaselineProcess1, JPEG2000ImageCompressionLosslessOnly,
-		JPEGBaselineProcess2And4, JPEGLosslessNonHierarchicalProcesses14, JPEGLSLosslessImageCompression,
-		JPEGLSLossyNearLosslessImageCompression, JPEG2000ImageCompression, MPEG4AVCH264highProfile, MPEG4AVCH264BDCompatibleHighProfile,
-		JPEGLosslessNonHierarchicalFirstOrderPredictionProcess14, RLELossless:
-		return binary.LittleEndian, false, nil
-	case ExplicitVRBigEndian:
-		return binary.BigEndian, false, nil
-	case PrivateGELittleEndianImplicitWithBigEndianPixelData:
-		return binary.LittleEndian, true, nil
-	default:
-		return binary.BigEndian, false, fmt.Errorf("unsupported transfer syntax: %v", uid)
+	ts, err := TransferSyntaxInfo(uid)
+	if err != nil {
+		return binary.BigEndian, false, err
 	}
+	return ts.ByteOrder, ts.Implicit, nil
 }
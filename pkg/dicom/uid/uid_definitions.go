@@ -1,60 +1,5 @@
 package uid
 
-import "fmt"
-
-const (
-	TypeSOPClass                  string = "SOP Class"
-	TypeTransferSyntax            string = "Transfer Syntax"
-	TypeWellKnownFrameOfReference string = "Well-known frame of reference"
-	TypeWellKnownSOPInstance      string = "Well-known SOP instance"
-	TypeCodingScheme              string = "Coding Scheme"
-)
-
-const (
-	PatientRootQRFind               = "1.2.840.10008.5.1.4.1.2.1.1"
-	StudyRootQRFind                 = "1.2.840.10008.5.1.4.1.2.2.1"
-	PatientRootQRGet                = "1.2.840.10008.5.1.4.1.2.1.3"
-	StudyRootQRGet                  = "1.2.840.10008.5.1.4.1.2.2.3"
-	PatientRootQRMove               = "1.2.840.10008.5.1.4.1.2.1.2"
-	StudyRootQRMove                 = "1.2.840.10008.5.1.4.1.2.2.2"
-	ModalityWorklistInformationFind = "1.2.840.10008.5.1.4.31"
-	VerificationSOPClass            = "1.2.840.10008.1.1"
-)
-
-// Define the support transfer syntax
-const (
-	ImplicitVRLittleEndian                                   = "1.2.840.10008.1.2"
-	ExplicitVRLittleEndian                                   = "1.2.840.10008.1.2.1"
-	ExplicitVRBigEndian                                      = "1.2.840.10008.1.2.2"
-	PrivateGELittleEndianImplicitWithBigEndianPixelData      = "1.2.840.113619.5.2"
-	RLELossless                                              = "1.2.840.10008.1.2.5"
-	DeflatedExplicitVRLittleEndian                           = "1.2.840.10008.1.2.1.99"
-	JPEGBaselineProcess1                                     = "1.2.840.10008.1.2.4.50"
-	JPEGBaselineProcess2And4                                 = "1.2.840.10008.1.2.4.51"
-	JPEGLosslessNonHierarchicalProcesses14                   = "1.2.840.10008.1.2.4.57"
-	JPEGLosslessNonHierarchicalFirstOrderPredictionProcess14 = "1.2.840.10008.1.2.4.70"
-	JPEGLSLosslessImageCompression                           = "1.2.840.10008.1.2.4.80"
-	JPEGLSLossyNearLosslessImageCompression                  = "1.2.840.10008.1.2.4.81"
-	JPEG2000ImageCompressionLosslessOnly                     = "1.2.840.10008.1.2.4.90"
-	JPEG2000ImageCompression                                 = "1.2.840.10008.1.2.4.91"
-	MPEG4AVCH264highProfile                                  = "1.2.840.10008.1.2.4.102"
-	MPEG4AVCH264BDCompatibleHighProfile                      = "1.2.840.10008.1.2.4.103"
-)
-
-type Info struct {
-	UID    string // "1.2.840.10008.x.y.z"
-	Name   string // The UID string, e.g.,"1.2.840.10008.1.2.1".
-	Type   string // "SOP Class", "Transfer Syntax", etc.
-	Part   string // Not used.
-	Status string // "" if active. "Retired", if retired.
-}
-
-var UncompressedSyntax = map[string]bool{
-	ImplicitVRLittleEndian: true,
-	ExplicitVRLittleEndian: true,
-	ExplicitVRBigEndian:    true,
-}
-
 var uidMap = map[string]Info{
 	"1.2.840.10008.1.1":                {"1.2.840.10008.1.1", "Verification SOP Class", TypeSOPClass, "", ""},
 	"1.2.840.10008.1.2":                {"1.2.840.10008.1.2", "Implicit VR Little Endian", TypeTransferSyntax, "Default Transfer Syntax for DICOM", ""},
@@ -457,19 +402,3 @@ var uidMap = map[string]Info{
 	"1.2.840.10008.15.0.4.8":           {"1.2.840.10008.15.0.4.8", "dicomTransferCapability", "LDAP OID", "", ""},
 	"1.2.840.10008.15.1.1":             {"1.2.840.10008.15.1.1", "Universal Coordinated Time", "Synchronization Frame of Reference", "", ""},
 }
-
-func Lookup(uid string) (Info, error) {
-	e, ok := uidMap[uid]
-	if !ok {
-		return Info{}, fmt.Errorf("UID '%s' not found/supported", uid)
-	}
-	return e, nil
-}
-
-func MustLookup(uid string) Info {
-	e, err := Lookup(uid)
-	if err != nil {
-		panic(err)
-	}
-	return e
-}
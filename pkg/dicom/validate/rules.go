@@ -0,0 +1,189 @@
+package validate
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// rule holds the constraints of the values of a string VR, defined by PS3.5 6.2
+type rule struct {
+	// maxLength is the maximum number of characters of a value, 0 if unlimited
+	maxLength int
+	charset   func(r rune) bool
+	format    func(value string) error
+}
+
+var rules = map[string]rule{
+	vr.ApplicationEntity:           {maxLength: 16, charset: isDefault},
+	vr.AgeString:                   {maxLength: 4, charset: isAnyOf("0123456789DWMY"), format: validateAge},
+	vr.CodeString:                  {maxLength: 16, charset: isAnyOf("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 _")},
+	vr.Date:                        {maxLength: 8, charset: isAnyOf("0123456789"), format: validateDate},
+	vr.DecimalString:               {maxLength: 16, charset: isAnyOf("0123456789+-Ee. "), format: validateDecimal},
+	vr.DateTime:                    {maxLength: 26, charset: isAnyOf("0123456789+-."), format: validateDateTime},
+	vr.IntegerString:               {maxLength: 12, charset: isAnyOf("0123456789+- "), format: validateInteger},
+	vr.LongString:                  {maxLength: 64, charset: isString},
+	vr.LongText:                    {maxLength: 10240, charset: isText},
+	vr.PersonName:                  {charset: isString, format: validatePersonName},
+	vr.ShortString:                 {maxLength: 16, charset: isString},
+	vr.ShortText:                   {maxLength: 1024, charset: isText},
+	vr.Time:                        {maxLength: 14, charset: isAnyOf("0123456789."), format: validateTime},
+	vr.UnlimitedCharacters:         {charset: isString},
+	vr.UniqueIdentifier:            {maxLength: uid.MaxLength, charset: isAnyOf("0123456789."), format: uid.Validate},
+	vr.UniversalResourceIdentifier: {charset: isURI},
+	vr.UnlimitedText:               {charset: isText},
+}
+
+// isDefault returns true for the characters of the default repertoire, except the control characters and backslash
+func isDefault(r rune) bool {
+	return r >= 0x20 && r < 0x7F && r != '\\'
+}
+
+// isString returns true for the characters of the string VRs: any character except the control characters other
+// than ESC, used by the code extensions of the specific character sets
+func isString(r rune) bool {
+	return r == 0x1B || r >= 0x20 && r != 0x7F && r != '\\'
+}
+
+// isText returns true for the characters of the text VRs, which also allow TAB, LF, FF, CR and backslash
+func isText(r rune) bool {
+	return r == '\t' || r == '\n' || r == '\f' || r == '\r' || r == '\\' || isString(r)
+}
+
+// isURI returns true for the characters of the URIs, which have no space
+func isURI(r rune) bool {
+	return r > 0x20 && r < 0x7F && r != '\\'
+}
+
+func isAnyOf(chars string) func(r rune) bool {
+	return func(r rune) bool {
+		return strings.ContainsRune(chars, r)
+	}
+}
+
+func invalidCharacter(value string, charset func(r rune) bool) (rune, bool) {
+	for _, r := range value {
+		if !charset(r) {
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+func length(value string) int {
+	return utf8.RuneCountInString(value)
+}
+
+func validateAge(value string) error {
+	if len(value) != 4 || strings.IndexAny(value[:3], "DWMY") >= 0 || !strings.ContainsAny(value[3:], "DWMY") {
+		return fmt.Errorf("expected nnnD, nnnW, nnnM or nnnY")
+	}
+	return nil
+}
+
+func validateDate(value string) error {
+	if _, err := time.Parse("20060102", value); err != nil {
+		return fmt.Errorf("expected YYYYMMDD")
+	}
+	return nil
+}
+
+func validateDecimal(value string) error {
+	if _, err := strconv.ParseFloat(value, 64); err != nil {
+		return fmt.Errorf("not a decimal number")
+	}
+	return nil
+}
+
+func validateInteger(value string) error {
+	if _, err := strconv.ParseInt(value, 10, 32); err != nil {
+		return fmt.Errorf("not a 32-bit integer")
+	}
+	return nil
+}
+
+// validateTime validates the time formatted as HH[MM[SS[.F{1-6}]]]
+func validateTime(value string) error {
+	errFormat := fmt.Errorf("expected HH[MM[SS[.FFFFFF]]]")
+	hms, fraction, hasFraction := strings.Cut(value, ".")
+	if len(hms)%2 != 0 || len(hms) < 2 || len(hms) > 6 || hasFraction && (len(hms) != 6 || fraction == "" || len(fraction) > 6) {
+		return errFormat
+	}
+	for i, max := range []int{23, 59, 60} {
+		if 2*i >= len(hms) {
+			break
+		}
+		n, err := strconv.Atoi(hms[2*i : 2*i+2])
+		if err != nil || n > max {
+			return errFormat
+		}
+	}
+	if strings.Trim(fraction, "0123456789") != "" {
+		return errFormat
+	}
+	return nil
+}
+
+// validateDateTime validates the date time formatted as YYYY[MM[DD[HH[MM[SS[.F{1-6}]]]]]][&ZZXX]
+func validateDateTime(value string) error {
+	errFormat := fmt.Errorf("expected YYYY[MM[DD[HH[MM[SS[.FFFFFF]]]]]][&ZZXX]")
+	if i := strings.LastIndexAny(value, "+-"); i >= 0 {
+		offset := value[i+1:]
+		hours, err := strconv.Atoi(offset[:min(2, len(offset))])
+		if len(offset) != 4 || err != nil || hours > 14 {
+			return errFormat
+		}
+		if minutes, err := strconv.Atoi(offset[2:]); err != nil || minutes > 59 {
+			return errFormat
+		}
+		value = value[:i]
+	}
+	date := value
+	if len(date) > 8 {
+		date = value[:8]
+	}
+	switch len(date) {
+	case 4, 6, 8:
+	default:
+		return errFormat
+	}
+	if _, err := time.Parse("20060102"[:len(date)], date); err != nil {
+		return errFormat
+	}
+	if len(value) > 8 {
+		if err := validateTime(value[8:]); err != nil {
+			return errFormat
+		}
+	}
+	return nil
+}
+
+// validatePersonName validates the component groups of the name, alphabetic, ideographic and phonetic, of at most
+// 64 characters and 5 components each
+func validatePersonName(value string) error {
+	groups := strings.Split(value, "=")
+	if len(groups) > 3 {
+		return fmt.Errorf("more than 3 component groups")
+	}
+	for _, group := range groups {
+		if length(group) > 64 {
+			return fmt.Errorf("component group longer than 64 characters")
+		}
+		if strings.Count(group, "^") > 4 {
+			return fmt.Errorf("more than 5 components")
+		}
+	}
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
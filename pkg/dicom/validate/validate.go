@@ -0,0 +1,250 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+)
+
+// Severity is the severity of a finding
+type Severity string
+
+const (
+	// SeverityError is a violation of the standard
+	SeverityError Severity = "error"
+	// SeverityWarning is an encoding issue that most readers accept
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is an issue found in the value of an element
+type Finding struct {
+	// Path locates the element from the root dataset, e.g.: (0008,1140)[0].(0008,1155) for an element of the first
+	// item of a sequence
+	Path     string
+	Tag      tag.DicomTag
+	Severity Severity
+	Message  string
+}
+
+// String returns the finding as severity path: message
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s: %s", f.Severity, f.Path, f.Message)
+}
+
+// Dataset validates the value of each element of the dataset and of the items of its sequences against the rules of
+// its VR: character repertoire, maximum length, padding, format, and VM against the dictionary
+func Dataset(ds go2com.Dataset) []Finding {
+	return validateElements(ds.Elements, "")
+}
+
+// HasErrors returns true if one of the findings is an error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+func validateElements(elements []*go2com.Element, parent string) []Finding {
+	res := make([]Finding, 0)
+	for _, elem := range elements {
+		if elem == nil {
+			continue
+		}
+		path := parent + elem.Tag.String()
+		if elem.ValueRepresentationStr == vr.SequenceOfItems {
+			for i, item := range splitItems(elem) {
+				res = append(res, validateElements(item, fmt.Sprintf("%s[%d].", path, i))...)
+			}
+			continue
+		}
+		res = append(res, validateElement(elem, path)...)
+	}
+	return res
+}
+
+func validateElement(elem *go2com.Element, path string) []Finding {
+	var res []Finding
+	report := func(severity Severity, format string, args ...interface{}) {
+		res = append(res, Finding{Path: path, Tag: elem.Tag, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	valueRepresentation := elem.ValueRepresentationStr
+	if elem.ValueLength%2 != 0 && elem.ValueLength != go2com.VLUndefinedLength {
+		report(SeverityWarning, "odd value length %d", elem.ValueLength)
+	}
+
+	rule, ok := rules[valueRepresentation]
+	values := stringValues(elem)
+	// The UI values are padded with NUL, the other string values with space
+	switch p := padding(elem, values); {
+	case valueRepresentation == vr.UniqueIdentifier && strings.Contains(p, " "):
+		report(SeverityWarning, "value padded with space instead of NUL")
+	case valueRepresentation != vr.UniqueIdentifier && strings.Contains(p, "\x00"):
+		report(SeverityWarning, "value padded with NUL instead of space")
+	}
+	if ok && values != nil {
+		for _, value := range values {
+			value = strings.TrimRight(value, "\x00")
+			if rule.maxLength > 0 && length(value) > rule.maxLength {
+				report(SeverityError, "value %q is longer than %d characters of the %s VR", value, rule.maxLength, valueRepresentation)
+			}
+			if c, ok := invalidCharacter(value, rule.charset); ok {
+				report(SeverityError, "value %q has character %q not allowed by the %s VR", value, c, valueRepresentation)
+				continue
+			}
+			if rule.format != nil && strings.TrimSpace(value) != "" {
+				if err := rule.format(strings.TrimSpace(value)); err != nil {
+					report(SeverityError, "invalid %s value %q: %v", valueRepresentation, value, err)
+				}
+			}
+		}
+	}
+	if valueRepresentation == vr.IntegerString {
+		for _, v := range intValues(elem) {
+			if v < -1<<31 || v > 1<<31-1 {
+				report(SeverityError, "IS value %d out of the range of 32-bit integers", v)
+			}
+		}
+	}
+
+	// The multiplicity of the private tags depends on their creator
+	if tag.IsPrivateTag(elem.Tag.Group) {
+		return res
+	}
+	info, err := tag.Find(elem.Tag)
+	if err != nil {
+		return res
+	}
+	allowed := info.VRs()
+	if elem.Tag == tag.PixelData {
+		// The pixel data is OB or OW depending on the transfer syntax and the bits allocated
+		allowed = []string{vr.OtherByte, vr.OtherWord}
+	}
+	if !contains(allowed, valueRepresentation) {
+		// The values of the elements of unknown VR are not decoded
+		if valueRepresentation != vr.Unknown {
+			report(SeverityWarning, "VR %s differs from the VR %s of %s in the dictionary", valueRepresentation, info.VR, info.Name)
+		}
+		return res
+	}
+	vm, err := info.Multiplicity()
+	if err != nil {
+		return res
+	}
+	if n := multiplicity(elem, values); n > 0 && !vm.Allows(n) {
+		report(SeverityError, "%d values do not match the VM %s of %s", n, vm, info.Name)
+	}
+	return res
+}
+
+// stringValues returns the values of the string VRs, or nil if the value is not a string
+func stringValues(elem *go2com.Element) []string {
+	var values []string
+	switch v := elem.Value.RawValue.(type) {
+	case string:
+		values = strings.Split(v, "\\")
+	case []string:
+		values = v
+	default:
+		return nil
+	}
+	// The text VRs have a single value that may contain backslashes
+	switch elem.ValueRepresentationStr {
+	case vr.LongText, vr.ShortText, vr.UnlimitedText, vr.UniversalResourceIdentifier:
+		return []string{strings.Join(values, "\\")}
+	}
+	return values
+}
+
+// padding returns the trailing padding of the value: the padding trimmed by the reader, or the trailing spaces and
+// NULs of the last value of an element created in memory
+func padding(elem *go2com.Element, values []string) string {
+	if elem.Padding != "" || len(values) == 0 {
+		return elem.Padding
+	}
+	last := values[len(values)-1]
+	return last[len(strings.TrimRight(last, " \x00")):]
+}
+
+func intValues(elem *go2com.Element) []int {
+	switch v := elem.Value.RawValue.(type) {
+	case int:
+		return []int{v}
+	case []int:
+		return v
+	}
+	return nil
+}
+
+// multiplicity returns the number of values of the element, 0 if the value is empty
+func multiplicity(elem *go2com.Element, values []string) int {
+	if values != nil {
+		if len(values) == 1 && strings.TrimSpace(values[0]) == "" {
+			return 0
+		}
+		return len(values)
+	}
+	switch v := elem.Value.RawValue.(type) {
+	case nil:
+		return 0
+	case []byte:
+		// The values of the OB, OW, UN... VRs are a single value
+		if len(v) == 0 {
+			return 0
+		}
+		return 1
+	}
+	rv := reflect.ValueOf(elem.Value.RawValue)
+	n := 1
+	if rv.Kind() == reflect.Slice {
+		n = rv.Len()
+	}
+	// The tags of the AT VR are read as pairs of group and element
+	if elem.ValueRepresentationStr == vr.AttributeTag {
+		n /= 2
+	}
+	return n
+}
+
+func splitItems(elem *go2com.Element) [][]*go2com.Element {
+	subElements, _ := elem.Value.RawValue.([]*go2com.Element)
+	items := make([][]*go2com.Element, 0)
+	var current []*go2com.Element
+	for _, subElem := range subElements {
+		if subElem == nil {
+			continue
+		}
+		if len(current) > 0 && !tagLess(current[len(current)-1].Tag, subElem.Tag) {
+			items = append(items, current)
+			current = nil
+		}
+		current = append(current, subElem)
+	}
+	if len(current) > 0 {
+		items = append(items, current)
+	}
+	return items
+}
+
+func tagLess(a, b tag.DicomTag) bool {
+	if a.Group != b.Group {
+		return a.Group < b.Group
+	}
+	return a.Element < b.Element
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
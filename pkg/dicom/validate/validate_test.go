@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+func paths(findings []Finding, severity Severity) []string {
+	res := make([]string, 0)
+	for _, f := range findings {
+		if f.Severity == severity {
+			res = append(res, f.Path)
+		}
+	}
+	return res
+}
+
+func TestDataset(t *testing.T) {
+	assert := assert.New(t)
+
+	valid := go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.ImageType, []string{"ORIGINAL", "PRIMARY", "AXIAL"}),
+		go2com.NewElement(tag.StudyDate, "20200229"),
+		go2com.NewElement(tag.StudyTime, "235960.123456"),
+		go2com.NewElement(tag.AcquisitionDateTime, "20200102101112.5+0100"),
+		go2com.NewElement(tag.Modality, "CT"),
+		go2com.NewElement(tag.PatientName, "Doe^John^^Dr=^"),
+		go2com.NewElement(tag.PatientAge, "050Y"),
+		go2com.NewElement(tag.SliceThickness, 1.25),
+		go2com.NewElement(tag.SeriesNumber, 3),
+		go2com.NewElement(tag.ImagePositionPatient, []string{"-1.5", "2E3", "0"}),
+		go2com.NewElement(tag.StudyDescription, ""),
+		go2com.NewElement(tag.ImageComments, "Line 1\r\nLine 2\\3"),
+		go2com.NewElement(tag.Rows, 512),
+		go2com.NewElement(tag.ReferencedImageSequence, []*go2com.Element{
+			go2com.NewElement(tag.ReferencedSOPClassUID, "1.2.840.10008.5.1.4.1.1.2"),
+			go2com.NewElement(tag.ReferencedSOPInstanceUID, "1.2.3"),
+		}),
+	}}
+	assert.Empty(Dataset(valid))
+
+	invalid := go2com.Dataset{Elements: []*go2com.Element{
+		go2com.NewElement(tag.ImageType, []string{"original", "PRIMARY"}),
+		go2com.NewElement(tag.StudyDate, "20200230"),
+		go2com.NewElement(tag.StudyTime, "246000"),
+		go2com.NewElement(tag.AcquisitionDateTime, "2020010210+2500"),
+		go2com.NewElement(tag.AccessionNumber, "ACCESSION-NUMBER-TOO-LONG"),
+		go2com.NewElement(tag.PatientAge, "50Y"),
+		go2com.NewElement(tag.SeriesNumber, "III"),
+		go2com.NewElement(tag.ImagePositionPatient, []string{"1.5", "2"}),
+		go2com.NewElement(tag.StudyDescription, "Line\nbreak"),
+		go2com.NewElement(tag.ReferencedImageSequence, []*go2com.Element{
+			go2com.NewElement(tag.ReferencedSOPInstanceUID, "1.2.03"),
+			go2com.NewElement(tag.ReferencedSOPInstanceUID, "1.2.3.4"),
+			go2com.NewElement(tag.ReferencedFrameNumber, 2147483648),
+		}),
+		go2com.NewElement(tag.InstitutionName, "Hospital\x00"),
+	}}
+	findings := Dataset(invalid)
+	assert.True(HasErrors(findings))
+	assert.Equal([]string{
+		"(0008,0008)",
+		"(0008,0020)",
+		"(0008,0030)",
+		"(0008,002a)",
+		"(0008,0050)",
+		"(0010,1010)",
+		"(0020,0011)",
+		"(0020,0032)",
+		"(0008,1030)",
+		"(0008,1140)[0].(0008,1155)",
+		"(0008,1140)[1].(0008,1160)",
+	}, paths(findings, SeverityError))
+	assert.Equal([]string{"(0008,0080)"}, paths(findings, SeverityWarning))
+	for _, f := range findings {
+		assert.NotEmpty(f.Message)
+	}
+}
+
+func TestDataset_Padding(t *testing.T) {
+	assert := assert.New(t)
+
+	// Explicit VR Little Endian elements of odd length values and their padding
+	data := []byte{
+		0x08, 0x00, 0x16, 0x00, 'U', 'I', 0x04, 0x00, '1', '.', '2', 0x00, // NUL padded UI
+		0x08, 0x00, 0x18, 0x00, 'U', 'I', 0x04, 0x00, '1', '.', '3', ' ', // Space padded UI
+		0x08, 0x00, 0x60, 0x00, 'C', 'S', 0x04, 0x00, 'M', 'R', ' ', ' ', // Space padded CS
+		0x08, 0x00, 0x80, 0x00, 'L', 'O', 0x04, 0x00, 'A', 'B', 'C', 0x00, // NUL padded LO
+		0x20, 0x00, 0x11, 0x00, 'I', 'S', 0x02, 0x00, '3', 0x00, // NUL padded IS
+	}
+	rd := go2com.NewDICOMReader(bufio.NewReader(bytes.NewReader(data)), go2com.WithDatasetTransferSyntax(binary.LittleEndian, false))
+	assert.NoError(rd.Parse())
+	findings := Dataset(rd.GetDataset())
+	assert.Empty(paths(findings, SeverityError))
+	assert.Equal([]string{"(0008,0018)", "(0008,0080)", "(0020,0011)"}, paths(findings, SeverityWarning))
+}
+
+func TestDataset_File(t *testing.T) {
+	assert := assert.New(t)
+
+	f, err := os.Open("../../../dicom_test/014.dcm")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	assert.NoError(err)
+	rd := go2com.NewDICOMReader(bufio.NewReader(f), go2com.WithSetFileSize(info.Size()))
+	assert.NoError(rd.Parse())
+	for _, finding := range Dataset(rd.GetDataset()) {
+		t.Log(finding)
+	}
+}
@@ -0,0 +1,124 @@
+package dicomweb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	MediaTypeDICOM       = "application/dicom"
+	MediaTypeDICOMJSON   = "application/dicom+json"
+	MediaTypeOctetStream = "application/octet-stream"
+	MediaTypeMultipart   = "multipart/related"
+)
+
+// Client issues QIDO-RS, WADO-RS and STOW-RS requests against a DICOMweb origin server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// NewClient returns a new DICOMweb client. The base URL is the service root,
+// e.g.: http://127.0.0.1:8042/dicom-web
+func NewClient(baseURL string, options ...func(*Client)) *Client {
+	client := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+	}
+	for _, opt := range options {
+		opt(client)
+	}
+	return client
+}
+
+// WithHTTPClient provides option to use a custom http client, e.g.: with timeout or TLS configuration
+func WithHTTPClient(httpClient *http.Client) func(*Client) {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader provides option to add a header to every request sent by the client
+func WithHeader(key, value string) func(*Client) {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithBearerToken provides option to authenticate every request with the given bearer token
+func WithBearerToken(token string) func(*Client) {
+	return func(c *Client) {
+		c.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// StatusError is returned when the origin server responds with an unexpected HTTP status
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("dicomweb: unexpected response status %s", e.Status)
+	}
+	return fmt.Sprintf("dicomweb: unexpected response status %s: %s", e.Status, e.Body)
+}
+
+// resourcePath builds the resource path for the given study, series and instance UIDs.
+// Empty UIDs stop the path at the previous level
+func resourcePath(studyUID, seriesUID, instanceUID string) string {
+	path := "/studies"
+	if studyUID == "" {
+		return path
+	}
+	path += "/" + studyUID
+	if seriesUID == "" {
+		return path
+	}
+	path += "/series/" + seriesUID
+	if instanceUID == "" {
+		return path
+	}
+	return path + "/instances/" + instanceUID
+}
+
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	return req, nil
+}
+
+// do sends the request and returns the response if its status is one of the accepted ones.
+// The caller is responsible for closing the response body
+func (c *Client) do(req *http.Request, accepted ...int) (*http.Response, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	for _, code := range accepted {
+		if resp.StatusCode == code {
+			return resp, nil
+		}
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return nil, &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
@@ -0,0 +1,196 @@
+package dicomweb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/stretchr/testify/assert"
+)
+
+var testFiles = []string{"../../dicom_test/02.dcm", "../../dicom_test/014.dcm"}
+
+func writeMultipart(w http.ResponseWriter, partType string, parts [][]byte, withLength bool) {
+	mw := multipart.NewWriter(w)
+	w.Header().Set("Content-Type", fmt.Sprintf("multipart/related; type=%q; boundary=%s", partType, mw.Boundary()))
+	for _, part := range parts {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Type", partType)
+		if withLength {
+			header.Set("Content-Length", strconv.Itoa(len(part)))
+		}
+		pw, _ := mw.CreatePart(header)
+		_, _ = pw.Write(part)
+	}
+	_ = mw.Close()
+}
+
+func newTestServer(t *testing.T) *httptest.Server {
+	var parts [][]byte
+	var retrieveCount int
+	for _, fPath := range testFiles {
+		b, err := os.ReadFile(fPath)
+		assert.NoError(t, err)
+		parts = append(parts, b)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/dicom-web/studies", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			assert.Equal(t, "CT", r.URL.Query().Get("00080060"))
+			assert.Equal(t, "20200101-", r.URL.Query().Get("00080020"))
+			assert.Equal(t, "10", r.URL.Query().Get("limit"))
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			w.Header().Set("Content-Type", MediaTypeDICOMJSON)
+			_, _ = w.Write([]byte(`[{"0020000D":{"vr":"UI","Value":["1.2.3"]},"00100010":{"vr":"PN","Value":[{"Alphabetic":"Doe^John"}]}}]`))
+		case http.MethodPost:
+			mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			assert.NoError(t, err)
+			assert.Equal(t, "multipart/related", mediaType)
+			assert.Equal(t, MediaTypeDICOM, params["type"])
+			mr := multipart.NewReader(r.Body, params["boundary"])
+			count := 0
+			for {
+				part, err := mr.NextPart()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+				b, _ := io.ReadAll(part)
+				assert.Equal(t, parts[count], b)
+				count++
+			}
+			w.Header().Set("Content-Type", MediaTypeDICOMJSON)
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = fmt.Fprintf(w, `{"00081199":{"vr":"SQ","Value":[{"00081150":{"vr":"UI","Value":["1.2.840.10008.5.1.4.1.1.2"]},"00081155":{"vr":"UI","Value":["1.2.3.4"]}}]},`+
+				`"00081198":{"vr":"SQ","Value":[{"00081155":{"vr":"UI","Value":["1.2.3.5"]},"00081197":{"vr":"US","Value":[272]}}]},"count":{"vr":"US","Value":[%d]}}`, count)
+		}
+	})
+	mux.HandleFunc("/dicom-web/studies/1.2.3/series", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/dicom-web/studies/1.2.3", func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept"), `type="application/dicom"`)
+		// Alternate between parts with and without Content-Length
+		retrieveCount++
+		writeMultipart(w, MediaTypeDICOM, parts, retrieveCount%2 == 1)
+	})
+	mux.HandleFunc("/dicom-web/studies/1.2.3/series/4.5/instances/6.7/frames/1,2", func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.Header.Get("Accept"), `type="application/octet-stream"; transfer-syntax=1.2.840.10008.1.2.1; q=1.0`)
+		assert.Contains(t, r.Header.Get("Accept"), `type="image/jp2"; transfer-syntax=1.2.840.10008.1.2.4.90; q=0.9`)
+		mw := multipart.NewWriter(w)
+		w.Header().Set("Content-Type", fmt.Sprintf("multipart/related; type=%q; boundary=%s", MediaTypeOctetStream, mw.Boundary()))
+		for i := 0; i < 2; i++ {
+			header := make(textproto.MIMEHeader)
+			header.Set("Content-Type", MediaTypeOctetStream+"; transfer-syntax=1.2.840.10008.1.2.1")
+			pw, _ := mw.CreatePart(header)
+			_, _ = pw.Write([]byte{byte(i), byte(i)})
+		}
+		_ = mw.Close()
+	})
+	mux.HandleFunc("/dicom-web/studies/9.9", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "study not found", http.StatusNotFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestClient_Search(t *testing.T) {
+	assert := assert.New(t)
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	client := NewClient(srv.URL+"/dicom-web/", WithBearerToken("secret"))
+	res, err := client.Search(context.Background(), Query{
+		Level: StudyLevel,
+		Filters: []Filter{
+			Match(tag.Modality, "CT"),
+			MatchDateRange(tag.StudyDate, time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}),
+		},
+		Limit: 10,
+	})
+	assert.NoError(err)
+	assert.Len(res, 1)
+	uids, err := res[0].GetElementByTagString("0020000D")
+	assert.NoError(err)
+	assert.Equal([]interface{}{"1.2.3"}, uids)
+
+	res, err = client.SearchSeries(context.Background(), "1.2.3")
+	assert.NoError(err)
+	assert.Len(res, 0)
+
+	_, err = client.Search(context.Background(), Query{Level: InstanceLevel, SeriesInstanceUID: "4.5"})
+	assert.Error(err)
+}
+
+func TestClient_RetrieveStudy(t *testing.T) {
+	assert := assert.New(t)
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	client := NewClient(srv.URL + "/dicom-web")
+	for i := 0; i < 2; i++ {
+		var uids []string
+		err := client.RetrieveStudy(context.Background(), "1.2.3", func(instance *Instance) error {
+			fileUID, err := instance.Dataset.RetrieveFileUID()
+			if err != nil {
+				return err
+			}
+			uids = append(uids, fileUID.SOPInstanceUID)
+			return nil
+		}, WithRetrieveTransferSyntax("*"))
+		assert.NoError(err)
+		assert.Len(uids, len(testFiles))
+	}
+
+	err := client.RetrieveStudy(context.Background(), "9.9", func(instance *Instance) error { return nil })
+	assert.Error(err)
+	statusErr, ok := err.(*StatusError)
+	assert.True(ok)
+	assert.Equal(http.StatusNotFound, statusErr.StatusCode)
+
+	err = client.RetrieveStudy(context.Background(), "", func(instance *Instance) error { return nil })
+	assert.Error(err)
+}
+
+func TestClient_RetrieveFrames(t *testing.T) {
+	assert := assert.New(t)
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	client := NewClient(srv.URL + "/dicom-web")
+	frames, err := client.RetrieveFrames(context.Background(), "1.2.3", "4.5", "6.7", []int{1, 2}, "1.2.840.10008.1.2.1", "1.2.840.10008.1.2.4.90")
+	assert.NoError(err)
+	assert.Len(frames, 2)
+	assert.Equal(2, frames[1].Number)
+	assert.Equal("1.2.840.10008.1.2.1", frames[1].TransferSyntaxUID)
+	assert.Equal([]byte{1, 1}, frames[1].Data)
+
+	_, err = client.RetrieveFrames(context.Background(), "1.2.3", "4.5", "6.7", []int{0})
+	assert.Error(err)
+}
+
+func TestClient_StoreFiles(t *testing.T) {
+	assert := assert.New(t)
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	client := NewClient(srv.URL + "/dicom-web")
+	res, err := client.StoreFiles(context.Background(), "", testFiles...)
+	assert.NoError(err)
+	assert.Len(res.Referenced, 1)
+	assert.Equal("1.2.3.4", res.Referenced[0].SOPInstanceUID)
+	assert.Len(res.Failed, 1)
+	assert.Equal(272, res.Failed[0].FailureReason)
+	assert.Equal([]interface{}{float64(len(testFiles))}, res.Response["count"].Value)
+}
@@ -0,0 +1,166 @@
+package dicomweb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+type QueryLevel int
+
+const (
+	StudyLevel QueryLevel = iota
+	SeriesLevel
+	InstanceLevel
+)
+
+// Filter is a single QIDO-RS attribute matching key
+type Filter struct {
+	Tag   tag.DicomTag
+	Value string
+}
+
+// Match returns a filter for single value or wildcard matching. '*' and '?' are passed as is
+func Match(t tag.DicomTag, value string) Filter {
+	return Filter{Tag: t, Value: value}
+}
+
+// MatchUIDList returns a filter matching any of the given UIDs
+func MatchUIDList(t tag.DicomTag, uids ...string) Filter {
+	return Filter{Tag: t, Value: strings.Join(uids, ",")}
+}
+
+// MatchRange returns a filter for range matching. An empty bound leaves the range open on that side
+func MatchRange(t tag.DicomTag, lower, upper string) Filter {
+	return Filter{Tag: t, Value: lower + "-" + upper}
+}
+
+// MatchDateRange returns a filter for range matching on a DA attribute. A zero time leaves the range open on that side
+func MatchDateRange(t tag.DicomTag, from, to time.Time) Filter {
+	var lower, upper string
+	if !from.IsZero() {
+		lower = from.Format("20060102")
+	}
+	if !to.IsZero() {
+		upper = to.Format("20060102")
+	}
+	return MatchRange(t, lower, upper)
+}
+
+// Query defines a QIDO-RS search
+type Query struct {
+	Level QueryLevel
+	// StudyInstanceUID scopes series and instance searches to the given study
+	StudyInstanceUID string
+	// SeriesInstanceUID scopes instance searches to the given series. Requires StudyInstanceUID
+	SeriesInstanceUID string
+	Filters           []Filter
+	IncludeFields     []tag.DicomTag
+	IncludeAllFields  bool
+	FuzzyMatching     bool
+	Limit             int
+	Offset            int
+}
+
+func (q Query) path() (string, error) {
+	switch q.Level {
+	case StudyLevel:
+		return "/studies", nil
+	case SeriesLevel:
+		if q.StudyInstanceUID == "" {
+			return "/series", nil
+		}
+		return resourcePath(q.StudyInstanceUID, "", "") + "/series", nil
+	case InstanceLevel:
+		if q.StudyInstanceUID == "" {
+			if q.SeriesInstanceUID != "" {
+				return "", fmt.Errorf("dicomweb: series scoped search requires a study instance uid")
+			}
+			return "/instances", nil
+		}
+		return resourcePath(q.StudyInstanceUID, q.SeriesInstanceUID, "") + "/instances", nil
+	default:
+		return "", fmt.Errorf("dicomweb: unsupported query level %d", q.Level)
+	}
+}
+
+func (q Query) values() url.Values {
+	values := make(url.Values)
+	for _, filter := range q.Filters {
+		values.Add(filter.Tag.StringWithoutParentheses(), filter.Value)
+	}
+	if q.IncludeAllFields {
+		values.Add("includefield", "all")
+	} else {
+		for _, t := range q.IncludeFields {
+			values.Add("includefield", t.StringWithoutParentheses())
+		}
+	}
+	if q.FuzzyMatching {
+		values.Set("fuzzymatching", "true")
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Offset > 0 {
+		values.Set("offset", strconv.Itoa(q.Offset))
+	}
+	return values
+}
+
+// Search issues a QIDO-RS query and returns the matched results in DICOM JSON model
+func (c *Client) Search(ctx context.Context, query Query) ([]go2com.MappedTag, error) {
+	path, err := query.path()
+	if err != nil {
+		return nil, err
+	}
+	if values := query.values(); len(values) > 0 {
+		path += "?" + values.Encode()
+	}
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", MediaTypeDICOMJSON)
+
+	resp, err := c.do(req, http.StatusOK, http.StatusPartialContent, http.StatusNoContent)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res := make([]go2com.MappedTag, 0)
+	if resp.StatusCode == http.StatusNoContent {
+		return res, nil
+	}
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	if err != nil {
+		return nil, fmt.Errorf("dicomweb: cannot decode search response: %v", err)
+	}
+	return res, nil
+}
+
+// SearchStudies searches for studies matching the filters
+func (c *Client) SearchStudies(ctx context.Context, filters ...Filter) ([]go2com.MappedTag, error) {
+	return c.Search(ctx, Query{Level: StudyLevel, Filters: filters})
+}
+
+// SearchSeries searches for series of the given study matching the filters. If the study
+// instance UID is empty, all series are searched
+func (c *Client) SearchSeries(ctx context.Context, studyUID string, filters ...Filter) ([]go2com.MappedTag, error) {
+	return c.Search(ctx, Query{Level: SeriesLevel, StudyInstanceUID: studyUID, Filters: filters})
+}
+
+// SearchInstances searches for instances of the given series matching the filters. Empty UIDs widen the
+// search scope
+func (c *Client) SearchInstances(ctx context.Context, studyUID, seriesUID string, filters ...Filter) ([]go2com.MappedTag, error) {
+	return c.Search(ctx, Query{Level: InstanceLevel, StudyInstanceUID: studyUID, SeriesInstanceUID: seriesUID, Filters: filters})
+}
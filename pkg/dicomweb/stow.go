@@ -0,0 +1,168 @@
+package dicomweb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+
+	"github.com/okieraised/go2com"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+)
+
+// StoredInstance is an entry of the Referenced or Failed SOP Sequence of a STOW-RS response
+type StoredInstance struct {
+	SOPClassUID    string
+	SOPInstanceUID string
+	RetrieveURL    string
+	FailureReason  int
+	WarningReason  int
+}
+
+// StoreResult holds the STOW-RS response
+type StoreResult struct {
+	Response   go2com.MappedTag
+	Referenced []StoredInstance
+	Failed     []StoredInstance
+}
+
+// Store uploads the given Part 10 instances with STOW-RS. The instances are streamed to the origin server
+// in a single multipart request. If the study instance UID is not empty, the instances must belong to that study
+func (c *Client) Store(ctx context.Context, studyUID string, instances ...io.Reader) (*StoreResult, error) {
+	opener := func(index int) (io.ReadCloser, error) {
+		return io.NopCloser(instances[index]), nil
+	}
+	return c.store(ctx, studyUID, len(instances), opener)
+}
+
+// StoreFiles uploads the given Part 10 files with STOW-RS. Files are opened one by one while the request
+// body is being written
+func (c *Client) StoreFiles(ctx context.Context, studyUID string, filePaths ...string) (*StoreResult, error) {
+	opener := func(index int) (io.ReadCloser, error) {
+		return os.Open(filePaths[index])
+	}
+	return c.store(ctx, studyUID, len(filePaths), opener)
+}
+
+func (c *Client) store(ctx context.Context, studyUID string, count int, open func(index int) (io.ReadCloser, error)) (*StoreResult, error) {
+	if count == 0 {
+		return nil, fmt.Errorf("dicomweb: no instance to store")
+	}
+	path := "/studies"
+	if studyUID != "" {
+		path = resourcePath(studyUID, "", "")
+	}
+
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		err := writeParts(mw, count, open)
+		if err == nil {
+			err = mw.Close()
+		}
+		_ = pw.CloseWithError(err)
+	}()
+
+	req, err := c.newRequest(ctx, http.MethodPost, path, pr)
+	if err != nil {
+		_ = pr.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", fmt.Sprintf("%s; type=%q; boundary=%s", MediaTypeMultipart, MediaTypeDICOM, mw.Boundary()))
+	req.Header.Set("Accept", MediaTypeDICOMJSON)
+
+	resp, err := c.do(req, http.StatusOK, http.StatusAccepted, http.StatusConflict)
+	_ = pr.Close()
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &StoreResult{Response: make(go2com.MappedTag)}
+	err = json.NewDecoder(resp.Body).Decode(&result.Response)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("dicomweb: cannot decode store response: %v", err)
+	}
+	result.Referenced = storedInstances(result.Response, tag.ReferencedSOPSequence)
+	result.Failed = storedInstances(result.Response, tag.FailedSOPSequence)
+
+	if resp.StatusCode == http.StatusConflict {
+		return result, &StatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return result, nil
+}
+
+func writeParts(mw *multipart.Writer, count int, open func(index int) (io.ReadCloser, error)) error {
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Type", MediaTypeDICOM)
+	for index := 0; index < count; index++ {
+		rd, err := open(index)
+		if err != nil {
+			return err
+		}
+		w, err := mw.CreatePart(header)
+		if err != nil {
+			_ = rd.Close()
+			return err
+		}
+		_, err = io.Copy(w, rd)
+		_ = rd.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// storedInstances extracts the items of the given sequence of the DICOM JSON response
+func storedInstances(res go2com.MappedTag, sequenceTag tag.DicomTag) []StoredInstance {
+	instances := make([]StoredInstance, 0)
+	seq, ok := res[sequenceTag.StringWithoutParentheses()]
+	if !ok {
+		return instances
+	}
+	items, ok := seq.Value.([]interface{})
+	if !ok {
+		return instances
+	}
+	for _, item := range items {
+		attrs, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		instances = append(instances, StoredInstance{
+			SOPClassUID:    firstString(attrs, tag.ReferencedSOPClassUID),
+			SOPInstanceUID: firstString(attrs, tag.ReferencedSOPInstanceUID),
+			RetrieveURL:    firstString(attrs, tag.RetrieveURL),
+			FailureReason:  firstInt(attrs, tag.FailureReason),
+			WarningReason:  firstInt(attrs, tag.WarningReason),
+		})
+	}
+	return instances
+}
+
+func firstValue(attrs map[string]interface{}, t tag.DicomTag) interface{} {
+	attr, ok := attrs[t.StringWithoutParentheses()].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	values, ok := attr["Value"].([]interface{})
+	if !ok || len(values) == 0 {
+		return nil
+	}
+	return values[0]
+}
+
+func firstString(attrs map[string]interface{}, t tag.DicomTag) string {
+	str, _ := firstValue(attrs, t).(string)
+	return str
+}
+
+func firstInt(attrs map[string]interface{}, t tag.DicomTag) int {
+	num, _ := firstValue(attrs, t).(float64)
+	return int(num)
+}
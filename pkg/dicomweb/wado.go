@@ -0,0 +1,275 @@
+package dicomweb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/okieraised/go2com"
+)
+
+// Instance holds the parsed content of a single retrieved DICOM instance
+type Instance struct {
+	Metadata go2com.Dataset
+	Dataset  go2com.Dataset
+}
+
+// InstanceHandler is called for every instance streamed from a WADO-RS response.
+// Returning an error stops the retrieval
+type InstanceHandler func(instance *Instance) error
+
+// Frame is a single frame returned by a WADO-RS frame retrieval
+type Frame struct {
+	Number            int
+	TransferSyntaxUID string
+	ContentType       string
+	Data              []byte
+}
+
+type retrieveConfig struct {
+	transferSyntaxUID string
+	skipPixelData     bool
+}
+
+// WithRetrieveTransferSyntax provides option to request the instances in the given transfer syntax.
+// Use "*" to accept any transfer syntax the origin server prefers
+func WithRetrieveTransferSyntax(transferSyntaxUID string) func(*retrieveConfig) {
+	return func(c *retrieveConfig) {
+		c.transferSyntaxUID = transferSyntaxUID
+	}
+}
+
+// WithRetrieveSkipPixelData provides option to skip reading pixel data of the retrieved instances
+func WithRetrieveSkipPixelData(skipPixelData bool) func(*retrieveConfig) {
+	return func(c *retrieveConfig) {
+		c.skipPixelData = skipPixelData
+	}
+}
+
+// RetrieveStudy retrieves all instances of a study and passes them to the handler one by one
+func (c *Client) RetrieveStudy(ctx context.Context, studyUID string, handler InstanceHandler, options ...func(*retrieveConfig)) error {
+	return c.retrieve(ctx, resourcePath(studyUID, "", ""), handler, options...)
+}
+
+// RetrieveSeries retrieves all instances of a series and passes them to the handler one by one
+func (c *Client) RetrieveSeries(ctx context.Context, studyUID, seriesUID string, handler InstanceHandler, options ...func(*retrieveConfig)) error {
+	if seriesUID == "" {
+		return fmt.Errorf("dicomweb: missing series instance uid")
+	}
+	return c.retrieve(ctx, resourcePath(studyUID, seriesUID, ""), handler, options...)
+}
+
+// RetrieveInstance retrieves a single instance and passes it to the handler
+func (c *Client) RetrieveInstance(ctx context.Context, studyUID, seriesUID, instanceUID string, handler InstanceHandler, options ...func(*retrieveConfig)) error {
+	if seriesUID == "" || instanceUID == "" {
+		return fmt.Errorf("dicomweb: missing series or sop instance uid")
+	}
+	return c.retrieve(ctx, resourcePath(studyUID, seriesUID, instanceUID), handler, options...)
+}
+
+func (c *Client) retrieve(ctx context.Context, path string, handler InstanceHandler, options ...func(*retrieveConfig)) error {
+	cfg := &retrieveConfig{}
+	for _, opt := range options {
+		opt(cfg)
+	}
+	if path == resourcePath("", "", "") {
+		return fmt.Errorf("dicomweb: missing study instance uid")
+	}
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	accept := fmt.Sprintf("%s; type=%q", MediaTypeMultipart, MediaTypeDICOM)
+	if cfg.transferSyntaxUID != "" {
+		accept += "; transfer-syntax=" + cfg.transferSyntaxUID
+	}
+	req.Header.Set("Accept", accept)
+
+	resp, err := c.do(req, http.StatusOK, http.StatusPartialContent)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	mr, err := newMultipartReader(resp)
+	if err != nil {
+		return err
+	}
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		instance, err := parsePart(part, cfg.skipPixelData)
+		_ = part.Close()
+		if err != nil {
+			return err
+		}
+		err = handler(instance)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parsePart parses a single application/dicom part. When the part declares its length, the part is parsed
+// directly from the response stream, otherwise only this instance is buffered to determine its size
+func parsePart(part *multipart.Part, skipPixelData bool) (*Instance, error) {
+	var rd io.Reader = part
+	size, err := strconv.ParseInt(part.Header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		b, err := io.ReadAll(part)
+		if err != nil {
+			return nil, err
+		}
+		rd = bytes.NewReader(b)
+		size = int64(len(b))
+	}
+
+	parser := go2com.NewDICOMReader(bufio.NewReader(rd), go2com.WithSetFileSize(size), go2com.WithSkipPixelData(skipPixelData))
+	err = parser.Parse()
+	if err != nil {
+		return nil, fmt.Errorf("dicomweb: cannot parse retrieved instance: %v", err)
+	}
+	return &Instance{
+		Metadata: parser.GetMetadata(),
+		Dataset:  parser.GetDataset(),
+	}, nil
+}
+
+// RetrieveFrames retrieves the given frames (1-based) of an instance. The transfer syntaxes are proposed to the
+// origin server in order of preference; an empty list accepts any transfer syntax
+func (c *Client) RetrieveFrames(ctx context.Context, studyUID, seriesUID, instanceUID string, frameNumbers []int, transferSyntaxUIDs ...string) ([]Frame, error) {
+	if len(frameNumbers) == 0 {
+		return nil, fmt.Errorf("dicomweb: no frame number requested")
+	}
+	numbers := make([]string, 0, len(frameNumbers))
+	for _, n := range frameNumbers {
+		if n < 1 {
+			return nil, fmt.Errorf("dicomweb: invalid frame number %d", n)
+		}
+		numbers = append(numbers, strconv.Itoa(n))
+	}
+	path := resourcePath(studyUID, seriesUID, instanceUID) + "/frames/" + strings.Join(numbers, ",")
+
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", frameAcceptHeader(transferSyntaxUIDs))
+
+	resp, err := c.do(req, http.StatusOK, http.StatusPartialContent)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	mr, err := newMultipartReader(resp)
+	if err != nil {
+		return nil, err
+	}
+	frames := make([]Frame, 0, len(frameNumbers))
+	for index := 0; ; index++ {
+		part, err := mr.NextPart()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		data, err := io.ReadAll(part)
+		_ = part.Close()
+		if err != nil {
+			return nil, err
+		}
+		frame := Frame{
+			ContentType: part.Header.Get("Content-Type"),
+			Data:        data,
+		}
+		if index < len(frameNumbers) {
+			frame.Number = frameNumbers[index]
+		}
+		if _, params, err := mime.ParseMediaType(frame.ContentType); err == nil {
+			frame.TransferSyntaxUID = params["transfer-syntax"]
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+// frameMediaTypes holds the media types of the frames of the compressed transfer syntaxes, PS3.18 Table 8.7.3-2. The
+// frames of the uncompressed transfer syntaxes are application/octet-stream
+var frameMediaTypes = map[string]string{
+	"1.2.840.10008.1.2.4.50":  "image/jpeg",        // JPEG Baseline (Process 1)
+	"1.2.840.10008.1.2.4.51":  "image/jpeg",        // JPEG Extended (Process 2 & 4)
+	"1.2.840.10008.1.2.4.57":  "image/jpeg",        // JPEG Lossless, Non-Hierarchical (Process 14)
+	"1.2.840.10008.1.2.4.70":  "image/jpeg",        // JPEG Lossless, Non-Hierarchical, First-Order Prediction
+	"1.2.840.10008.1.2.4.80":  "image/jls",         // JPEG-LS Lossless
+	"1.2.840.10008.1.2.4.81":  "image/jls",         // JPEG-LS Lossy (Near-Lossless)
+	"1.2.840.10008.1.2.4.90":  "image/jp2",         // JPEG 2000 (Lossless Only)
+	"1.2.840.10008.1.2.4.91":  "image/jp2",         // JPEG 2000
+	"1.2.840.10008.1.2.4.92":  "image/jpx",         // JPEG 2000 Part 2 Multi-component (Lossless Only)
+	"1.2.840.10008.1.2.4.93":  "image/jpx",         // JPEG 2000 Part 2 Multi-component
+	"1.2.840.10008.1.2.4.100": "video/mpeg",        // MPEG2 Main Profile / Main Level
+	"1.2.840.10008.1.2.4.101": "video/mpeg",        // MPEG2 Main Profile / High Level
+	"1.2.840.10008.1.2.4.102": "video/mp4",         // MPEG-4 AVC/H.264 High Profile / Level 4.1
+	"1.2.840.10008.1.2.4.103": "video/mp4",         // MPEG-4 AVC/H.264 BD-compatible High Profile / Level 4.1
+	"1.2.840.10008.1.2.4.104": "video/mp4",         // MPEG-4 AVC/H.264 High Profile / Level 4.2 For 2D Video
+	"1.2.840.10008.1.2.4.105": "video/mp4",         // MPEG-4 AVC/H.264 High Profile / Level 4.2 For 3D Video
+	"1.2.840.10008.1.2.4.106": "video/mp4",         // MPEG-4 AVC/H.264 Stereo High Profile / Level 4.2
+	"1.2.840.10008.1.2.4.107": "video/H265",        // HEVC/H.265 Main Profile / Level 5.1
+	"1.2.840.10008.1.2.4.108": "video/H265",        // HEVC/H.265 Main 10 Profile / Level 5.1
+	"1.2.840.10008.1.2.5":     "image/x-dicom-rle", // RLE Lossless
+}
+
+// frameMediaType returns the media type of the frames of the transfer syntax
+func frameMediaType(transferSyntaxUID string) string {
+	if mediaType, ok := frameMediaTypes[transferSyntaxUID]; ok {
+		return mediaType
+	}
+	return MediaTypeOctetStream
+}
+
+// frameAcceptHeader builds the Accept header value with decreasing quality for each proposed transfer syntax, of the
+// media type of the frames of the transfer syntax
+func frameAcceptHeader(transferSyntaxUIDs []string) string {
+	if len(transferSyntaxUIDs) == 0 {
+		return fmt.Sprintf("%s; type=%q; transfer-syntax=*", MediaTypeMultipart, MediaTypeOctetStream)
+	}
+	ranges := make([]string, 0, len(transferSyntaxUIDs))
+	for index, ts := range transferSyntaxUIDs {
+		q := 1.0 - float64(index)*0.1
+		if q < 0.1 {
+			q = 0.1
+		}
+		ranges = append(ranges, fmt.Sprintf("%s; type=%q; transfer-syntax=%s; q=%.1f", MediaTypeMultipart, frameMediaType(ts), ts, q))
+	}
+	return strings.Join(ranges, ", ")
+}
+
+func newMultipartReader(resp *http.Response) (*multipart.Reader, error) {
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, fmt.Errorf("dicomweb: invalid response content type: %v", err)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return nil, fmt.Errorf("dicomweb: expected multipart response, got %s", mediaType)
+	}
+	boundary, ok := params["boundary"]
+	if !ok {
+		return nil, fmt.Errorf("dicomweb: missing multipart boundary")
+	}
+	return multipart.NewReader(resp.Body, boundary), nil
+}
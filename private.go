@@ -3,10 +3,13 @@ package go2com
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"github.com/okieraised/go2com/pkg/dicom/tag"
 	"github.com/okieraised/go2com/pkg/dicom/uid"
 	"github.com/okieraised/go2com/pkg/dicom/vr"
 	"io"
+	"strings"
 )
 
 func (r *dcmReader) isTrackingImplicit() bool {
@@ -84,7 +87,9 @@ func (r *dcmReader) peek(n int) ([]byte, error) {
 }
 
 func (r *dcmReader) discard(n int) (int, error) {
-	return r.reader.Discard(n)
+	discarded, err := r.reader.Discard(n)
+	r.offset += int64(discarded)
+	return discarded, err
 }
 
 func (r *dcmReader) skip(n int64) error {
@@ -94,23 +99,16 @@ func (r *dcmReader) skip(n int64) error {
 
 func (r *dcmReader) parse() error {
 	_ = r.SetFileSize(r.fileSize)
-	err := r.IsValidDICOM()
-	if err != nil {
-		return err
+	var err error
+	if r.isRawDataset {
+		err = r.verifyImplicity()
+	} else {
+		err = r.parseHeader()
 	}
-	_ = r.skip(132)
-	err = r.parseMetadata()
 	if err != nil {
 		return err
 	}
 
-	// IMPORTANT: Additional check is needed here since there are few instances where the DICOM
-	// meta header is registered as Explicit Little-Endian, but Implicit Little-Endian is used in the body
-	err = r.verifyImplicity()
-	if err != nil {
-		return nil
-	}
-
 	if r.skipDataset {
 		return nil
 	}
@@ -121,6 +119,60 @@ func (r *dcmReader) parse() error {
 	return nil
 }
 
+// parseHeader skips the preamble and parses the file meta information. In lenient mode, the files without preamble are
+// read from their magic string or file meta information, or else as datasets without file meta information
+func (r *dcmReader) parseHeader() error {
+	err := r.IsValidDICOM()
+	if err != nil {
+		err = r.report(IssueMissingPreamble, tag.DicomTag{}, err)
+		if err != nil {
+			return err
+		}
+		if n, _ := r.peek(4); string(n) == MagicString {
+			_ = r.skip(4)
+		} else if !r.hasFileMeta() {
+			r.guessTransferSyntax()
+			return nil
+		}
+	} else {
+		_ = r.skip(132)
+	}
+	err = r.parseMetadata()
+	if err != nil {
+		return err
+	}
+
+	// IMPORTANT: Additional check is needed here since there are few instances where the DICOM
+	// meta header is registered as Explicit Little-Endian, but Implicit Little-Endian is used in the body
+	return r.verifyImplicity()
+}
+
+// hasFileMeta returns whether the file starts with an element of the file meta information, encoded in Explicit VR
+// Little Endian
+func (r *dcmReader) hasFileMeta() bool {
+	n, err := r.peek(6)
+	return err == nil && binary.LittleEndian.Uint16(n) == 0x0002 && vr.VRMapper[string(n[4:6])]
+}
+
+// guessTransferSyntax sets the transfer syntax of a dataset without file meta information from its first element. The
+// VR is explicit if a VR follows the tag, and the byte order is the one reading the lowest group, e.g.: 0x0008 rather
+// than 0x0800
+func (r *dcmReader) guessTransferSyntax() {
+	n, err := r.peek(6)
+	if err != nil {
+		// The dataset is too short for an element
+		return
+	}
+	isImplicit := !vr.VRMapper[string(n[4:6])]
+	var binOrder binary.ByteOrder = binary.LittleEndian
+	// There is no Implicit VR Big Endian transfer syntax
+	if !isImplicit && binary.BigEndian.Uint16(n) < binary.LittleEndian.Uint16(n) {
+		binOrder = binary.BigEndian
+	}
+	r.SetTransferSyntax(binOrder, isImplicit)
+	r.setOverallImplicit(isImplicit)
+}
+
 // parseMetadata parses the file meta information according to
 // https://dicom.nema.org/dicom/2013/output/chtml/part10/chapter_7.html
 // the File Meta Information shall be encoded using the Explicit VR Little Endian Transfer Syntax
@@ -128,6 +180,8 @@ func (r *dcmReader) parse() error {
 func (r *dcmReader) parseMetadata() error {
 	var metadata []*Element
 	var transferSyntaxUID string
+	// groupLength is the value of the FileMetaInformationGroupLength, the number of bytes of the meta header following it
+	groupLength, groupEnd := int64(-1), int64(0)
 
 	for {
 		// No longer relied on the MetaInformationGroupLength tag to determine the length of the meta header.
@@ -149,9 +203,24 @@ func (r *dcmReader) parseMetadata() error {
 		if res.Tag == tag.TransferSyntaxUID {
 			transferSyntaxUID = (res.Value.RawValue).(string)
 		}
+		if length, ok := res.Value.RawValue.(int); ok && res.Tag == tag.FileMetaInformationGroupLength {
+			groupLength, groupEnd = int64(length), r.offset+int64(length)
+		}
 	}
 	r.metadata = Dataset{Elements: metadata}
 
+	var err error
+	if groupLength < 0 {
+		err = r.report(IssueGroupLength, tag.FileMetaInformationGroupLength,
+			errors.New("missing FileMetaInformationGroupLength"))
+	} else if groupEnd != r.offset {
+		err = r.report(IssueGroupLength, tag.FileMetaInformationGroupLength,
+			fmt.Errorf("group length %d, meta header of %d bytes", groupLength, groupLength+r.offset-groupEnd))
+	}
+	if err != nil {
+		return err
+	}
+
 	// Set transfer syntax here for the dataset parser
 	binOrder, isImplicit, err := uid.ParseTransferSyntaxUID(transferSyntaxUID)
 	if err != nil {
@@ -163,18 +232,29 @@ func (r *dcmReader) parseMetadata() error {
 	return nil
 }
 
+// verifyImplicity checks if the VR explicitness of the first element of the dataset matches the transfer syntax. If
+// not, the dataset is read with the VR explicitness of its first element
 func (r *dcmReader) verifyImplicity() error {
-	// Need to check if the implicit matches between header and body
 	n, err := r.peek(6)
 	if err != nil {
+		// The file has no dataset
+		if err == io.EOF {
+			return nil
+		}
 		return err
 	}
-	if !vr.VRMapper[string(n[4:6])] && !r.IsImplicit() {
-		r.SetTransferSyntax(r.binaryOrder, true)
+	isImplicit := !vr.VRMapper[string(n[4:6])]
+	if isImplicit == r.IsImplicit() {
+		return nil
 	}
-	if vr.VRMapper[string(n[4:6])] && r.IsImplicit() {
-		r.SetTransferSyntax(r.binaryOrder, false)
+	first := tag.DicomTag{Group: r.binaryOrder.Uint16(n[0:2]), Element: r.binaryOrder.Uint16(n[2:4])}
+	err = r.report(IssueVRMismatch, first, fmt.Errorf("dataset from tag %s encoded with implicit VR %t, not %t",
+		first, isImplicit, r.IsImplicit()))
+	if err != nil {
+		return err
 	}
+	r.SetTransferSyntax(r.binaryOrder, isImplicit)
+	r.setOverallImplicit(isImplicit)
 	return nil
 }
 
@@ -198,3 +278,17 @@ func (r *dcmReader) parseDataset() error {
 	r.dataset = dicomDataset
 	return nil
 }
+
+// setPrivateCreator records the value of the private creator element reserving a block of private tags
+func (r *dcmReader) setPrivateCreator(t tag.DicomTag, value interface{}) {
+	creator, ok := value.(string)
+	if !ok {
+		if values, isSlice := value.([]string); isSlice && len(values) > 0 {
+			creator = values[0]
+		}
+	}
+	if r.privateCreators == nil {
+		r.privateCreators = make(map[tag.DicomTag]string)
+	}
+	r.privateCreators[t] = strings.TrimSpace(creator)
+}
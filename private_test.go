@@ -0,0 +1,68 @@
+package go2com
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadElement_PrivateTags(t *testing.T) {
+	assert := assert.New(t)
+	private := func(group, element uint16, valueRepresentation string, value interface{}) *Element {
+		return &Element{Tag: tag.DicomTag{Group: group, Element: element}, ValueRepresentationStr: valueRepresentation, Value: Value{RawValue: value}}
+	}
+	meta := NewFileMeta("1.2.840.10008.5.1.4.1.1.4", "1.2.3.4", uid.ImplicitVRLittleEndian)
+	ds := Dataset{Elements: []*Element{
+		NewElement(tag.PatientName, "DOE^JOHN"),
+		private(0x0019, 0x0010, "LO", "GEMS_ACQU_01"),
+		private(0x0019, 0x0011, "LO", "UNKNOWN CREATOR"),
+		private(0x0019, 0x101B, "DS", "12.5"),
+		private(0x0019, 0x108F, "SS", 1),
+		private(0x0019, 0x111B, "UN", []byte{1, 2, 3, 4}),
+		NewElement(tag.ReferencedImageSequence, []*Element{
+			NewElement(tag.ReferencedSOPInstanceUID, "1.2.3"),
+			private(0x0029, 0x0010, "LO", "SIEMENS CSA HEADER"),
+			private(0x0029, 0x1008, "CS", "IMAGE NUM 4"),
+		}),
+		private(0x0029, 0x1008, "UN", []byte("ABCD")),
+	}}
+	buf := bytes.Buffer{}
+	assert.NoError(NewDICOMWriter(&buf).WriteFile(meta, ds))
+
+	rd := NewDICOMReader(bufio.NewReader(bytes.NewReader(buf.Bytes())), WithSetFileSize(int64(buf.Len())))
+	assert.NoError(rd.Parse())
+	res := rd.GetDataset()
+	expected := []struct {
+		t                   tag.DicomTag
+		name                string
+		valueRepresentation string
+		value               interface{}
+	}{
+		{tag.DicomTag{Group: 0x0019, Element: 0x0010}, tag.PrivateCreatorName, "LO", "GEMS_ACQU_01"},
+		{tag.DicomTag{Group: 0x0019, Element: 0x101B}, "LastScanLocation", "DS", 12.5},
+		{tag.DicomTag{Group: 0x0019, Element: 0x108F}, "SwapPhaseFrequency", "SS", 1},
+		{tag.DicomTag{Group: 0x0019, Element: 0x111B}, PrivateTag, "UN", []byte{1, 2, 3, 4}},
+		// The creator of the item does not reserve the block outside of the item
+		{tag.DicomTag{Group: 0x0029, Element: 0x1008}, PrivateTag, "UN", []byte("ABCD")},
+	}
+	for _, e := range expected {
+		elem, err := res.FindElementByTag(e.t)
+		assert.NoError(err, e.t.String())
+		if err == nil {
+			assert.Equal(e.name, elem.TagName, e.t.String())
+			assert.Equal(e.valueRepresentation, elem.ValueRepresentationStr, e.t.String())
+			assert.Equal(e.value, elem.Value.RawValue, e.t.String())
+		}
+	}
+
+	seq, err := res.FindElementByTag(tag.ReferencedImageSequence)
+	assert.NoError(err)
+	items := seq.Value.RawValue.([]*Element)
+	assert.Len(items, 3)
+	assert.Equal("CSAImageHeaderType", items[2].TagName)
+	assert.Equal("IMAGE NUM 4", items[2].Value.RawValue)
+}
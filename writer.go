@@ -0,0 +1,470 @@
+package go2com
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/okieraised/go2com/internal/system"
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/okieraised/go2com/pkg/dicom/vr"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ImplementationClassUID identifies go2com in the file meta information and the association negotiation
+	ImplementationClassUID = "2.25.321658688305475064556237780278963539380"
+	// ImplementationVersionName identifies the go2com version in the file meta information and the association negotiation
+	ImplementationVersionName = "GO2COM"
+)
+
+type dcmWriter struct {
+	writer      io.Writer
+	binaryOrder binary.ByteOrder
+	isImplicit  bool
+}
+
+// NewDICOMWriter returns a new writer. By default, the dataset is encoded in Explicit VR Little Endian
+func NewDICOMWriter(writer io.Writer, options ...func(*dcmWriter)) *dcmWriter {
+	w := &dcmWriter{
+		writer:      writer,
+		binaryOrder: binary.LittleEndian,
+		isImplicit:  false,
+	}
+	for _, opt := range options {
+		opt(w)
+	}
+	return w
+}
+
+// WithWriterTransferSyntax provides option to set the byte order and VR encoding of the written dataset
+func WithWriterTransferSyntax(binaryOrder binary.ByteOrder, isImplicit bool) func(*dcmWriter) {
+	return func(w *dcmWriter) {
+		w.binaryOrder = binaryOrder
+		w.isImplicit = isImplicit
+	}
+}
+
+// SetTransferSyntax sets the byte order and VR encoding of the written dataset
+func (w *dcmWriter) SetTransferSyntax(binaryOrder binary.ByteOrder, isImplicit bool) {
+	w.binaryOrder = binaryOrder
+	w.isImplicit = isImplicit
+}
+
+// NewElement returns a new element of the given tag. The VR and name are looked up in the dictionary
+func NewElement(t tag.DicomTag, value interface{}) *Element {
+	elem := &Element{
+		Tag:                    t,
+		TagName:                PrivateTag,
+		ValueRepresentationStr: vr.Unknown,
+		Value:                  Value{RawValue: value},
+	}
+	if tagInfo, err := tag.Find(t); err == nil {
+		elem.TagName = tagInfo.Name
+		elem.ValueRepresentationStr = tagInfo.VR
+	}
+	elem.ValueRepresentation = vr.GetVR(t, elem.ValueRepresentationStr)
+	return elem
+}
+
+// NewFileMeta returns the File Meta Information elements for the given instance
+func NewFileMeta(sopClassUID, sopInstanceUID, transferSyntaxUID string) Dataset {
+	return Dataset{Elements: []*Element{
+		NewElement(tag.FileMetaInformationVersion, []byte{0x00, 0x01}),
+		NewElement(tag.MediaStorageSOPClassUID, sopClassUID),
+		NewElement(tag.MediaStorageSOPInstanceUID, sopInstanceUID),
+		NewElement(tag.TransferSyntaxUID, transferSyntaxUID),
+		NewElement(tag.ImplementationClassUID, ImplementationClassUID),
+		NewElement(tag.ImplementationVersionName, ImplementationVersionName),
+	}}
+}
+
+// WriteFile writes the 128 bytes preamble, the magic string, the file meta information and the dataset
+// encoded with the transfer syntax of the file meta information
+func (w *dcmWriter) WriteFile(meta, ds Dataset) error {
+	err := w.WriteFileMeta(meta)
+	if err != nil {
+		return err
+	}
+	transferSyntaxUID := ""
+	for _, elem := range meta.Elements {
+		if elem.Tag == tag.TransferSyntaxUID {
+			transferSyntaxUID, _ = elem.Value.RawValue.(string)
+		}
+	}
+	binOrder, isImplicit, err := uid.ParseTransferSyntaxUID(transferSyntaxUID)
+	if err != nil {
+		return err
+	}
+	w.SetTransferSyntax(binOrder, isImplicit)
+	return w.WriteDataset(ds)
+}
+
+// WriteFileMeta writes the 128 bytes preamble, the magic string and the file meta information.
+// The file meta information is always encoded in Explicit VR Little Endian and its group length is computed
+func (w *dcmWriter) WriteFileMeta(meta Dataset) error {
+	group := bytes.Buffer{}
+	metaWriter := NewDICOMWriter(&group)
+	for _, elem := range meta.Elements {
+		if elem.Tag.Group != 0x0002 || elem.Tag == tag.FileMetaInformationGroupLength {
+			continue
+		}
+		err := metaWriter.WriteElement(elem)
+		if err != nil {
+			return err
+		}
+	}
+
+	header := bytes.Buffer{}
+	header.Write(make([]byte, 128))
+	header.WriteString(MagicString)
+	groupLength := NewElement(tag.FileMetaInformationGroupLength, group.Len())
+	err := NewDICOMWriter(&header).WriteElement(groupLength)
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(header.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(group.Bytes())
+	return err
+}
+
+// WriteDataset writes the elements of the dataset. Group length elements are not written since their
+// value depends on the encoding of the original dataset
+func (w *dcmWriter) WriteDataset(ds Dataset) error {
+	for _, elem := range ds.Elements {
+		if elem == nil || elem.Tag.Element == 0x0000 {
+			continue
+		}
+		err := w.WriteElement(elem)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteElement writes a single element
+func (w *dcmWriter) WriteElement(elem *Element) error {
+	valueRepresentation := explicitVR(elem)
+	if valueRepresentation == vr.SequenceOfItems {
+		return w.writeSequence(elem)
+	}
+
+	value, err := encodeValue(elem, valueRepresentation, w.binaryOrder)
+	if err != nil {
+		return fmt.Errorf("cannot encode tag %s: %v", elem.Tag.String(), err)
+	}
+
+	// Encapsulated pixel data keeps the raw items and sequence delimitation item as read
+	valueLength := uint32(len(value))
+	if elem.Tag == tag.PixelData && isEncapsulated(value) {
+		valueLength = VLUndefinedLength
+	}
+	err = w.writeHeader(elem.Tag, valueRepresentation, valueLength)
+	if err != nil {
+		return err
+	}
+	_, err = w.writer.Write(value)
+	return err
+}
+
+func (w *dcmWriter) writeTag(t tag.DicomTag) error {
+	b := make([]byte, 4)
+	w.binaryOrder.PutUint16(b, t.Group)
+	w.binaryOrder.PutUint16(b[2:], t.Element)
+	_, err := w.writer.Write(b)
+	return err
+}
+
+// writeHeader writes the tag, the VR in explicit transfer syntaxes, and the value length
+func (w *dcmWriter) writeHeader(t tag.DicomTag, valueRepresentation string, valueLength uint32) error {
+	err := w.writeTag(t)
+	if err != nil {
+		return err
+	}
+	if w.isImplicit {
+		b := make([]byte, 4)
+		w.binaryOrder.PutUint32(b, valueLength)
+		_, err = w.writer.Write(b)
+		return err
+	}
+
+	switch valueRepresentation {
+	case vr.OtherByte, vr.OtherDouble, vr.OtherFloat, vr.OtherLong, vr.OtherVeryLong, vr.OtherWord, vr.SequenceOfItems,
+		vr.SignedVeryLong, vr.UnlimitedCharacters, vr.Unknown, vr.UniversalResourceIdentifier, vr.UnlimitedText,
+		vr.UnsignedVeryLong:
+		b := make([]byte, 8)
+		copy(b, valueRepresentation)
+		w.binaryOrder.PutUint32(b[4:], valueLength)
+		_, err = w.writer.Write(b)
+		return err
+	default:
+		if valueLength > 0xFFFF {
+			return fmt.Errorf("value of tag %s is too long for VR %s", t.String(), valueRepresentation)
+		}
+		b := make([]byte, 4)
+		copy(b, valueRepresentation)
+		w.binaryOrder.PutUint16(b[2:], uint16(valueLength))
+		_, err = w.writer.Write(b)
+		return err
+	}
+}
+
+// writeSequence writes the sequence and its items with undefined length. Since the parsed sequence holds the
+// elements of all its items, a new item is started whenever a tag is not greater than the previous one
+func (w *dcmWriter) writeSequence(elem *Element) error {
+	err := w.writeHeader(elem.Tag, vr.SequenceOfItems, VLUndefinedLength)
+	if err != nil {
+		return err
+	}
+	items := splitItems(elem)
+	for _, item := range items {
+		err = w.writeItemHeader(tag.Item, VLUndefinedLength)
+		if err != nil {
+			return err
+		}
+		err = w.WriteDataset(Dataset{Elements: item})
+		if err != nil {
+			return err
+		}
+		err = w.writeDelimiter(tag.ItemDelimitationItem)
+		if err != nil {
+			return err
+		}
+	}
+	return w.writeDelimiter(tag.SequenceDelimitationItem)
+}
+
+func (w *dcmWriter) writeDelimiter(t tag.DicomTag) error {
+	return w.writeItemHeader(t, 0)
+}
+
+// writeItemHeader writes the tag and the 4 bytes length of an item or delimiter, which never have a VR
+func (w *dcmWriter) writeItemHeader(t tag.DicomTag, valueLength uint32) error {
+	err := w.writeTag(t)
+	if err != nil {
+		return err
+	}
+	b := make([]byte, 4)
+	w.binaryOrder.PutUint32(b, valueLength)
+	_, err = w.writer.Write(b)
+	return err
+}
+
+// splitItems splits the elements of a parsed sequence into its items
+func splitItems(elem *Element) [][]*Element {
+	subElements, _ := elem.Value.RawValue.([]*Element)
+	items := make([][]*Element, 0)
+	var current []*Element
+	for _, subElem := range subElements {
+		if subElem == nil {
+			continue
+		}
+		if len(current) > 0 && !tagLess(current[len(current)-1].Tag, subElem.Tag) {
+			items = append(items, current)
+			current = nil
+		}
+		current = append(current, subElem)
+	}
+	if len(current) > 0 {
+		items = append(items, current)
+	}
+	return items
+}
+
+func tagLess(a, b tag.DicomTag) bool {
+	if a.Group != b.Group {
+		return a.Group < b.Group
+	}
+	return a.Element < b.Element
+}
+
+// explicitVR returns the VR to encode the element with, resolving the ambiguous dictionary VRs
+func explicitVR(elem *Element) string {
+	// Private sequences of unknown VR are parsed as sequences
+	if _, ok := elem.Value.RawValue.([]*Element); ok {
+		return vr.SequenceOfItems
+	}
+	switch elem.ValueRepresentationStr {
+	case vr.SignedShortOrUnsignedShort, strings.ToLower(vr.SignedShortOrUnsignedShort):
+		return vr.UnsignedShort
+	case vr.OtherByteOrOtherWord, strings.ToLower(vr.OtherByteOrOtherWord):
+		if b, ok := elem.Value.RawValue.([]byte); ok && len(b)%2 != 0 {
+			return vr.OtherByte
+		}
+		return vr.OtherWord
+	case "up":
+		// Offsets of the directory records are unsigned long values
+		return vr.UnsignedLong
+	case "", "na":
+		return vr.Unknown
+	default:
+		return elem.ValueRepresentationStr
+	}
+}
+
+// isEncapsulated returns true if the pixel data value starts with an item tag
+func isEncapsulated(value []byte) bool {
+	if len(value) < 4 {
+		return false
+	}
+	return (value[0] == 0xFE && value[1] == 0xFF && value[2] == 0x00 && value[3] == 0xE0) ||
+		(value[0] == 0xFF && value[1] == 0xFE && value[2] == 0xE0 && value[3] == 0x00)
+}
+
+// encodeValue returns the value of the element encoded for the given VR and byte order, padded to an even length
+func encodeValue(elem *Element, valueRepresentation string, binOrder binary.ByteOrder) ([]byte, error) {
+	rawValue := elem.Value.RawValue
+	if rawValue == nil {
+		return []byte{}, nil
+	}
+
+	switch valueRepresentation {
+	case vr.ApplicationEntity, vr.AgeString, vr.CodeString, vr.Date, vr.DecimalString, vr.DateTime,
+		vr.IntegerString, vr.LongString, vr.LongText, vr.PersonName, vr.ShortString, vr.ShortText, vr.Time,
+		vr.UnlimitedCharacters, vr.UniversalResourceIdentifier, vr.UnlimitedText:
+		strs, err := toStrings(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		return padValue([]byte(strings.Join(strs, "\\")), ' '), nil
+	case vr.UniqueIdentifier:
+		strs, err := toStrings(rawValue)
+		if err != nil {
+			return nil, err
+		}
+		return padValue([]byte(strings.Join(strs, "\\")), 0x00), nil
+	case vr.UnsignedShort, vr.SignedShort, vr.AttributeTag:
+		return encodeInts(rawValue, 2, binOrder)
+	case vr.UnsignedLong, vr.SignedLong:
+		return encodeInts(rawValue, 4, binOrder)
+	case vr.FloatingPointSingle, vr.FloatingPointDouble, vr.OtherFloat, vr.OtherDouble:
+		if floats, ok := toFloats(rawValue); ok {
+			buf := bytes.Buffer{}
+			for _, f := range floats {
+				if valueRepresentation == vr.FloatingPointSingle || valueRepresentation == vr.OtherFloat {
+					_ = binary.Write(&buf, binOrder, float32(f))
+				} else {
+					_ = binary.Write(&buf, binOrder, f)
+				}
+			}
+			return buf.Bytes(), nil
+		}
+	case vr.OtherWord:
+		// Other Word values are kept in the native byte order by the reader, except the pixel data which is kept
+		// as read
+		if b, ok := rawValue.([]byte); ok && elem.Tag != tag.PixelData && system.NativeEndian != binOrder {
+			res := make([]byte, len(b))
+			for i := 0; i+1 < len(b); i += 2 {
+				binOrder.PutUint16(res[i:], system.NativeEndian.Uint16(b[i:]))
+			}
+			return padValue(res, 0x00), nil
+		}
+	}
+
+	switch v := rawValue.(type) {
+	case []byte:
+		return padValue(v, 0x00), nil
+	case string:
+		return padValue([]byte(v), 0x00), nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T for VR %s", rawValue, valueRepresentation)
+	}
+}
+
+func padValue(b []byte, padding byte) []byte {
+	if len(b)%2 != 0 {
+		return append(b, padding)
+	}
+	return b
+}
+
+// toStrings converts the parsed value of a string VR to its string values
+func toStrings(rawValue interface{}) ([]string, error) {
+	switch v := rawValue.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case int:
+		return []string{strconv.Itoa(v)}, nil
+	case []int:
+		res := make([]string, 0, len(v))
+		for _, i := range v {
+			res = append(res, strconv.Itoa(i))
+		}
+		return res, nil
+	case float64:
+		return []string{formatDecimalString(v)}, nil
+	case []float64:
+		res := make([]string, 0, len(v))
+		for _, f := range v {
+			res = append(res, formatDecimalString(f))
+		}
+		return res, nil
+	case []byte:
+		return []string{string(v)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T for string VR", rawValue)
+	}
+}
+
+// formatDecimalString formats the float to fit the 16 bytes maximum length of the DS VR
+func formatDecimalString(f float64) string {
+	str := strconv.FormatFloat(f, 'g', -1, 64)
+	for precision := 15; len(str) > 16 && precision > 0; precision-- {
+		str = strconv.FormatFloat(f, 'g', precision, 64)
+	}
+	return str
+}
+
+func toFloats(rawValue interface{}) ([]float64, bool) {
+	switch v := rawValue.(type) {
+	case float64:
+		return []float64{v}, true
+	case []float64:
+		return v, true
+	case float32:
+		return []float64{float64(v)}, true
+	case []float32:
+		res := make([]float64, 0, len(v))
+		for _, f := range v {
+			res = append(res, float64(f))
+		}
+		return res, true
+	default:
+		return nil, false
+	}
+}
+
+func encodeInts(rawValue interface{}, size int, binOrder binary.ByteOrder) ([]byte, error) {
+	var ints []int
+	switch v := rawValue.(type) {
+	case int:
+		ints = []int{v}
+	case []int:
+		ints = v
+	case uint16:
+		ints = []int{int(v)}
+	case uint32:
+		ints = []int{int(v)}
+	case []byte:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported value type %T for binary integer VR", rawValue)
+	}
+	b := make([]byte, size*len(ints))
+	for i, v := range ints {
+		if size == 2 {
+			binOrder.PutUint16(b[i*2:], uint16(v))
+		} else {
+			binOrder.PutUint32(b[i*4:], uint32(v))
+		}
+	}
+	return b, nil
+}
@@ -0,0 +1,75 @@
+package go2com
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"github.com/okieraised/go2com/pkg/dicom/tag"
+	"github.com/okieraised/go2com/pkg/dicom/uid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDICOMWriter_RoundTrip(t *testing.T) {
+	assert := assert.New(t)
+	fPaths := []string{
+		"./dicom_test/010.dcm", // Implicit VR Little Endian
+		"./dicom_test/013.dcm", // Encapsulated pixel data
+		"./dicom_test/014.dcm", // Explicit VR Little Endian with sequences
+		"./dicom_test/024.dcm", // Private sequences of unknown VR
+	}
+	for _, fPath := range fPaths {
+		f, err := os.Open(fPath)
+		assert.NoError(err)
+		fInfo, err := f.Stat()
+		assert.NoError(err)
+		rd := NewDICOMReader(bufio.NewReader(f), WithSetFileSize(fInfo.Size()))
+		assert.NoError(rd.Parse())
+		_ = f.Close()
+
+		buf := bytes.Buffer{}
+		assert.NoError(NewDICOMWriter(&buf).WriteFile(rd.GetMetadata(), rd.GetDataset()))
+
+		written := NewDICOMReader(bufio.NewReader(bytes.NewReader(buf.Bytes())), WithSetFileSize(int64(buf.Len())))
+		assert.NoError(written.Parse())
+		expected, actual := rd.GetDataset(), written.GetDataset()
+		for _, elem := range expected.Elements {
+			if elem.Tag.Element == 0x0000 {
+				continue
+			}
+			res, err := actual.FindElementByTag(elem.Tag)
+			assert.NoError(err, elem.Tag.String())
+			if err == nil {
+				assert.Equal(elem.Value.RawValue, res.Value.RawValue, elem.Tag.String())
+			}
+		}
+	}
+}
+
+func TestDICOMWriter_Implicit(t *testing.T) {
+	assert := assert.New(t)
+	meta := NewFileMeta("1.2.840.10008.5.1.4.1.1.2", "1.2.3.4", uid.ImplicitVRLittleEndian)
+	ds := Dataset{Elements: []*Element{
+		NewElement(tag.PatientName, "DOE^JOHN"),
+		NewElement(tag.Rows, 512),
+		NewElement(tag.PixelSpacing, []float64{0.5, 0.25}),
+	}}
+
+	buf := bytes.Buffer{}
+	w := NewDICOMWriter(&buf, WithWriterTransferSyntax(binary.LittleEndian, true))
+	assert.NoError(w.WriteFile(meta, ds))
+
+	rd := NewDICOMReader(bufio.NewReader(bytes.NewReader(buf.Bytes())), WithSetFileSize(int64(buf.Len())))
+	assert.NoError(rd.Parse())
+	val, err := rd.GetElementByTagString("(0010,0010)")
+	assert.NoError(err)
+	assert.Equal(Value{RawValue: "DOE^JOHN"}, val)
+	val, err = rd.GetElementByTagString("(0028,0010)")
+	assert.NoError(err)
+	assert.Equal(Value{RawValue: 512}, val)
+	val, err = rd.GetElementByTagString("(0028,0030)")
+	assert.NoError(err)
+	assert.Equal(Value{RawValue: []float64{0.5, 0.25}}, val)
+}